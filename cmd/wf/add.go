@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/fredriklanga/wf/internal/config"
 	"github.com/fredriklanga/wf/internal/store"
 	"github.com/fredriklanga/wf/internal/template"
 	"github.com/spf13/cobra"
@@ -18,7 +19,12 @@ var addCmd = &cobra.Command{
 
 If --name or --command are missing, you will be prompted for them.
 Parameters in the command string ({{name}} or {{name:default}}) are
-automatically extracted and added as arguments.`,
+automatically extracted and added as arguments.
+
+With --project, the workflow is saved in the .wf/ directory of the current
+project (found by walking up from the current directory) instead of your
+personal workflows. Commit it to share it with everyone working in the
+repository; it is listed as "project/<name>" while you are inside it.`,
 	RunE: runAdd,
 }
 
@@ -28,6 +34,7 @@ func init() {
 	addCmd.Flags().StringP("description", "d", "", "description")
 	addCmd.Flags().StringSliceP("tag", "t", nil, "tags (repeatable)")
 	addCmd.Flags().StringP("folder", "f", "", "subfolder path under workflows/ (max 2 levels)")
+	addCmd.Flags().Bool("project", false, "save in the current project's .wf/ directory")
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
@@ -36,6 +43,17 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	description, _ := cmd.Flags().GetString("description")
 	tags, _ := cmd.Flags().GetStringSlice("tag")
 	folder, _ := cmd.Flags().GetString("folder")
+	project, _ := cmd.Flags().GetBool("project")
+
+	// Resolve the target store up front so a missing project fails before
+	// any prompting.
+	s := getStore()
+	if project {
+		s = getProjectStore()
+		if s == nil {
+			return fmt.Errorf("no %s directory found in the current directory or its parents; create one at the project root", config.ProjectDirName)
+		}
+	}
 
 	// Determine if we need interactive mode (missing required fields)
 	interactive := name == "" || command == ""
@@ -127,7 +145,6 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check for duplicate
-	s := getStore()
 	existing, err := s.Get(storeName)
 	if err == nil && existing != nil {
 		return fmt.Errorf("workflow %q already exists", name)
@@ -145,6 +162,10 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	// Restore display name for output
 	wf.Name = name
 
+	if project {
+		fmt.Printf("Created %s/%s\n", store.ProjectAlias, storeName)
+		return nil
+	}
 	fmt.Printf("Created %s\n", name)
 	return nil
 }
@@ -44,7 +44,7 @@ func runAutofill(cmd *cobra.Command, args []string) error {
 	}
 
 	// Warn if workflow has no command
-	if strings.TrimSpace(wf.Command) == "" {
+	if strings.TrimSpace(wf.Template()) == "" {
 		fmt.Fprintln(os.Stderr, "Warning: this workflow has no command. Consider using 'wf generate' instead.")
 		return nil
 	}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/fredriklanga/wf/internal/config"
+	"github.com/fredriklanga/wf/internal/source"
+)
+
+// startSourceAutoUpdate starts 'wf source update --auto' as a detached
+// background process when sources.auto_update_interval is set and a git
+// source is older than it. The current invocation does not wait: it shows
+// the workflows already on disk, and the next one sees the refreshed ones.
+func startSourceAutoUpdate() {
+	appCfg, err := config.LoadAppConfig()
+	if err != nil {
+		return
+	}
+	interval, err := appCfg.Sources.AutoUpdate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "wf: ignoring invalid config: %v\n", err)
+		return
+	}
+	if interval == 0 || !source.NewManager(config.SourcesDir()).AutoUpdateDue(interval) {
+		return
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(exe, "source", "update", "--auto")
+	// With nil Stdin, Stdout and Stderr the child gets the null device, so
+	// it never writes over the picker.
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	_ = cmd.Process.Release()
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
+	"github.com/fredriklanga/wf/internal/lint"
 	"github.com/fredriklanga/wf/internal/store"
 	"github.com/fredriklanga/wf/internal/template"
 	"github.com/goccy/go-yaml"
@@ -32,7 +34,11 @@ func init() {
 
 func runEdit(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	s := getStore()
+	s, storeName := resolveStore(name)
+	if ro, ok := s.(store.ReadOnlyStore); ok && ro.ReadOnly() {
+		alias, _, _ := strings.Cut(name, "/")
+		return fmt.Errorf("workflow %q is in read-only source %q; enable edits with 'wf source writable %s'", name, alias, alias)
+	}
 
 	// Check if any update flags were provided
 	hasFlags := cmd.Flags().Changed("command") ||
@@ -42,15 +48,16 @@ func runEdit(cmd *cobra.Command, args []string) error {
 		cmd.Flags().Changed("remove-tag")
 
 	if hasFlags {
-		return runQuickEdit(cmd, s, name)
+		return runQuickEdit(cmd, s, storeName, name)
 	}
 
-	return runEditorEdit(s, name)
+	return runEditorEdit(s, storeName, name)
 }
 
 // runQuickEdit updates specific fields via flags without opening an editor.
-func runQuickEdit(cmd *cobra.Command, s *store.YAMLStore, name string) error {
-	wf, err := s.Get(name)
+// storeName is the workflow's name within s; name is the name shown.
+func runQuickEdit(cmd *cobra.Command, s fileStore, storeName, name string) error {
+	wf, err := s.Get(storeName)
 	if err != nil {
 		return fmt.Errorf("workflow %q not found", name)
 	}
@@ -115,8 +122,9 @@ func runQuickEdit(cmd *cobra.Command, s *store.YAMLStore, name string) error {
 }
 
 // runEditorEdit opens the workflow YAML in $EDITOR for editing.
-func runEditorEdit(s *store.YAMLStore, name string) error {
-	wf, err := s.Get(name)
+// storeName is the workflow's name within s; name is the name shown.
+func runEditorEdit(s fileStore, storeName, name string) error {
+	wf, err := s.Get(storeName)
 	if err != nil {
 		return fmt.Errorf("workflow %q not found", name)
 	}
@@ -128,7 +136,7 @@ func runEditorEdit(s *store.YAMLStore, name string) error {
 	}
 
 	// Get file path for the workflow
-	fpath := s.WorkflowPath(name)
+	fpath := s.WorkflowPath(storeName)
 
 	// Open editor
 	editorCmd := exec.Command(editor, fpath)
@@ -151,9 +159,23 @@ func runEditorEdit(s *store.YAMLStore, name string) error {
 		return fmt.Errorf("invalid YAML after editing: %w\nPlease re-run 'wf edit %s' to fix", err, name)
 	}
 
+	// Point out semantic problems, such as a misspelt key, without
+	// rejecting the edit.
+	issues, _ := lint.File(fpath, data)
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stderr, issue)
+	}
+
 	// Suppress unused variable warning - validation passed
 	_ = wf
 
+	// Writable remote sources commit and push the edited file.
+	if p, ok := s.(store.Publisher); ok {
+		if err := p.PublishPath(fpath); err != nil {
+			return err
+		}
+	}
+
 	fmt.Printf("Updated %s\n", name)
 	return nil
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fredriklanga/wf/internal/exporter"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <format>",
+	Short: "Export workflows to external formats",
+	Long: `Export workflows to Pet TOML, Warp YAML, a JSON bundle, or bash functions.
+
+Supported formats:
+  wf export pet   — Pet TOML snippet file (read back with wf import pet)
+  wf export warp  — Warp YAML workflows (read back with wf import warp)
+  wf export json  — JSON bundle of wf workflows, lossless (wf import json)
+  wf export bash  — bash functions to source, one per workflow
+
+Output goes to stdout unless --output is set. Features a format cannot
+represent, such as enum options in Pet, are listed on stderr.
+
+Examples:
+  wf export pet --folder infra -o infra.toml
+  wf export warp --tag docker
+  wf export bash --name 'deploy-*' > deploy.sh`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"pet", "warp", "json", "bash"},
+	RunE:      runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringP("output", "o", "", "write to this file instead of stdout")
+	exportCmd.Flags().StringP("folder", "f", "", "only export workflows in this folder")
+	exportCmd.Flags().StringSliceP("tag", "t", nil, "only export workflows with one of these tags (repeatable)")
+	exportCmd.Flags().StringSliceP("name", "n", nil, "only export these workflows; glob patterns allowed (repeatable)")
+}
+
+// exporters maps a format argument to its exporter.
+var exporters = map[string]exporter.Exporter{
+	"pet":  &exporter.PetExporter{},
+	"warp": &exporter.WarpExporter{},
+	"json": &exporter.JSONExporter{},
+	"bash": &exporter.BashExporter{},
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	folder, _ := cmd.Flags().GetString("folder")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	names, _ := cmd.Flags().GetStringSlice("name")
+
+	exp, ok := exporters[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown export format %q (want pet, warp, json, or bash)", args[0])
+	}
+
+	all, err := getMultiStore().List()
+	if err != nil {
+		return fmt.Errorf("listing workflows: %w", err)
+	}
+	workflows := exporter.Select(all, exporter.Filter{Folder: folder, Tags: tags, Names: names})
+	if len(workflows) == 0 {
+		return fmt.Errorf("no workflows match")
+	}
+
+	var w io.Writer = cmd.OutOrStdout()
+	var f *os.File
+	if output != "" {
+		f, err = os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", output, err)
+		}
+		w = f
+	}
+
+	result, err := exp.Export(w, workflows)
+	if f != nil {
+		// Close reports write errors the file system deferred, such as a
+		// full disk, so it decides whether the export succeeded.
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			return fmt.Errorf("writing %s: %w", output, closeErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("exporting: %w", err)
+	}
+
+	stderr := cmd.ErrOrStderr()
+	if len(result.Warnings) > 0 {
+		fmt.Fprintln(stderr, "Warnings (features not exported):")
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(stderr, "  %s\n", warning)
+		}
+	}
+	fmt.Fprintf(stderr, "Exported %d workflows (%d warnings)\n", result.Exported, len(result.Warnings))
+	return nil
+}
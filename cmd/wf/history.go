@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fredriklanga/wf/internal/runlog"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [name]",
+	Short: "Show workflow execution history",
+	Long: `Show recently picked, copied, pasted, and run workflows.
+
+Every command produced by wf pick, wf manage, and wf run is recorded in a
+local log with its parameter values, working directory, and exit code when
+wf ran it. Pass a workflow name to show only its entries.
+
+The log keeps only recent history: once it grows past 4 MiB, the oldest
+entries are dropped. To clear it, delete ~/.local/share/wf/history.jsonl.
+
+Examples:
+  wf history
+  wf history deploy --limit 5
+  wf history --since 7d --failed
+  wf history --stats`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().IntP("limit", "n", 20, "maximum number of entries to show (0 = all)")
+	historyCmd.Flags().String("since", "", "only show entries newer than a duration such as 36h or 7d")
+	historyCmd.Flags().Bool("failed", false, "only show runs that exited non-zero")
+	historyCmd.Flags().Bool("stats", false, "show per-workflow usage counts instead of entries")
+	historyCmd.Flags().Bool("json", false, "print entries as JSON lines")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	sinceRaw, _ := cmd.Flags().GetString("since")
+	failed, _ := cmd.Flags().GetBool("failed")
+	stats, _ := cmd.Flags().GetBool("stats")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	filter := runlog.Filter{FailedOnly: failed}
+	if len(args) == 1 {
+		filter.Workflow = args[0]
+	}
+	if sinceRaw != "" {
+		d, err := parseSince(sinceRaw)
+		if err != nil {
+			return err
+		}
+		filter.Since = time.Now().Add(-d)
+	}
+
+	all, err := getRunLog().Entries()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if stats {
+		// Stats cover every matching entry; --limit caps the rows shown.
+		entries := runlog.Query(all, filter)
+		rows := runlog.Stats(entries)
+		if limit > 0 && len(rows) > limit {
+			rows = rows[:limit]
+		}
+		return printHistoryStats(out, rows)
+	}
+
+	filter.Limit = limit
+	entries := runlog.Query(all, filter)
+	if asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No history found")
+		return nil
+	}
+
+	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("242"))
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	for _, e := range entries {
+		status := e.Action
+		if e.ExitCode != nil {
+			status = fmt.Sprintf("exit %d", *e.ExitCode)
+		}
+		status = fmt.Sprintf("%-7s", status)
+		if e.Failed() {
+			status = failStyle.Render(status)
+		}
+		fmt.Fprintf(out, "%s  %s  %s  %s\n",
+			timeStyle.Render(e.Time.Local().Format("2006-01-02 15:04")),
+			status,
+			nameStyle.Render(e.Workflow),
+			firstLine(e.Command))
+	}
+	return nil
+}
+
+func printHistoryStats(out io.Writer, rows []runlog.Stat) error {
+	if len(rows) == 0 {
+		fmt.Fprintln(out, "No history found")
+		return nil
+	}
+
+	width := len("WORKFLOW")
+	for _, r := range rows {
+		if len(r.Workflow) > width {
+			width = len(r.Workflow)
+		}
+	}
+
+	fmt.Fprintf(out, "%-*s  %5s  %5s  %6s  %s\n", width, "WORKFLOW", "USES", "RUNS", "FAILED", "LAST USED")
+	for _, r := range rows {
+		fmt.Fprintf(out, "%-*s  %5d  %5d  %6d  %s\n", width, r.Workflow, r.Count, r.Runs, r.Failures,
+			r.LastUsed.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// parseSince accepts Go durations plus a "d" suffix for whole days.
+func parseSince(raw string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid --since %q: use a duration such as 36h or 7d", raw)
+	}
+	return d, nil
+}
+
+// firstLine returns the first line of a command, marking truncation.
+func firstLine(s string) string {
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx] + " …"
+	}
+	return s
+}
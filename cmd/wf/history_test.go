@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	d, err := parseSince("7d")
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	d, err = parseSince("90m")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+
+	_, err = parseSince("soon")
+	assert.Error(t, err)
+	_, err = parseSince("-1h")
+	assert.Error(t, err)
+}
+
+func TestFirstLine(t *testing.T) {
+	assert.Equal(t, "echo hi", firstLine("echo hi"))
+	assert.Equal(t, "make build …", firstLine("make build\nmake test"))
+}
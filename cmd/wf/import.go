@@ -14,14 +14,25 @@ import (
 var importCmd = &cobra.Command{
 	Use:   "import",
 	Short: "Import workflows from external formats",
-	Long: `Import workflows from Pet TOML or Warp YAML files.
+	Long: `Import workflows from Pet TOML, Warp YAML, wf JSON bundle, Navi cheat,
+shell, or Markdown files.
 
 Supported formats:
-  wf import pet <file>   — Import from Pet TOML snippet file
-  wf import warp <file>  — Import from Warp YAML workflow file
+  wf import pet <file>       — Import from Pet TOML snippet file
+  wf import warp <file>      — Import from Warp YAML workflow file
+  wf import json <file>      — Import a bundle written by wf export json
+  wf import navi <file>      — Import from a Navi .cheat file
+  wf import shell <file>     — Import aliases and functions from a shell rc file
+  wf import markdown <file>  — Import shell code blocks from a runbook or tldr page
 
 By default, a dry-run preview is shown before importing.
-Use --force to skip the preview and import directly.`,
+Use --force to skip the preview and import directly.
+
+A workflow conflicts when one with the same name, or the same file name,
+already exists. --on-conflict decides what happens: skip keeps the existing
+workflow, rename imports under a free name such as deploy-2, overwrite
+replaces it, and ask (the default) prompts for each conflict and can show a
+diff. With --force, conflicts are skipped unless --on-conflict is given.`,
 }
 
 var importPetCmd = &cobra.Command{
@@ -38,11 +49,46 @@ var importWarpCmd = &cobra.Command{
 	RunE:  runImportWarp,
 }
 
+var importJSONCmd = &cobra.Command{
+	Use:   "json <file>",
+	Short: "Import workflows from a wf JSON bundle",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImportJSON,
+}
+
+var importNaviCmd = &cobra.Command{
+	Use:   "navi <file>",
+	Short: "Import workflows from a Navi .cheat file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImportNavi,
+}
+
+var importShellCmd = &cobra.Command{
+	Use:     "shell <file>",
+	Aliases: []string{"alias"},
+	Short:   "Import aliases and functions from a shell file such as ~/.bashrc",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runImportShell,
+}
+
+var importMarkdownCmd = &cobra.Command{
+	Use:     "markdown <file>",
+	Aliases: []string{"md"},
+	Short:   "Import shell code blocks from a Markdown runbook or tldr page",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runImportMarkdown,
+}
+
 func init() {
 	importCmd.PersistentFlags().Bool("force", false, "skip preview, import directly")
 	importCmd.PersistentFlags().String("folder", "", "target folder for imported workflows")
+	importCmd.PersistentFlags().String("on-conflict", string(importer.ConflictAsk), "when a workflow exists: skip, rename, overwrite or ask (skip with --force)")
 	importCmd.AddCommand(importPetCmd)
 	importCmd.AddCommand(importWarpCmd)
+	importCmd.AddCommand(importJSONCmd)
+	importCmd.AddCommand(importNaviCmd)
+	importCmd.AddCommand(importShellCmd)
+	importCmd.AddCommand(importMarkdownCmd)
 }
 
 func runImportPet(cmd *cobra.Command, args []string) error {
@@ -55,15 +101,39 @@ func runImportWarp(cmd *cobra.Command, args []string) error {
 	return runImport(cmd, args[0], imp, "Warp")
 }
 
-// importWorkflowEntry tracks a workflow through the import pipeline.
-type importWorkflowEntry struct {
-	workflow store.Workflow
-	conflict bool
+func runImportJSON(cmd *cobra.Command, args []string) error {
+	imp := &importer.JSONImporter{}
+	return runImport(cmd, args[0], imp, "JSON")
+}
+
+func runImportNavi(cmd *cobra.Command, args []string) error {
+	imp := &importer.NaviImporter{}
+	return runImport(cmd, args[0], imp, "Navi")
+}
+
+func runImportShell(cmd *cobra.Command, args []string) error {
+	imp := &importer.ShellImporter{}
+	return runImport(cmd, args[0], imp, "shell")
+}
+
+func runImportMarkdown(cmd *cobra.Command, args []string) error {
+	imp := &importer.MarkdownImporter{}
+	return runImport(cmd, args[0], imp, "Markdown")
 }
 
 func runImport(cmd *cobra.Command, filePath string, imp importer.Importer, formatName string) error {
 	force, _ := cmd.Flags().GetBool("force")
 	folder, _ := cmd.Flags().GetString("folder")
+	onConflict, _ := cmd.Flags().GetString("on-conflict")
+	policy, err := importer.ParseConflictPolicy(onConflict)
+	if err != nil {
+		return err
+	}
+	// --force is for unattended imports: unless a policy is given, keep
+	// existing workflows rather than prompting or overwriting them.
+	if force && !cmd.Flags().Changed("on-conflict") {
+		policy = importer.ConflictSkip
+	}
 
 	// 1. Open and read the source file
 	f, err := os.Open(filePath)
@@ -90,93 +160,72 @@ func runImport(cmd *cobra.Command, filePath string, imp importer.Importer, forma
 		return fmt.Errorf("no workflows to import")
 	}
 
-	// 4. Prepare entries with folder prefix and conflict detection
+	// 4. Apply the folder prefix and detect conflicts with the store
 	s := getStore()
-	entries := make([]importWorkflowEntry, 0, len(result.Workflows))
-
-	for _, wf := range result.Workflows {
+	workflows := make([]store.Workflow, len(result.Workflows))
+	for i, wf := range result.Workflows {
 		if folder != "" {
 			wf.Name = strings.Trim(folder, "/") + "/" + wf.Name
 		}
-		existing, err := s.Get(wf.Name)
-		conflict := err == nil && existing != nil
-		entries = append(entries, importWorkflowEntry{
-			workflow: wf,
-			conflict: conflict,
-		})
+		workflows[i] = wf
+	}
+	entries, err := importer.Plan(workflows, s)
+	if err != nil {
+		return err
 	}
 
-	// 5. Preview and conflict resolution (unless --force)
-	if !force {
-		scanner := bufio.NewScanner(os.Stdin)
+	scanner := bufio.NewScanner(os.Stdin)
 
-		// Print preview table
+	// 5. Preview (unless --force)
+	if !force {
 		fmt.Println("\nImport preview:")
 		for _, entry := range entries {
 			status := "new"
-			if entry.conflict {
+			switch {
+			case entry.Duplicate:
+				status = "duplicate in import"
+			case entry.Conflict():
 				status = "conflict"
 			}
-			fmt.Printf("  %-50s %s\n", entry.workflow.Name, status)
+			fmt.Printf("  %-50s %s\n", entry.Workflow.Name, status)
 		}
 
-		// Print warnings
 		if len(result.Warnings) > 0 {
 			fmt.Println("\nWarnings (unmappable fields):")
 			for _, w := range result.Warnings {
 				fmt.Printf("  %s\n", w)
 			}
 		}
+	}
 
-		// Handle conflicts interactively
-		var toRemove []int
-		for i, entry := range entries {
-			if !entry.conflict {
-				continue
-			}
-
-			fmt.Printf("\nConflict: '%s' already exists. [s]kip / [r]ename / [o]verwrite: ", entry.workflow.Name)
-			if !scanner.Scan() {
-				return fmt.Errorf("input cancelled")
-			}
-			choice := strings.TrimSpace(strings.ToLower(scanner.Text()))
-
-			switch {
-			case strings.HasPrefix(choice, "s"):
-				toRemove = append(toRemove, i)
-			case strings.HasPrefix(choice, "r"):
-				fmt.Print("New name: ")
-				if !scanner.Scan() {
-					return fmt.Errorf("input cancelled")
-				}
-				newName := strings.TrimSpace(scanner.Text())
-				if newName == "" {
-					return fmt.Errorf("name cannot be empty")
-				}
-				if folder != "" && !strings.HasPrefix(newName, strings.Trim(folder, "/")+"/") {
-					newName = strings.Trim(folder, "/") + "/" + newName
-				}
-				entries[i].workflow.Name = newName
-			case strings.HasPrefix(choice, "o"):
-				// Keep in import list — will overwrite
-			default:
-				return fmt.Errorf("invalid choice: %s", choice)
-			}
-		}
-
-		// Remove skipped entries (in reverse order to keep indices stable)
-		for i := len(toRemove) - 1; i >= 0; i-- {
-			idx := toRemove[i]
-			entries = append(entries[:idx], entries[idx+1:]...)
+	// 6. Resolve conflicts
+	decide := func(e *importer.Entry) (importer.ConflictPolicy, string, error) {
+		return policy, "", nil
+	}
+	if policy == importer.ConflictAsk {
+		decide = func(e *importer.Entry) (importer.ConflictPolicy, string, error) {
+			return askConflict(scanner, e, folder)
 		}
+	}
+	if err := importer.Resolve(entries, s, decide); err != nil {
+		return err
+	}
 
-		if len(entries) == 0 {
-			fmt.Println("No workflows to import after conflict resolution.")
-			return nil
+	pending := 0
+	for _, entry := range entries {
+		if entry.Action != importer.ActionSkip {
+			pending++
 		}
+	}
+	if pending == 0 {
+		fmt.Println("No workflows to import after conflict resolution.")
+		printImportSummary(entries)
+		return nil
+	}
 
-		// Confirm
-		fmt.Printf("\nProceed with import of %d workflows? (y/n): ", len(entries))
+	// 7. Confirm (unless --force)
+	if !force {
+		fmt.Printf("\nProceed with import of %d workflows? (y/n): ", pending)
 		if !scanner.Scan() {
 			return fmt.Errorf("input cancelled")
 		}
@@ -187,41 +236,124 @@ func runImport(cmd *cobra.Command, filePath string, imp importer.Importer, forma
 		}
 	}
 
-	// 6. Save workflows
+	// 8. Save workflows
 	// Build a map of warnings keyed by both the original identifier (quoted name
 	// in warning string) and the slugified name (for Pet, where warnings use
 	// description but workflow Name is slugified).
 	warnMap := buildWarningMap(result.Warnings)
 
-	imported := 0
-	for _, entry := range entries {
-		wf := &entry.workflow
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Action == importer.ActionSkip {
+			continue
+		}
+		wf := &entry.Workflow
 		if err := s.Save(wf); err != nil {
 			fmt.Printf("  Error saving '%s': %s\n", wf.Name, err)
+			entry.Action = importer.ActionSkip
 			continue
 		}
 
 		// Inject unmappable fields as YAML comments.
-		// Try matching by workflow name (Warp) or by the original name in
-		// the warning string (which buildWarningMap indexes by both raw and
-		// slugified keys).
-		warns := warnMap[wf.Name]
+		// Warnings are keyed by the name the importer produced, so look
+		// renamed workflows up by their original name, and Pet snippets
+		// by description.
+		origName := wf.Name
+		if entry.RenamedFrom != "" {
+			origName = entry.RenamedFrom
+		}
+		if folder != "" {
+			origName = strings.TrimPrefix(origName, strings.Trim(folder, "/")+"/")
+		}
+		warns := warnMap[origName]
 		if len(warns) == 0 {
-			// For Pet: warnings are keyed by description; also try
-			// the description field directly.
 			warns = warnMap[wf.Description]
 		}
 		if len(warns) > 0 {
 			injectComments(s, wf.Name, formatName, warns)
 		}
-
-		imported++
 	}
 
-	fmt.Printf("Imported %d workflows\n", imported)
+	printImportSummary(entries)
 	return nil
 }
 
+// askConflict shows a conflict and asks how to resolve it. [d]iff prints
+// the changes the import would make, then asks again.
+func askConflict(scanner *bufio.Scanner, e *importer.Entry, folder string) (importer.ConflictPolicy, string, error) {
+	problem := "already exists"
+	if e.Duplicate {
+		problem = "appears earlier in this import"
+	}
+	for {
+		fmt.Printf("\nConflict: '%s' %s. [s]kip / [r]ename / [o]verwrite / [d]iff: ", e.Workflow.Name, problem)
+		if !scanner.Scan() {
+			return "", "", fmt.Errorf("input cancelled")
+		}
+		choice := strings.TrimSpace(strings.ToLower(scanner.Text()))
+
+		switch {
+		case strings.HasPrefix(choice, "s"):
+			return importer.ConflictSkip, "", nil
+		case strings.HasPrefix(choice, "o"):
+			return importer.ConflictOverwrite, "", nil
+		case strings.HasPrefix(choice, "d"):
+			diff, err := importer.Diff(*e.Existing, e.Workflow)
+			if err != nil {
+				return "", "", err
+			}
+			if diff == "" {
+				fmt.Println("  (no differences)")
+				continue
+			}
+			fmt.Print(diff)
+		case strings.HasPrefix(choice, "r"):
+			fmt.Print("New name (empty for automatic): ")
+			if !scanner.Scan() {
+				return "", "", fmt.Errorf("input cancelled")
+			}
+			newName := strings.TrimSpace(scanner.Text())
+			if newName != "" && folder != "" && !strings.HasPrefix(newName, strings.Trim(folder, "/")+"/") {
+				newName = strings.Trim(folder, "/") + "/" + newName
+			}
+			return importer.ConflictRename, newName, nil
+		default:
+			fmt.Printf("  invalid choice: %s\n", choice)
+		}
+	}
+}
+
+// printImportSummary lists what the import created, updated and skipped.
+func printImportSummary(entries []importer.Entry) {
+	var created, updated, skipped int
+	for _, e := range entries {
+		switch e.Action {
+		case importer.ActionCreate:
+			created++
+		case importer.ActionUpdate:
+			updated++
+		case importer.ActionSkip:
+			skipped++
+		}
+	}
+
+	fmt.Printf("\nImported %d workflows (%d created, %d updated, %d skipped)\n", created+updated, created, updated, skipped)
+	for _, e := range entries {
+		switch {
+		case e.Action == importer.ActionCreate && e.RenamedFrom != "":
+			fmt.Printf("  created  %s (renamed from %s)\n", e.Workflow.Name, e.RenamedFrom)
+		case e.Action == importer.ActionCreate:
+			fmt.Printf("  created  %s\n", e.Workflow.Name)
+		case e.Action == importer.ActionUpdate:
+			fmt.Printf("  updated  %s\n", e.Workflow.Name)
+		case e.Action == importer.ActionSkip && e.Superseded:
+			fmt.Printf("  skipped  %s (replaced by a later workflow in this import)\n", e.Workflow.Name)
+		case e.Action == importer.ActionSkip:
+			fmt.Printf("  skipped  %s\n", e.Workflow.Name)
+		}
+	}
+}
+
 // buildWarningMap groups warnings by workflow name/description.
 // Warning format is: '<format> <type> "<name>": <field>: <value>'
 // The quoted name may be either the workflow name (Warp) or the snippet
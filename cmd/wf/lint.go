@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fredriklanga/wf/internal/config"
+	"github.com/fredriklanga/wf/internal/lint"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [path]",
+	Short: "Check workflow files for problems",
+	Long: `Check workflow files for problems that stop them loading or working.
+
+Lints your workflows directory, or the file or directory given, such as a
+checkout of a shared workflow repository. Reported problems include
+unparsable YAML, unknown keys, args that match no placeholder, placeholders
+with no arg, enum defaults that are not an option, invalid list field
+indexes, and workflow names used by more than one file.
+
+wf lint exits 1 when it finds errors (or warnings, with --strict), so it can
+gate CI. Use --json for machine-readable output.
+
+Examples:
+  wf lint
+  wf lint ./team-workflows --strict
+  wf lint deploy.yaml --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().Bool("json", false, "print the report as JSON")
+	lintCmd.Flags().Bool("strict", false, "exit non-zero on warnings as well as errors")
+}
+
+// lintJSON is the --json output: the report plus totals for CI summaries.
+type lintJSON struct {
+	lint.Report
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	strict, _ := cmd.Flags().GetBool("strict")
+
+	path := config.WorkflowsDir()
+	if len(args) == 1 {
+		path = args[0]
+	}
+	report, err := lint.Path(path)
+	if err != nil {
+		return fmt.Errorf("linting %s: %w", path, err)
+	}
+	if report.Issues == nil {
+		report.Issues = []lint.Issue{}
+	}
+	errs, warns := report.Count(lint.SeverityError), report.Count(lint.SeverityWarning)
+
+	out := cmd.OutOrStdout()
+	if asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(lintJSON{Report: report, Errors: errs, Warnings: warns}); err != nil {
+			return err
+		}
+	} else {
+		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+		warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+		for _, issue := range report.Issues {
+			line := issue.String()
+			if issue.Severity == lint.SeverityError {
+				line = errStyle.Render(line)
+			} else {
+				line = warnStyle.Render(line)
+			}
+			fmt.Fprintln(out, line)
+		}
+		fmt.Fprintf(out, "%d file(s) checked: %d error(s), %d warning(s)\n", report.Files, errs, warns)
+	}
+
+	if errs > 0 || (strict && warns > 0) {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitCodeError{code: 1}
+	}
+	return nil
+}
@@ -19,6 +19,8 @@ Workflows in subfolders are included automatically.`,
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	startSourceAutoUpdate()
+
 	s := getMultiStore()
 	workflows, err := s.List()
 	if err != nil {
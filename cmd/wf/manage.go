@@ -37,6 +37,8 @@ func init() {
 }
 
 func runManage(cmd *cobra.Command, args []string) error {
+	startSourceAutoUpdate()
+
 	result, err := manage.Run(getMultiStore())
 	if err != nil {
 		return err
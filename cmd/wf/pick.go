@@ -6,7 +6,12 @@ import (
 
 	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fredriklanga/wf/internal/cmdcache"
+	"github.com/fredriklanga/wf/internal/config"
+	"github.com/fredriklanga/wf/internal/params"
 	"github.com/fredriklanga/wf/internal/picker"
+	"github.com/fredriklanga/wf/internal/runlog"
+	"github.com/fredriklanga/wf/internal/usage"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +33,8 @@ func init() {
 }
 
 func runPick(cmd *cobra.Command, args []string) error {
+	startSourceAutoUpdate()
+
 	// Load workflows synchronously before creating tea.Program (PICK-02 performance).
 	s := getMultiStore()
 	workflows, err := s.List()
@@ -53,7 +60,18 @@ func runPick(cmd *cobra.Command, args []string) error {
 		defer tty.Close()
 	}
 
-	m := picker.New(workflows)
+	// Usage state only affects ranking; an unreadable file is ignored rather
+	// than blocking the picker.
+	u, err := usage.Load(config.UsagePath())
+	if err != nil {
+		u = nil
+	}
+
+	m := picker.New(workflows, u)
+	m.SetCommandCache(cmdcache.New(config.CommandCacheDir()))
+	if err := params.LoadCommandDefaults(); err != nil {
+		fmt.Fprintf(os.Stderr, "wf: ignoring invalid config: %v\n", err)
+	}
 	p := tea.NewProgram(
 		m,
 		tea.WithAltScreen(), // Clean overlay, restores on exit
@@ -75,16 +93,26 @@ func runPick(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	entry := runlog.Entry{
+		Workflow: fm.Workflow,
+		Action:   runlog.ActionPrint,
+		Command:  fm.MaskedResult,
+		Params:   fm.Values,
+	}
+
 	// --copy flag: write to clipboard instead of stdout.
 	if pickCopy {
 		if err := clipboard.WriteAll(fm.Result); err != nil {
 			return fmt.Errorf("clipboard: %w", err)
 		}
+		entry.Action = runlog.ActionCopy
+		recordRun(entry)
 		fmt.Fprintln(os.Stderr, "Copied to clipboard")
 		return nil
 	}
 
 	// Default: write to stdout for shell function capture.
+	recordRun(entry)
 	fmt.Fprintln(os.Stdout, fm.Result)
 	return nil
 }
@@ -68,6 +68,10 @@ func runRegister(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Captured: %s\n", command)
 
+	// A shell command has no wf placeholders yet; keep any {{ in it, e.g. a
+	// docker --format string, as literal text.
+	command = template.EscapeBraces(command)
+
 	// Auto-detect parameters
 	command = applyDetectedParams(command, scanner)
 
@@ -27,10 +27,10 @@ func init() {
 func runRm(cmd *cobra.Command, args []string) error {
 	name := args[0]
 	force, _ := cmd.Flags().GetBool("force")
-	s := getStore()
+	s, storeName := resolveStore(name)
 
 	// Verify workflow exists before prompting
-	if _, err := s.Get(name); err != nil {
+	if _, err := s.Get(storeName); err != nil {
 		return fmt.Errorf("workflow %q not found", name)
 	}
 
@@ -50,7 +50,7 @@ func runRm(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if err := s.Delete(name); err != nil {
+	if err := s.Delete(storeName); err != nil {
 		return fmt.Errorf("deleting workflow: %w", err)
 	}
 
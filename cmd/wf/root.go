@@ -1,7 +1,10 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/fredriklanga/wf/internal/config"
+	"github.com/fredriklanga/wf/internal/runlog"
 	"github.com/fredriklanga/wf/internal/source"
 	"github.com/fredriklanga/wf/internal/store"
 	"github.com/spf13/cobra"
@@ -34,10 +37,14 @@ func init() {
 	rootCmd.AddCommand(pickCmd)
 	rootCmd.AddCommand(manageCmd)
 	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(registerCmd)
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(autofillCmd)
 	rootCmd.AddCommand(sourceCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(lintCmd)
 }
 
 // getStore returns the shared YAMLStore instance, creating it if needed.
@@ -48,19 +55,81 @@ func getStore() *store.YAMLStore {
 	return yamlStore
 }
 
-// getMultiStore returns a Store that merges local and remote workflows.
-// If no remote sources are configured, it returns the local store directly
-// to avoid any overhead.
+// getRunLog returns the execution history log.
+func getRunLog() *runlog.Log {
+	return runlog.New(config.RunLogPath())
+}
+
+// recordRun appends an entry to the run log. History is best-effort: a
+// failure to write it never fails the command that produced the entry.
+func recordRun(e runlog.Entry) {
+	_ = getRunLog().Append(e)
+}
+
+// getMultiStore returns a Store that merges local and remote workflows,
+// plus the current project's workflows under "project/". If there are no
+// remote sources and no project, it returns the local store directly to
+// avoid any overhead.
 func getMultiStore() store.Store {
 	local := getStore()
 	mgr := source.NewManager(config.SourcesDir())
 	sources := mgr.SourceDirs()
-	if len(sources) == 0 {
+	project := getProjectStore()
+	if len(sources) == 0 && project == nil {
 		return local
 	}
-	remote := make(map[string]store.Store, len(sources))
+	remote := make(map[string]store.Store, len(sources)+1)
 	for alias, dir := range sources {
-		remote[alias] = store.NewRemoteStore(dir)
+		remote[alias] = newSourceStore(mgr, alias, dir)
+	}
+	if project != nil {
+		remote[store.ProjectAlias] = project
 	}
 	return store.NewMultiStore(local, remote)
 }
+
+// getProjectStore returns a store over the .wf/ directory of the project
+// containing the current directory, or nil outside a project.
+func getProjectStore() *store.YAMLStore {
+	dir := config.ProjectDir()
+	if dir == "" {
+		return nil
+	}
+	return store.NewYAMLStore(dir)
+}
+
+// newSourceStore returns the store for a source: writable when the source
+// publishes its changes, read-only otherwise.
+func newSourceStore(mgr *source.Manager, alias, dir string) *store.RemoteStore {
+	if publish := mgr.Publisher(alias); publish != nil {
+		return store.NewWritableRemoteStore(dir, publish)
+	}
+	return store.NewRemoteStore(dir)
+}
+
+// fileStore is a store whose workflows are files that can be opened in an
+// editor.
+type fileStore interface {
+	store.Store
+	WorkflowPath(name string) string
+}
+
+// resolveStore returns the store holding name and the name within it: the
+// project store for "project/..." names inside a project, a remote source's
+// store for "<alias>/..." names, and the local store otherwise.
+func resolveStore(name string) (fileStore, string) {
+	prefix, rest, ok := strings.Cut(name, "/")
+	if !ok {
+		return getStore(), name
+	}
+	if prefix == store.ProjectAlias {
+		if project := getProjectStore(); project != nil {
+			return project, rest
+		}
+	}
+	mgr := source.NewManager(config.SourcesDir())
+	if dir, found := mgr.SourceDirs()[prefix]; found {
+		return newSourceStore(mgr, prefix, dir), rest
+	}
+	return getStore(), name
+}
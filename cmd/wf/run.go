@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/runlog"
+	"github.com/fredriklanga/wf/internal/runner"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Run a workflow directly",
+	Long: `Render a workflow with the given parameters and execute it in your shell.
+
+Parameters are supplied with --param name=value (repeatable). Parameters
+without a value or default are prompted for interactively; use --no-input
+to fail instead, e.g. in CI scripts and Makefiles.
+
+Multi-step workflows run their steps in order and report a status for each
+step. Steps marked confirm: true ask before running; use --yes to accept all
+confirmations up front.
+
+The workflow's exit code is passed through as wf's exit code.
+
+Examples:
+  wf run deploy --param env=prod --param app=api
+  wf run team/rollback -p version=1.4.2 --no-input`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRun,
+}
+
+func init() {
+	runCmd.Flags().StringArrayP("param", "p", nil, "parameter value as name=value (repeatable)")
+	runCmd.Flags().Bool("no-input", false, "fail instead of prompting for missing parameters")
+	runCmd.Flags().Bool("dry-run", false, "print the rendered command without executing it")
+	runCmd.Flags().BoolP("yes", "y", false, "accept step confirmation prompts without asking")
+}
+
+// loadWorkflow gets workflow name from s. Only a missing workflow is
+// reported as not found; a file that exists but cannot be read, such as
+// one with a newer schema, keeps its own error.
+func loadWorkflow(s store.Store, name string) (*store.Workflow, error) {
+	wf, err := s.Get(name)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("workflow %q not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading workflow %q: %w", name, err)
+	}
+	return wf, nil
+}
+
+// exitCodeError carries a child process exit code back to main so it can be
+// used as wf's own exit status without printing an error message.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	rawParams, _ := cmd.Flags().GetStringArray("param")
+	noInput, _ := cmd.Flags().GetBool("no-input")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	supplied, err := parseParamFlags(rawParams)
+	if err != nil {
+		return err
+	}
+
+	wf, err := loadWorkflow(getMultiStore(), name)
+	if err != nil {
+		return err
+	}
+
+	if err := params.CheckDependencies(*wf); err != nil {
+		return fmt.Errorf("workflow %q: %w", name, err)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	ps := params.ForWorkflow(*wf)
+	values, err := resolveRunValues(ps, supplied, noInput, scanner, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	// Secret values never reach the terminal or the run log.
+	masked := params.MaskSecrets(ps, values)
+	rendered, err := runner.Render(*wf, values)
+	if err != nil {
+		return err
+	}
+	display, err := runner.RenderMasked(*wf, masked)
+	if err != nil {
+		return err
+	}
+	var code int
+	if wf.IsMultiStep() {
+		code, err = runSteps(cmd, *wf, values, masked, dryRun, func(step runner.PlannedStep) bool {
+			if yes {
+				return true
+			}
+			if noInput {
+				return false
+			}
+			return confirmStep(scanner, os.Stderr, step)
+		})
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+	} else {
+		if dryRun {
+			fmt.Fprintln(cmd.OutOrStdout(), display)
+			return nil
+		}
+		code, err = runner.Run(cmd.Context(), rendered, runner.Options{})
+		if err != nil {
+			return err
+		}
+	}
+
+	recordRun(runlog.Entry{
+		Workflow: wf.Name,
+		Action:   runlog.ActionRun,
+		Command:  display,
+		Params:   masked,
+		ExitCode: &code,
+	})
+
+	if code != 0 {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitCodeError{code: code}
+	}
+	return nil
+}
+
+// runSteps executes a multi-step workflow, printing one status line per step
+// to stderr. With dryRun, the plan is printed to stdout instead. Anything
+// shown to the user is rendered from masked so secrets are not echoed.
+func runSteps(cmd *cobra.Command, wf store.Workflow, values, masked map[string]string, dryRun bool, confirm func(runner.PlannedStep) bool) (int, error) {
+	plan, err := runner.Plan(wf, values)
+	if err != nil {
+		return 0, err
+	}
+	shown, err := runner.PlanMasked(wf, masked)
+	if err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		for _, step := range shown {
+			note := ""
+			if step.Skip {
+				note = " (skipped)"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "# %s%s\n%s\n", step.Name, note, step.Command)
+		}
+		return 0, nil
+	}
+
+	total := len(plan)
+	results, code := runner.RunSteps(cmd.Context(), plan, runner.Options{}, runner.StepHooks{
+		Confirm: func(step runner.PlannedStep) bool {
+			return confirm(shown[step.Index])
+		},
+		OnStart: func(step runner.PlannedStep) {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", step.Index+1, total, step.Name)
+		},
+	})
+
+	fmt.Fprintln(os.Stderr)
+	for _, r := range results {
+		line := fmt.Sprintf("  %-10s %s", r.Status, r.Step.Name)
+		if r.Status == runner.StepFailed {
+			if r.Err != nil {
+				line += fmt.Sprintf(" (%v)", r.Err)
+			} else {
+				line += fmt.Sprintf(" (exit %d)", r.ExitCode)
+			}
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
+	return code, nil
+}
+
+// confirmStep asks whether a confirm: true step should run. Anything other
+// than y/yes declines.
+func confirmStep(scanner *bufio.Scanner, out io.Writer, step runner.PlannedStep) bool {
+	fmt.Fprintf(out, "Run step %q?\n  %s\n[y/N]: ", step.Name, step.Command)
+	if !scanner.Scan() {
+		return false
+	}
+	answer := strings.TrimSpace(strings.ToLower(scanner.Text()))
+	return answer == "y" || answer == "yes"
+}
+
+// parseParamFlags converts repeated name=value flags into a value map.
+// Only the first "=" separates name from value, so values may contain "=".
+func parseParamFlags(raw []string) (map[string]string, error) {
+	values := make(map[string]string, len(raw))
+	for _, kv := range raw {
+		idx := strings.IndexByte(kv, '=')
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid --param %q: expected name=value", kv)
+		}
+		values[strings.TrimSpace(kv[:idx])] = kv[idx+1:]
+	}
+	return values, nil
+}
+
+// resolveRunValues merges supplied values with defaults and prompts for any
+// parameter that still has no value. With noInput, missing parameters are
+// reported as a single error instead of prompting.
+func resolveRunValues(ps []template.Param, supplied map[string]string, noInput bool, scanner *bufio.Scanner, out io.Writer) (map[string]string, error) {
+	known := make(map[string]bool, len(ps))
+	for _, p := range ps {
+		known[p.Name] = true
+	}
+	for name := range supplied {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+	}
+
+	values := make(map[string]string, len(ps))
+	var missing []template.Param
+	for _, p := range ps {
+		if v, ok := supplied[p.Name]; ok {
+			values[p.Name] = v
+			continue
+		}
+		if p.Default != "" {
+			values[p.Name] = p.Default
+			continue
+		}
+		missing = append(missing, p)
+	}
+	if err := checkRunValues(ps, values); err != nil {
+		return nil, err
+	}
+
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	if noInput {
+		var names []string
+		for _, p := range missing {
+			if p.Type == template.ParamSecret {
+				v, ok, err := params.ResolveSecret(p)
+				if err != nil {
+					return nil, fmt.Errorf("parameter %q: %w", p.Name, err)
+				}
+				if ok {
+					values[p.Name] = v
+					continue
+				}
+			}
+			names = append(names, p.Name)
+		}
+		if len(names) == 0 {
+			return values, checkRunValues(ps, values)
+		}
+		return nil, fmt.Errorf("missing values for parameters: %s (use --param name=value)", strings.Join(names, ", "))
+	}
+
+	for _, p := range missing {
+		if p.Type == template.ParamSecret {
+			if v, ok, err := params.ResolveSecret(p); ok {
+				values[p.Name] = v
+				continue
+			} else if err != nil {
+				fmt.Fprintf(out, "warning: %s: %v\n", p.Name, err)
+			}
+		}
+		for {
+			v, err := promptParam(scanner, out, p)
+			if err != nil {
+				return nil, err
+			}
+			if err := params.Validate(p, v); err != nil {
+				fmt.Fprintf(out, "  ✗ %v\n", err)
+				continue
+			}
+			values[p.Name] = v
+			break
+		}
+	}
+	return values, checkRunValues(ps, values)
+}
+
+// checkRunValues validates the values that are already known, whether
+// supplied with --param, taken from a default, or resolved from a secret
+// source. Params without a value yet are skipped.
+func checkRunValues(ps []template.Param, values map[string]string) error {
+	for _, p := range ps {
+		v, ok := values[p.Name]
+		if !ok {
+			continue
+		}
+		if err := params.Validate(p, v); err != nil {
+			return fmt.Errorf("parameter %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// promptAttempts is how many times promptParam asks before giving up on an
+// empty answer.
+const promptAttempts = 3
+
+// promptParam asks for a single parameter value. Enum parameters list their
+// options and accept either the option number or its literal value. Secret
+// parameters are read without echo when stdin is a terminal. An empty answer
+// is asked again, up to promptAttempts times.
+func promptParam(scanner *bufio.Scanner, out io.Writer, p template.Param) (string, error) {
+	if p.Type == template.ParamEnum && len(p.Options) > 0 {
+		for i, opt := range p.Options {
+			fmt.Fprintf(out, "  %d) %s\n", i+1, opt)
+		}
+	}
+
+	var answer string
+	for attempt := 1; answer == ""; attempt++ {
+		if attempt > promptAttempts {
+			return "", fmt.Errorf("parameter %q is required", p.Name)
+		}
+		if attempt > 1 {
+			fmt.Fprintln(out, "  ✗ required")
+		}
+		fmt.Fprintf(out, "%s: ", p.Name)
+		if p.Type == template.ParamSecret && stdinIsTerminal() {
+			secret, err := readSecret()
+			fmt.Fprintln(out)
+			if err != nil {
+				return "", fmt.Errorf("reading %s: %w", p.Name, err)
+			}
+			answer = strings.TrimSpace(secret)
+		} else {
+			if !scanner.Scan() {
+				return "", fmt.Errorf("input cancelled")
+			}
+			answer = strings.TrimSpace(scanner.Text())
+		}
+	}
+
+	if p.Type == template.ParamEnum && len(p.Options) > 0 {
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(p.Options) {
+			return p.Options[n-1], nil
+		}
+	}
+	return answer, nil
+}
+
+// stdinIsTerminal and readSecret are variables so tests can drive secret
+// prompts without a terminal.
+var (
+	stdinIsTerminal = func() bool { return term.IsTerminal(os.Stdin.Fd()) }
+	readSecret      = func() (string, error) {
+		b, err := term.ReadPassword(os.Stdin.Fd())
+		return string(b), err
+	}
+)
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseParamFlags(t *testing.T) {
+	values, err := parseParamFlags([]string{"env=prod", "query=a=b", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "query": "a=b", "empty": ""}, values)
+
+	_, err = parseParamFlags([]string{"novalue"})
+	require.Error(t, err)
+	_, err = parseParamFlags([]string{"=value"})
+	require.Error(t, err)
+}
+
+func TestResolveRunValues_SuppliedAndDefaults(t *testing.T) {
+	ps := template.ExtractParams("deploy {{app}} --env {{env:staging}}")
+	values, err := resolveRunValues(ps, map[string]string{"app": "api"}, true, bufio.NewScanner(strings.NewReader("")), &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "api", "env": "staging"}, values)
+}
+
+func TestResolveRunValues_NoInputReportsMissing(t *testing.T) {
+	ps := template.ExtractParams("deploy {{app}} {{region}} {{env:staging}}")
+	_, err := resolveRunValues(ps, nil, true, bufio.NewScanner(strings.NewReader("")), &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "app, region")
+}
+
+func TestResolveRunValues_UnknownParam(t *testing.T) {
+	ps := template.ExtractParams("deploy {{app}}")
+	_, err := resolveRunValues(ps, map[string]string{"ap": "api"}, true, bufio.NewScanner(strings.NewReader("")), &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"ap"`)
+}
+
+func TestResolveRunValues_PromptsForMissing(t *testing.T) {
+	ps := template.ExtractParams("deploy {{app}} {{env|dev|prod}}")
+	var out bytes.Buffer
+	values, err := resolveRunValues(ps, nil, false, bufio.NewScanner(strings.NewReader("api\n2\n")), &out)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "api", "env": "prod"}, values)
+	assert.Contains(t, out.String(), "2) prod")
+}
+
+func TestResolveRunValues_Validation(t *testing.T) {
+	ps := []template.Param{
+		{Name: "port", Default: "http", Validation: &template.Validation{Kind: "port"}},
+		{Name: "replicas", Validation: &template.Validation{Kind: "int", Min: floatPtr(1)}},
+	}
+
+	_, err := resolveRunValues(ps, map[string]string{"replicas": "2"}, true, nil, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `parameter "port": must be a port`)
+
+	// Invalid prompted values are asked for again.
+	var out bytes.Buffer
+	values, err := resolveRunValues(ps, map[string]string{"port": "8080"}, false, bufio.NewScanner(strings.NewReader("0\n3\n")), &out)
+	require.NoError(t, err)
+	assert.Equal(t, "3", values["replicas"])
+	assert.Contains(t, out.String(), "✗ must be at least 1")
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestResolveRunValues_SecretFromEnvAndNoInput(t *testing.T) {
+	t.Setenv("WF_TEST_RUN_SECRET", "tok")
+	ps := []template.Param{
+		{Name: "token", Type: template.ParamSecret, SecretEnv: "WF_TEST_RUN_SECRET"},
+		{Name: "other", Type: template.ParamSecret, SecretEnv: "WF_TEST_RUN_UNSET"},
+	}
+
+	values, err := resolveRunValues(ps, map[string]string{"other": "x"}, true, nil, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"token": "tok", "other": "x"}, values)
+
+	_, err = resolveRunValues(ps, nil, true, nil, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "other")
+}
+
+func TestPromptParam_SecretReadsWithoutEcho(t *testing.T) {
+	origTerm, origRead := stdinIsTerminal, readSecret
+	t.Cleanup(func() { stdinIsTerminal, readSecret = origTerm, origRead })
+	stdinIsTerminal = func() bool { return true }
+	readSecret = func() (string, error) { return "hunter2", nil }
+
+	var out bytes.Buffer
+	v, err := promptParam(bufio.NewScanner(strings.NewReader("")), &out, template.Param{Name: "token", Type: template.ParamSecret})
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+	assert.NotContains(t, out.String(), "hunter2")
+}
+
+func TestPromptParam_EmptyAnswerAsksAgain(t *testing.T) {
+	p := template.Param{Name: "host"}
+
+	var out bytes.Buffer
+	v, err := promptParam(bufio.NewScanner(strings.NewReader("\n\nexample.com\n")), &out, p)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", v)
+	assert.Equal(t, 2, strings.Count(out.String(), "✗ required"))
+
+	_, err = promptParam(bufio.NewScanner(strings.NewReader("\n\n\nexample.com\n")), &bytes.Buffer{}, p)
+	assert.EqualError(t, err, `parameter "host" is required`)
+}
+
+func TestLoadWorkflow_ReportsWhyItFailed(t *testing.T) {
+	dir := t.TempDir()
+	s := store.NewYAMLStore(dir)
+	require.NoError(t, s.Save(&store.Workflow{Name: "ok", Command: "ls"}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "future.yaml"), []byte("version: 99\nname: future\ncommand: ls\n"), 0644))
+
+	wf, err := loadWorkflow(s, "ok")
+	require.NoError(t, err)
+	assert.Equal(t, "ls", wf.Command)
+
+	_, err = loadWorkflow(s, "missing")
+	assert.EqualError(t, err, `workflow "missing" not found`)
+
+	_, err = loadWorkflow(s, "future")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "not found")
+	assert.Contains(t, err.Error(), "schema version 99 is newer")
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/fredriklanga/wf/internal/config"
 	"github.com/fredriklanga/wf/internal/source"
 	"github.com/spf13/cobra"
@@ -14,29 +19,63 @@ import (
 var sourceCmd = &cobra.Command{
 	Use:   "source",
 	Short: "Manage remote workflow sources",
-	Long: `Manage remote git repositories as workflow sources.
+	Long: `Manage remote git repositories and local directories as workflow sources.
 
-Add remote repos to make their workflows available alongside your local ones
-in picker, manage, and list commands.`,
+Add remote repos or shared directories to make their workflows available
+alongside your local ones in picker, manage, and list commands.`,
 }
 
-var sourceNameFlag string
+var (
+	sourceNameFlag string
+	sourceRefFlag  string
+	sourcePathFlag string
+)
 
 var sourceAddCmd = &cobra.Command{
-	Use:   "add <git-url>",
+	Use:   "add <git-url> | --path <dir>",
 	Short: "Add a remote workflow source",
 	Long: `Clone a git repository and register it as a workflow source.
 
 The repository name is used as the alias by default. Use --name to specify
-a custom alias. Remote workflows appear with the alias prefix (e.g., "team/deploy").`,
-	Args: cobra.ExactArgs(1),
+a custom alias. Remote workflows appear with the alias prefix (e.g., "team/deploy").
+
+Use --ref to pin the source to a branch, tag or commit instead of following
+the default branch; see 'wf source pin'.
+
+Use --path instead of a URL to register a local directory, such as a
+monorepo's ./ops/workflows or a network share, or a single workflow file.
+It is read in place: nothing is cloned, git is not needed, and
+'wf source update' leaves it alone.`,
+	Example: `  wf source add https://github.com/team/workflows.git
+  wf source add git@github.com:team/workflows.git --ref v2.3
+  wf source add --path ./ops/workflows --name ops`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if sourcePathFlag != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("give either a git URL or --path, not both")
+			}
+			if sourceRefFlag != "" {
+				return fmt.Errorf("--ref only applies to git sources")
+			}
+			return nil
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := config.EnsureSourcesDir(); err != nil {
 			return err
 		}
 		mgr := source.NewManager(config.SourcesDir())
+		if sourcePathFlag != "" {
+			if err := mgr.AddPath(sourcePathFlag, sourceNameFlag); err != nil {
+				return err
+			}
+			added := mgr.List()[len(mgr.List())-1]
+			fmt.Fprintf(os.Stderr, "Added source %q from %s\n", added.Alias, added.Path)
+			return nil
+		}
 		url := args[0]
-		if err := mgr.Add(cmd.Context(), url, sourceNameFlag); err != nil {
+		if err := mgr.Add(cmd.Context(), url, sourceNameFlag, sourceRefFlag); err != nil {
 			return err
 		}
 		// Determine the effective alias for display
@@ -48,28 +87,21 @@ a custom alias. Remote workflows appear with the alias prefix (e.g., "team/deplo
 				alias = sources[len(sources)-1].Alias
 			}
 		}
+		if sourceRefFlag != "" {
+			fmt.Fprintf(os.Stderr, "Added source %q from %s at %s\n", alias, url, sourceRefFlag)
+			return nil
+		}
 		fmt.Fprintf(os.Stderr, "Added source %q from %s\n", alias, url)
 		return nil
 	},
 }
 
 var sourceRemoveCmd = &cobra.Command{
-	Use:   "remove <alias>",
-	Short: "Remove a remote workflow source",
-	Long:  `Remove a previously added remote source and delete its cloned repository.`,
-	Args:  cobra.ExactArgs(1),
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if len(args) != 0 {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-		mgr := source.NewManager(config.SourcesDir())
-		sources := mgr.List()
-		aliases := make([]string, len(sources))
-		for i, s := range sources {
-			aliases[i] = s.Alias
-		}
-		return aliases, cobra.ShellCompDirectiveNoFileComp
-	},
+	Use:               "remove <alias>",
+	Short:             "Remove a remote workflow source",
+	Long:              `Remove a previously added remote source and delete its cloned repository.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSourceAlias,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := config.EnsureSourcesDir(); err != nil {
 			return err
@@ -83,26 +115,41 @@ var sourceRemoveCmd = &cobra.Command{
 	},
 }
 
+// completeSourceAlias completes the first argument with configured aliases.
+func completeSourceAlias(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	mgr := source.NewManager(config.SourcesDir())
+	sources := mgr.List()
+	aliases := make([]string, len(sources))
+	for i, s := range sources {
+		aliases[i] = s.Alias
+	}
+	return aliases, cobra.ShellCompDirectiveNoFileComp
+}
+
 var sourceUpdateCmd = &cobra.Command{
 	Use:   "update [alias]",
 	Short: "Update remote sources",
 	Long: `Pull the latest changes from remote sources.
 
 If an alias is provided, only that source is updated. Otherwise all sources
-are updated. A diff summary shows what changed (added, removed, updated workflows).`,
-	Args: cobra.MaximumNArgs(1),
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if len(args) != 0 {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-		mgr := source.NewManager(config.SourcesDir())
-		sources := mgr.List()
-		aliases := make([]string, len(sources))
-		for i, s := range sources {
-			aliases[i] = s.Alias
-		}
-		return aliases, cobra.ShellCompDirectiveNoFileComp
-	},
+are updated in parallel, --jobs at a time, and a table lists the workflows
+added (+), removed (-) and updated (~) in each source. A source that fails
+to update is reported in the table without stopping the others.
+
+Pinned sources stay on their ref: a pinned branch moves to its latest commit,
+while a pinned tag or commit does not change.
+
+To keep sources fresh without running this command, set an interval in
+config.yaml. When wf pick, list or manage start and a git source is older
+than the interval, it is updated in the background:
+
+  sources:
+    auto_update_interval: 6h`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSourceAlias,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := config.EnsureSourcesDir(); err != nil {
 			return err
@@ -110,6 +157,10 @@ are updated. A diff summary shows what changed (added, removed, updated workflow
 		mgr := source.NewManager(config.SourcesDir())
 		ctx := cmd.Context()
 
+		if sourceAutoFlag {
+			return autoUpdateSources(cmd, mgr)
+		}
+
 		if len(args) == 1 {
 			return updateSource(ctx, mgr, args[0])
 		}
@@ -120,37 +171,188 @@ are updated. A diff summary shows what changed (added, removed, updated workflow
 			fmt.Fprintln(os.Stderr, "No remote sources configured. Use 'wf source add <git-url>' to add one.")
 			return nil
 		}
-		for _, s := range sources {
-			if err := updateSource(ctx, mgr, s.Alias); err != nil {
-				fmt.Fprintf(os.Stderr, "Error updating %q: %v\n", s.Alias, err)
-			}
+		if failed := updateAllSources(ctx, mgr, sourceJobsFlag); failed > 0 {
+			fmt.Fprintf(os.Stderr, "%d of %d sources failed to update\n", failed, len(sources))
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			return &exitCodeError{code: 1}
 		}
 		return nil
 	},
 }
 
+var (
+	sourceJobsFlag int
+	sourceAutoFlag bool
+)
+
+// autoUpdateSources is the background update started by pick, list and
+// manage. Its output goes nowhere, so it only reports errors through the
+// exit status; a source that fails is retried after the next interval.
+func autoUpdateSources(cmd *cobra.Command, mgr *source.Manager) error {
+	appCfg, err := config.LoadAppConfig()
+	if err != nil {
+		return err
+	}
+	interval, err := appCfg.Sources.AutoUpdate()
+	if err != nil || interval == 0 {
+		return err
+	}
+	if _, err := mgr.AutoUpdate(cmd.Context(), interval); err != nil && !errors.Is(err, source.ErrUpdateInProgress) {
+		return err
+	}
+	return nil
+}
+
 func updateSource(ctx context.Context, mgr *source.Manager, alias string) error {
+	for _, s := range mgr.List() {
+		if s.Alias == alias && s.Type() == source.TypePath {
+			fmt.Fprintf(os.Stderr, "Source %q: local path, read in place\n", alias)
+			return nil
+		}
+	}
 	result, err := mgr.Update(ctx, alias)
 	if err != nil {
 		return err
 	}
+	printUpdateResult(alias, result)
+	return nil
+}
 
+// printUpdateResult summarizes the workflows changed in a source.
+func printUpdateResult(alias string, result *source.UpdateResult) {
 	total := len(result.Added) + len(result.Removed) + len(result.Updated)
 	if total == 0 {
 		fmt.Fprintf(os.Stderr, "Source %q: already up to date\n", alias)
-		return nil
+		return
 	}
 
-	fmt.Fprintf(os.Stderr, "Source %q: +%d new, -%d removed, ~%d updated\n",
-		alias, len(result.Added), len(result.Removed), len(result.Updated))
-	return nil
+	fmt.Fprintf(os.Stderr, "Source %q: +%d new, -%d removed, ~%d updated\n  %s\n",
+		alias, len(result.Added), len(result.Removed), len(result.Updated), formatChanges(result))
+}
+
+// updateAllSources updates every source with a bounded worker pool, shows
+// progress on stderr and ends with a per-source table of changes. It
+// returns the number of sources that failed.
+func updateAllSources(ctx context.Context, mgr *source.Manager, jobs int) int {
+	sources := mgr.List()
+	progress := newUpdateProgress(sources, term.IsTerminal(os.Stderr.Fd()))
+	updates := mgr.UpdateAll(ctx, jobs, progress.report)
+	progress.finish()
+
+	failed := 0
+	tw := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	for i, u := range updates {
+		status, detail := "up to date", ""
+		switch {
+		case u.Err != nil:
+			failed++
+			// Keep each source on one row; git errors span several lines.
+			lines := strings.Split(strings.TrimSpace(u.Err.Error()), "\n")
+			status, detail = "failed", strings.Join(lines, "; ")
+		case sources[i].Type() == source.TypePath:
+			status = "local path"
+		case len(u.Result.Added)+len(u.Result.Removed)+len(u.Result.Updated) > 0:
+			status, detail = "updated", formatChanges(u.Result)
+		}
+		fmt.Fprintln(tw, strings.TrimRight(u.Alias+"\t"+status+"\t"+detail, "\t"))
+	}
+	tw.Flush()
+	return failed
+}
+
+// formatChanges lists changed workflows by name, prefixed with + for added,
+// - for removed and ~ for updated.
+func formatChanges(result *source.UpdateResult) string {
+	var parts []string
+	for _, name := range result.Added {
+		parts = append(parts, "+"+name)
+	}
+	for _, name := range result.Removed {
+		parts = append(parts, "-"+name)
+	}
+	for _, name := range result.Updated {
+		parts = append(parts, "~"+name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// updateProgress shows which sources are being updated. On a terminal it
+// redraws a single status line; otherwise it prints nothing, leaving the
+// final table as the only output.
+type updateProgress struct {
+	live    bool
+	total   int
+	done    int
+	running []string
+}
+
+func newUpdateProgress(sources []source.Source, live bool) *updateProgress {
+	return &updateProgress{live: live, total: len(sources)}
+}
+
+// report is passed to Manager.UpdateAll, which serializes the calls.
+func (p *updateProgress) report(u source.SourceUpdate, done bool) {
+	if done {
+		p.done++
+		p.running = slices.DeleteFunc(p.running, func(a string) bool { return a == u.Alias })
+	} else {
+		p.running = append(p.running, u.Alias)
+	}
+	if !p.live {
+		return
+	}
+	line := fmt.Sprintf("Updating sources: %d/%d done", p.done, p.total)
+	if len(p.running) > 0 {
+		line += " (" + strings.Join(p.running, ", ") + ")"
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+}
+
+// finish clears the status line.
+func (p *updateProgress) finish() {
+	if p.live {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
+
+var sourcePinCmd = &cobra.Command{
+	Use:   "pin <alias> <ref>",
+	Short: "Pin a remote source to a branch, tag or commit",
+	Long: `Check out a branch, tag or commit in a remote source and keep it there.
+
+Pinning lets a team roll out workflow changes deliberately: move the pin to
+a new tag when everyone should get the new workflows. 'wf source update'
+keeps pinned sources on their ref.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSourceAlias,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.EnsureSourcesDir(); err != nil {
+			return err
+		}
+		mgr := source.NewManager(config.SourcesDir())
+		alias, ref := args[0], args[1]
+		result, err := mgr.Pin(cmd.Context(), alias, ref)
+		if err != nil {
+			return err
+		}
+		commit, err := mgr.Commit(cmd.Context(), alias)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Pinned source %q to %s (%s)\n", alias, ref, commit)
+		printUpdateResult(alias, result)
+		return nil
+	},
 }
 
 var sourceListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List configured remote sources",
-	Long:  `Display all configured remote workflow sources with their alias, URL, and last update time.`,
-	Args:  cobra.NoArgs,
+	Long: `Display all configured workflow sources with their alias and type. Git
+sources show their URL, pinned ref, current commit, last update time, and
+whether they are writable; local path sources show their path.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := config.EnsureSourcesDir(); err != nil {
 			return err
@@ -164,8 +366,71 @@ var sourceListCmd = &cobra.Command{
 		}
 
 		for _, s := range sources {
+			if s.Type() == source.TypePath {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-16s  %-4s  %s\n", s.Alias, s.Type(), s.Path)
+				continue
+			}
 			updated := formatRelativeTime(s.UpdatedAt)
-			fmt.Fprintf(cmd.OutOrStdout(), "%-16s  %s  (%s)\n", s.Alias, s.URL, updated)
+			commit, err := mgr.Commit(cmd.Context(), s.Alias)
+			if err != nil {
+				commit = "unknown"
+			}
+			if s.Ref != "" {
+				commit = s.Ref + " @ " + commit
+			}
+			writable := ""
+			switch {
+			case s.Writable && s.PushBranch != "":
+				writable = "  writable, pushes to " + s.PushBranch
+			case s.Writable:
+				writable = "  writable"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%-16s  %-4s  %s  %s  (%s)%s\n", s.Alias, s.Type(), s.URL, commit, updated, writable)
+		}
+		return nil
+	},
+}
+
+var (
+	sourceWritableOff    bool
+	sourcePushBranchFlag string
+)
+
+var sourceWritableCmd = &cobra.Command{
+	Use:   "writable <alias>",
+	Short: "Allow saving workflows to a git source",
+	Long: `Let wf write to a git source so you can contribute to the team repository.
+
+Once a source is writable, saving one of its workflows (e.g. "team/deploy")
+from 'wf edit', 'wf rm' or the manage form writes the file into the clone,
+commits it with a generated message and pushes the commit. Commits go to the
+branch the source follows, or to --push-branch, for example a branch you open
+a pull request from; a pinned source needs --push-branch. If the push fails,
+the git command to push the commit by hand is printed. Either way the source
+keeps following its branch, so 'wf source update' is not blocked.
+
+Use --off to make the source read-only again.`,
+	Example: `  wf source writable team
+  wf source writable team --push-branch wf/updates
+  wf source writable team --off`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSourceAlias,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.EnsureSourcesDir(); err != nil {
+			return err
+		}
+		mgr := source.NewManager(config.SourcesDir())
+		alias := args[0]
+		if err := mgr.SetWritable(alias, !sourceWritableOff, sourcePushBranchFlag); err != nil {
+			return err
+		}
+		switch {
+		case sourceWritableOff:
+			fmt.Fprintf(os.Stderr, "Source %q is read-only\n", alias)
+		case sourcePushBranchFlag != "":
+			fmt.Fprintf(os.Stderr, "Source %q is writable; changes are pushed to %s\n", alias, sourcePushBranchFlag)
+		default:
+			fmt.Fprintf(os.Stderr, "Source %q is writable; changes are pushed to its current branch\n", alias)
 		}
 		return nil
 	},
@@ -206,5 +471,14 @@ func formatRelativeTime(t time.Time) string {
 
 func init() {
 	sourceAddCmd.Flags().StringVar(&sourceNameFlag, "name", "", "custom alias for the source")
-	sourceCmd.AddCommand(sourceAddCmd, sourceRemoveCmd, sourceUpdateCmd, sourceListCmd)
+	sourceAddCmd.Flags().StringVar(&sourceRefFlag, "ref", "", "branch, tag or commit to pin the source to")
+	sourceAddCmd.Flags().StringVar(&sourcePathFlag, "path", "", "local directory or workflow file to read in place")
+	sourceUpdateCmd.Flags().IntVarP(&sourceJobsFlag, "jobs", "j", source.DefaultUpdateWorkers, "number of sources to update at once")
+	sourceUpdateCmd.Flags().BoolVar(&sourceAutoFlag, "auto", false, "update sources older than sources.auto_update_interval (used by the background refresh)")
+	if err := sourceUpdateCmd.Flags().MarkHidden("auto"); err != nil {
+		panic(fmt.Errorf("hide source update auto flag: %w", err))
+	}
+	sourceWritableCmd.Flags().BoolVar(&sourceWritableOff, "off", false, "make the source read-only again")
+	sourceWritableCmd.Flags().StringVar(&sourcePushBranchFlag, "push-branch", "", "branch to push changes to (default: the branch the source follows)")
+	sourceCmd.AddCommand(sourceAddCmd, sourceRemoveCmd, sourceUpdateCmd, sourcePinCmd, sourceWritableCmd, sourceListCmd)
 }
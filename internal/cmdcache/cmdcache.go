@@ -0,0 +1,111 @@
+// Package cmdcache keeps the output of dynamic and list param commands on
+// disk, so slow listings such as `aws` or `kubectl` queries can be shown
+// immediately and refreshed in the background.
+package cmdcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Entry is the cached output of one command run from one directory. The
+// command and directory are only kept as a hash, since a rendered command
+// may contain values that should not be written to disk.
+type Entry struct {
+	Key   string    `json:"key"`
+	Lines []string  `json:"lines"`
+	Time  time.Time `json:"time"`
+}
+
+// Expired reports whether the entry is older than ttl at now.
+func (e Entry) Expired(ttl time.Duration, now time.Time) bool {
+	return now.Sub(e.Time) > ttl
+}
+
+// Cache stores one file per command and working directory. A nil *Cache
+// never hits and ignores writes.
+type Cache struct {
+	dir string
+}
+
+// New returns a Cache that stores entries in dir.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Get returns the cached output of command run from dir. Missing and
+// unreadable entries are reported as misses.
+func (c *Cache) Get(command, dir string) (Entry, bool) {
+	if c == nil {
+		return Entry{}, false
+	}
+	key := entryKey(command, dir)
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return Entry{}, false
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Put stores lines as the output of command run from dir at now. The file
+// is replaced atomically so a concurrent reader never sees a partial write.
+func (c *Cache) Put(command, dir string, lines []string, now time.Time) error {
+	if c == nil {
+		return nil
+	}
+	key := entryKey(command, dir)
+	data, err := json.Marshal(Entry{Key: key, Lines: lines, Time: now})
+	if err != nil {
+		return fmt.Errorf("encoding command cache: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("creating command cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, ".entry-*.json")
+	if err != nil {
+		return fmt.Errorf("writing command cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing command cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing command cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("writing command cache: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cached entry.
+func (c *Cache) Clear() error {
+	if c == nil {
+		return nil
+	}
+	if err := os.RemoveAll(c.dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("clearing command cache: %w", err)
+	}
+	return nil
+}
+
+// entryKey identifies command run from dir without revealing either.
+func entryKey(command, dir string) string {
+	sum := sha256.Sum256([]byte(dir + "\x00" + command))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:32]+".json")
+}
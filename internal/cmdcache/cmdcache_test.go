@@ -0,0 +1,64 @@
+package cmdcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutGetKeyedByCommandAndDir(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "commands"))
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	_, ok := c.Get("kubectl get ns", "/work/a")
+	assert.False(t, ok)
+
+	require.NoError(t, c.Put("kubectl get ns", "/work/a", []string{"default", "prod"}, now))
+
+	e, ok := c.Get("kubectl get ns", "/work/a")
+	require.True(t, ok)
+	assert.Equal(t, []string{"default", "prod"}, e.Lines)
+	assert.True(t, e.Time.Equal(now))
+
+	_, ok = c.Get("kubectl get ns", "/work/b")
+	assert.False(t, ok, "a different working directory is a different entry")
+	_, ok = c.Get("kubectl get pods", "/work/a")
+	assert.False(t, ok)
+
+	require.NoError(t, c.Clear())
+	_, ok = c.Get("kubectl get ns", "/work/a")
+	assert.False(t, ok)
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	e := Entry{Time: now.Add(-10 * time.Minute)}
+	assert.False(t, e.Expired(time.Hour, now))
+	assert.True(t, e.Expired(5*time.Minute, now))
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	_, ok := c.Get("x", "")
+	assert.False(t, ok)
+	assert.NoError(t, c.Put("x", "", []string{"y"}, time.Now()))
+	assert.NoError(t, c.Clear())
+}
+
+func TestPutDoesNotStoreCommand(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "commands")
+	c := New(dir)
+	require.NoError(t, c.Put("vault read --token s3cret-token db", "/work", []string{"x"}, time.Now()))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret-token")
+	assert.NotContains(t, string(data), "/work")
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/goccy/go-yaml"
@@ -22,9 +25,65 @@ type AISettings struct {
 	Timeout int           `yaml:"timeout,omitempty"` // Seconds per AI request, default 30
 }
 
+// ParamCommandSettings holds the defaults for running dynamic and list param
+// commands. Workflow args override each field.
+type ParamCommandSettings struct {
+	Shell   string            `yaml:"shell,omitempty"`   // Interpreter, default "sh"
+	Timeout string            `yaml:"timeout,omitempty"` // Duration per command such as "10s", default 5s
+	Workdir string            `yaml:"workdir,omitempty"` // Directory commands run in, default the current directory
+	Env     map[string]string `yaml:"env,omitempty"`     // Extra environment variables
+}
+
+// CommandTimeout parses Timeout. Zero means no timeout is configured.
+func (s ParamCommandSettings) CommandTimeout() (time.Duration, error) {
+	if strings.TrimSpace(s.Timeout) == "" {
+		return 0, nil
+	}
+	d, err := ParseTimeout(s.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("param_commands.timeout: %w", err)
+	}
+	return d, nil
+}
+
+// ParseTimeout parses a param command timeout. param_commands.timeout and
+// the timeout of a workflow arg share this format.
+func ParseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q (want a duration such as 20s or 2m)", s)
+	}
+	return d, nil
+}
+
+// SourceSettings holds configuration for remote workflow sources.
+type SourceSettings struct {
+	// AutoUpdateInterval is how old a git source may get before wf pick,
+	// list and manage refresh it in the background, as a duration such as
+	// "6h" or "30m". Empty or "0" turns automatic updates off.
+	AutoUpdateInterval string `yaml:"auto_update_interval,omitempty"`
+}
+
+// AutoUpdate parses AutoUpdateInterval. Zero means automatic updates are off.
+func (s SourceSettings) AutoUpdate() (time.Duration, error) {
+	if s.AutoUpdateInterval == "" || s.AutoUpdateInterval == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s.AutoUpdateInterval)
+	if err != nil {
+		return 0, fmt.Errorf("sources.auto_update_interval: %w", err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("sources.auto_update_interval: must not be negative")
+	}
+	return d, nil
+}
+
 // AppConfig is the top-level application configuration read from config.yaml.
 type AppConfig struct {
-	AI AISettings `yaml:"ai,omitempty"`
+	AI            AISettings           `yaml:"ai,omitempty"`
+	ParamCommands ParamCommandSettings `yaml:"param_commands,omitempty"`
+	Sources       SourceSettings       `yaml:"sources,omitempty"`
 }
 
 // ConfigPath returns the path to the config.yaml file.
@@ -51,6 +110,9 @@ func LoadAppConfig() (*AppConfig, error) {
 	if cfg.AI.Timeout <= 0 {
 		cfg.AI.Timeout = 30
 	}
+	if cfg.ParamCommands.Timeout == "" {
+		cfg.ParamCommands.Timeout = "5s"
+	}
 	return &cfg, nil
 }
 
@@ -60,6 +122,9 @@ func defaultAppConfig() *AppConfig {
 		AI: AISettings{
 			Timeout: 30,
 		},
+		ParamCommands: ParamCommandSettings{
+			Timeout: "5s",
+		},
 	}
 }
 
@@ -74,7 +139,69 @@ func WorkflowsDir() string {
 // Uses XDG data home (~/.local/share/wf/sources/) to keep cloned repos
 // separate from configuration.
 func SourcesDir() string {
-	return filepath.Join(xdg.DataHome, "wf", "sources")
+	return filepath.Join(DataDir(), "sources")
+}
+
+// DataDir returns the root data directory for wf.
+// Uses XDG data home (~/.local/share/wf/) for state that is not configuration.
+func DataDir() string {
+	return filepath.Join(xdg.DataHome, "wf")
+}
+
+// RunLogPath returns the path to the execution history log.
+func RunLogPath() string {
+	return filepath.Join(DataDir(), "history.jsonl")
+}
+
+// UsagePath returns the path to the workflow usage state file used for
+// frecency ranking.
+func UsagePath() string {
+	return filepath.Join(DataDir(), "usage.json")
+}
+
+// CacheDir returns the root cache directory for wf.
+// Uses XDG cache home (~/.cache/wf/) for data that can be rebuilt at any time.
+func CacheDir() string {
+	return filepath.Join(xdg.CacheHome, "wf")
+}
+
+// CommandCacheDir returns the directory holding cached output of dynamic and
+// list param commands.
+func CommandCacheDir() string {
+	return filepath.Join(CacheDir(), "commands")
+}
+
+// ProjectDirName is the directory that holds a project's own workflows.
+const ProjectDirName = ".wf"
+
+// FindProjectDir walks up from start looking for a .wf directory, the way
+// git finds .git, and returns its path, or "" when there is none.
+func FindProjectDir(start string) string {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, ProjectDirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ProjectDir returns the .wf directory of the project containing the
+// current directory, or "" outside a project.
+func ProjectDir() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return FindProjectDir(cwd)
 }
 
 // EnsureSourcesDir creates the sources directory if it doesn't exist.
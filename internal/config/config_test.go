@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProjectDir(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "repo", ProjectDirName)
+	nested := filepath.Join(root, "repo", "services", "api")
+	require.NoError(t, os.MkdirAll(project, 0755))
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	assert.Equal(t, project, FindProjectDir(nested))
+	assert.Equal(t, project, FindProjectDir(filepath.Join(root, "repo")))
+	assert.Empty(t, FindProjectDir(root))
+}
+
+func TestFindProjectDir_IgnoresFiles(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ProjectDirName), nil, 0644))
+
+	assert.Empty(t, FindProjectDir(root))
+}
+
+func TestSourceSettings_AutoUpdate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"6h", 6 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"daily", 0, true},
+		{"-1h", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := SourceSettings{AutoUpdateInterval: tt.value}.AutoUpdate()
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+			continue
+		}
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+}
+
+func TestParamCommandSettings_CommandTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"10s", 10 * time.Second, false},
+		{"1m30s", 90 * time.Second, false},
+		{"5", 0, true}, // seconds need a unit, as in a workflow arg timeout
+		{"soon", 0, true},
+		{"-5s", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParamCommandSettings{Timeout: tt.value}.CommandTimeout()
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+			continue
+		}
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+}
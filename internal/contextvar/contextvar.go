@@ -0,0 +1,206 @@
+// Package contextvar resolves the built-in {{@...}} variables, such as
+// {{@git.branch}} or {{@date:2006-01-02}}, that describe where and when a
+// workflow runs.
+package contextvar
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+// Prefix marks a placeholder name as a context variable.
+const Prefix = "@"
+
+// cacheTTL bounds how long a looked-up value is reused. Previews re-render
+// on every keystroke, so git and the clipboard are not queried each time,
+// but a long-running TUI still notices a branch switch.
+const cacheTTL = 5 * time.Second
+
+// gitTimeout bounds each git query so a slow repository cannot stall a
+// render.
+const gitTimeout = 2 * time.Second
+
+type lookup func(arg string) (string, bool)
+
+var vars = map[string]lookup{
+	"cwd":        cachedVar("cwd", getwd),
+	"dir":        cachedVar("dir", dirName),
+	"home":       func(string) (string, bool) { return nonEmpty(os.UserHomeDir()) },
+	"user":       cachedVar("user", userName),
+	"hostname":   func(string) (string, bool) { return nonEmpty(os.Hostname()) },
+	"date":       func(arg string) (string, bool) { return formatNow(arg, "2006-01-02") },
+	"time":       func(arg string) (string, bool) { return formatNow(arg, "15:04:05") },
+	"timestamp":  func(string) (string, bool) { return strconv.FormatInt(now().Unix(), 10), true },
+	"clipboard":  cachedVar("clipboard", readClipboard),
+	"git.branch": cachedVar("git.branch", gitOutput("rev-parse", "--abbrev-ref", "HEAD")),
+	"git.root":   cachedVar("git.root", gitRoot),
+	"git.repo":   cachedVar("git.repo", gitRepo),
+	"git.sha":    cachedVar("git.sha", gitOutput("rev-parse", "--short", "HEAD")),
+}
+
+var gitRoot = gitOutput("rev-parse", "--show-toplevel")
+
+// Names returns the supported variable names without the @ prefix, plus
+// the env.NAME form.
+func Names() []string {
+	names := make([]string, 0, len(vars)+1)
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append(names, "env.NAME")
+}
+
+// IsContext reports whether a placeholder name refers to a context variable.
+func IsContext(name string) bool {
+	return strings.HasPrefix(name, Prefix)
+}
+
+// Known reports whether name (with its @ prefix) is a supported variable.
+func Known(name string) bool {
+	name = strings.TrimPrefix(name, Prefix)
+	if env, ok := strings.CutPrefix(name, "env."); ok {
+		return env != ""
+	}
+	_, ok := vars[name]
+	return ok
+}
+
+// Resolve returns the value of context variable name, given with its @
+// prefix. arg is the text after a colon in the placeholder: a time layout
+// for @date and @time, otherwise ignored. ok is false for unknown names and
+// for values that cannot be determined, such as @git.branch outside a
+// repository.
+func Resolve(name, arg string) (value string, ok bool) {
+	name = strings.TrimPrefix(name, Prefix)
+	if env, found := strings.CutPrefix(name, "env."); found {
+		return os.LookupEnv(env)
+	}
+	if fn, found := vars[name]; found {
+		return fn(arg)
+	}
+	return "", false
+}
+
+// TakesArg reports whether the text after a colon is an argument to the
+// variable rather than a fallback value.
+func TakesArg(name string) bool {
+	name = strings.TrimPrefix(name, Prefix)
+	return name == "date" || name == "time"
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+func formatNow(layout, fallback string) (string, bool) {
+	if layout == "" {
+		layout = fallback
+	}
+	return now().Format(layout), true
+}
+
+type cacheEntry struct {
+	value string
+	ok    bool
+	at    time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]cacheEntry{}
+)
+
+// cached returns fn's result, reusing it for cacheTTL.
+func cached(key string, fn func() (string, bool)) (string, bool) {
+	cacheMu.Lock()
+	e, found := cache[key]
+	cacheMu.Unlock()
+	if found && now().Sub(e.at) < cacheTTL {
+		return e.value, e.ok
+	}
+	v, ok := fn()
+	cacheMu.Lock()
+	cache[key] = cacheEntry{value: v, ok: ok, at: now()}
+	cacheMu.Unlock()
+	return v, ok
+}
+
+// cachedVar wraps a lookup that takes no argument in cached.
+func cachedVar(key string, fn func() (string, bool)) lookup {
+	return func(string) (string, bool) { return cached(key, fn) }
+}
+
+// ResetCache forgets cached values, e.g. after changing directory.
+func ResetCache() {
+	cacheMu.Lock()
+	cache = map[string]cacheEntry{}
+	cacheMu.Unlock()
+}
+
+func nonEmpty(v string, err error) (string, bool) {
+	return v, err == nil && v != ""
+}
+
+func getwd() (string, bool) {
+	return nonEmpty(os.Getwd())
+}
+
+func dirName() (string, bool) {
+	wd, ok := getwd()
+	if !ok {
+		return "", false
+	}
+	return filepath.Base(wd), true
+}
+
+func userName() (string, bool) {
+	if name := os.Getenv("USER"); name != "" {
+		return name, true
+	}
+	if name := os.Getenv("USERNAME"); name != "" {
+		return name, true
+	}
+	u, err := user.Current()
+	if err != nil {
+		return "", false
+	}
+	return nonEmpty(u.Username, nil)
+}
+
+func readClipboard() (string, bool) {
+	v, err := clipboard.ReadAll()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimRight(v, "\r\n"), true
+}
+
+func gitOutput(args ...string) func() (string, bool) {
+	return func() (string, bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "git", args...).Output()
+		if err != nil {
+			return "", false
+		}
+		return nonEmpty(strings.TrimSpace(string(out)), nil)
+	}
+}
+
+func gitRepo() (string, bool) {
+	root, ok := cached("git.root", gitRoot)
+	if !ok {
+		return "", false
+	}
+	return filepath.Base(root), true
+}
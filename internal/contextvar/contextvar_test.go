@@ -0,0 +1,94 @@
+package contextvar
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve_DateAndTimeUseLayout(t *testing.T) {
+	fixed := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = time.Now })
+
+	v, ok := Resolve("@date", "")
+	require.True(t, ok)
+	assert.Equal(t, "2026-03-04", v)
+
+	v, _ = Resolve("@date", "Jan 2")
+	assert.Equal(t, "Mar 4", v)
+
+	v, _ = Resolve("@time", "")
+	assert.Equal(t, "05:06:07", v)
+
+	v, _ = Resolve("@timestamp", "")
+	assert.Equal(t, "1772600767", v)
+}
+
+func TestResolve_EnvAndUser(t *testing.T) {
+	t.Setenv("WF_CONTEXT_TEST", "hello")
+	t.Setenv("USER", "ada")
+	ResetCache()
+
+	v, ok := Resolve("@env.WF_CONTEXT_TEST", "")
+	require.True(t, ok)
+	assert.Equal(t, "hello", v)
+
+	_, ok = Resolve("@env.WF_CONTEXT_TEST_UNSET", "")
+	assert.False(t, ok)
+
+	v, ok = Resolve("@user", "")
+	require.True(t, ok)
+	assert.Equal(t, "ada", v)
+}
+
+func TestResolve_CwdAndGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("init", "-q", "-b", "feature/ctx")
+	run("-c", "user.email=t@example.com", "-c", "user.name=t", "commit", "-q", "--allow-empty", "-m", "init")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	ResetCache()
+
+	v, ok := Resolve("@dir", "")
+	require.True(t, ok)
+	assert.Equal(t, filepath.Base(dir), v)
+
+	v, ok = Resolve("@git.branch", "")
+	require.True(t, ok)
+	assert.Equal(t, "feature/ctx", v)
+
+	v, ok = Resolve("@git.repo", "")
+	require.True(t, ok)
+	assert.Equal(t, filepath.Base(dir), v)
+
+	v, ok = Resolve("@git.sha", "")
+	require.True(t, ok)
+	assert.NotEmpty(t, v)
+}
+
+func TestKnown(t *testing.T) {
+	assert.True(t, Known("@git.branch"))
+	assert.True(t, Known("@env.HOME"))
+	assert.False(t, Known("@env."))
+	assert.False(t, Known("@nope"))
+	_, ok := Resolve("@nope", "")
+	assert.False(t, ok)
+}
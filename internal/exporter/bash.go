@@ -0,0 +1,338 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/contextvar"
+	"github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// BashExporter writes wf Workflows as bash functions in one file that can
+// be sourced, so the workflows run without wf installed. Params become
+// positional arguments in fill order.
+type BashExporter struct{}
+
+// truthyHelper mirrors wf's truthiness for step when: conditions. It is
+// written once, ahead of the functions, when any condition needs it.
+const truthyHelper = `__wf_truthy() {
+	case "${1,,}" in
+		'' | false | 0 | no | off) return 1 ;;
+	esac
+}
+`
+
+// Export writes one function per workflow.
+func (e *BashExporter) Export(writer io.Writer, workflows []store.Workflow) (*ExportResult, error) {
+	result := &ExportResult{}
+	var body strings.Builder
+	usedNames := make(map[string]bool)
+	needsTruthy := false
+
+	for _, wf := range workflows {
+		w := newWarner("bash function", wf.Name)
+		f := &bashFunc{wf: wf, w: w}
+		f.name = uniqueName(funcNameRe.ReplaceAllString(wf.Name, "_"), usedNames)
+		f.write(&body)
+		needsTruthy = needsTruthy || f.usesTruthy
+		result.Warnings = append(result.Warnings, w.warnings...)
+		result.Exported++
+	}
+
+	var out strings.Builder
+	out.WriteString("# Workflows exported by wf. Source this file to define one bash\n# function per workflow.\n\n")
+	if needsTruthy {
+		out.WriteString(truthyHelper + "\n")
+	}
+	out.WriteString(body.String())
+	if _, err := io.WriteString(writer, out.String()); err != nil {
+		return nil, fmt.Errorf("writing bash functions: %w", err)
+	}
+	return result, nil
+}
+
+var (
+	funcNameRe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+	varNameRe  = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// uniqueName returns name, or name with a numeric suffix when it is taken.
+func uniqueName(name string, used map[string]bool) string {
+	candidate := name
+	for n := 2; used[candidate]; n++ {
+		candidate = name + "_" + strconv.Itoa(n)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// bashFunc builds the function for one workflow.
+type bashFunc struct {
+	wf         store.Workflow
+	w          *warner
+	name       string
+	params     []template.Param
+	vars       map[string]string // param name -> shell variable
+	raw        map[string]bool   // params inserted without quoting
+	usesTruthy bool
+}
+
+func (f *bashFunc) write(b *strings.Builder) {
+	f.params = params.ForWorkflow(f.wf)
+	f.vars = make(map[string]string, len(f.params))
+	f.raw = make(map[string]bool)
+	usedVars := map[string]bool{"_wf_reply": true}
+	for _, p := range f.params {
+		v := varNameRe.ReplaceAllString(p.Name, "_")
+		if v == "" || (v[0] >= '0' && v[0] <= '9') {
+			v = "_" + v
+		}
+		f.vars[p.Name] = uniqueName(v, usedVars)
+		f.raw[p.Name] = p.ListMulti
+	}
+	for _, arg := range f.wf.Args {
+		if arg.Raw {
+			f.raw[arg.Name] = true
+		}
+	}
+
+	usage := f.usage()
+	b.WriteString("# " + f.wf.Name)
+	if f.wf.Description != "" {
+		b.WriteString(": " + singleLine(f.wf.Description))
+	}
+	b.WriteString("\n# Usage: " + usage + "\n")
+	b.WriteString(f.name + "() {\n")
+	for i, p := range f.params {
+		f.writeParam(b, i+1, p, usage)
+	}
+	if f.wf.IsMultiStep() {
+		f.writeSteps(b)
+	} else {
+		writeIndented(b, f.translate(f.wf.Command), "")
+	}
+	b.WriteString("}\n\n")
+}
+
+// usage describes the positional arguments, with optional ones bracketed.
+func (f *bashFunc) usage() string {
+	parts := []string{f.name}
+	for _, p := range f.params {
+		arg := strings.ToUpper(f.vars[p.Name])
+		if f.fallback(p) != "" {
+			arg = "[" + arg + "]"
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// fallback returns the shell text used when an argument is not passed, or
+// "" when the argument is required.
+func (f *bashFunc) fallback(p template.Param) string {
+	switch {
+	case p.Default != "":
+		return doubleQuoteEscape(p.Default)
+	case p.Type == template.ParamSecret && p.SecretEnv != "":
+		return "${" + p.SecretEnv + "}"
+	case p.Type == template.ParamSecret && p.SecretCmd != "":
+		return "$(" + p.SecretCmd + ")"
+	}
+	return ""
+}
+
+func (f *bashFunc) writeParam(b *strings.Builder, pos int, p template.Param, usage string) {
+	v := f.vars[p.Name]
+	if def := f.fallback(p); def != "" {
+		fmt.Fprintf(b, "\tlocal %s=\"${%d:-%s}\"\n", v, pos, def)
+	} else {
+		fmt.Fprintf(b, "\tlocal %s=\"${%d:?usage: %s}\"\n", v, pos, doubleQuoteEscape(usage))
+	}
+
+	switch p.Type {
+	case template.ParamEnum:
+		quoted := make([]string, len(p.Options))
+		for i, o := range p.Options {
+			quoted[i] = singleQuote(o)
+		}
+		fmt.Fprintf(b, "\tcase \"$%s\" in\n\t\t%s) ;;\n", v, strings.Join(quoted, " | "))
+		fmt.Fprintf(b, "\t\t*) echo %s >&2; return 2 ;;\n\tesac\n",
+			singleQuote(fmt.Sprintf("%s: %s must be one of: %s", f.name, p.Name, strings.Join(p.Options, " "))))
+	case template.ParamDynamic:
+		f.w.add("arg %q: dynamic command not run; pass the value as argument %d", p.Name, pos)
+	case template.ParamList:
+		f.w.add("arg %q: list command not run; pass the value as argument %d", p.Name, pos)
+	}
+	if p.ListMulti {
+		f.w.add("arg %q: multi-select join settings dropped; the argument is inserted unquoted", p.Name)
+	}
+	if p.Validation != nil {
+		f.w.add("arg %q: validate rules dropped", p.Name)
+	}
+}
+
+func (f *bashFunc) writeSteps(b *strings.Builder) {
+	last := len(f.wf.Steps) - 1
+	if hasConfirm(f.wf.Steps) {
+		b.WriteString("\tlocal _wf_reply\n")
+	}
+	for i, step := range f.wf.Steps {
+		var conds []string
+		if step.When != "" {
+			conds = append(conds, f.condition(step.When))
+		}
+		if step.Confirm {
+			label := fmt.Sprintf("Run step %d", i+1)
+			if step.Name != "" {
+				label += " (" + step.Name + ")"
+			}
+			conds = append(conds, fmt.Sprintf("{ read -r -p %s _wf_reply && [[ $_wf_reply == [yY]* ]]; }", singleQuote(label+"? [y/N] ")))
+		}
+		suffix := ""
+		if i < last && !step.ContinueOnError {
+			suffix = " || return"
+		}
+
+		indent := ""
+		if len(conds) > 0 {
+			fmt.Fprintf(b, "\tif %s; then\n", strings.Join(conds, " && "))
+			indent = "\t"
+		}
+		command := f.translate(step.Command)
+		if strings.Contains(command, "\n") {
+			b.WriteString("\t" + indent + "{\n")
+			writeIndented(b, command, indent+"\t")
+			b.WriteString("\t" + indent + "}" + suffix + "\n")
+		} else {
+			b.WriteString("\t" + indent + command + suffix + "\n")
+		}
+		if len(conds) > 0 {
+			b.WriteString("\tfi\n")
+		}
+	}
+}
+
+// translate turns a command template into bash, expanding params from the
+// function's locals.
+func (f *bashFunc) translate(command string) string {
+	return template.Rewrite(command, func(ph template.Placeholder) string {
+		if contextvar.IsContext(ph.Param.Name) {
+			return contextPlaceholder(ph, f.w)
+		}
+		v, ok := f.vars[ph.Param.Name]
+		if !ok {
+			return ph.Text
+		}
+		expr := "${" + v + "}"
+		if len(ph.Transforms) > 0 {
+			if t, ok := bashTransform(v, ph.Transforms); ok {
+				expr = t
+			} else {
+				f.w.add("%s: transforms dropped", ph.Text)
+			}
+		}
+		if f.raw[ph.Param.Name] && ph.Quote == 0 {
+			return expr
+		}
+		return inQuoteContext(expr, ph.Quote)
+	})
+}
+
+// bashTransform expresses a single transform with bash parameter
+// expansion. Chains and transforms bash has no short form for report false.
+func bashTransform(v string, ts []template.Transform) (string, bool) {
+	if len(ts) != 1 {
+		return "", false
+	}
+	t := ts[0]
+	switch t.Name {
+	case "upper":
+		return "${" + v + "^^}", true
+	case "lower":
+		return "${" + v + ",,}", true
+	case "default":
+		return "${" + v + ":-" + doubleQuoteEscape(t.Args[0]) + "}", true
+	case "trimprefix":
+		return "${" + v + "#\"" + doubleQuoteEscape(t.Args[0]) + "\"}", true
+	case "trimsuffix":
+		return "${" + v + "%\"" + doubleQuoteEscape(t.Args[0]) + "\"}", true
+	case "basename":
+		return "$(basename -- \"${" + v + "}\")", true
+	}
+	return "", false
+}
+
+// condition translates a step's when: expression into a bash test list.
+// Each || alternative is grouped so && binds tighter, as in wf.
+func (f *bashFunc) condition(cond string) string {
+	alts := strings.Split(cond, "||")
+	groups := make([]string, len(alts))
+	for i, alt := range alts {
+		terms := strings.Split(alt, "&&")
+		tests := make([]string, len(terms))
+		for j, term := range terms {
+			tests[j] = f.conditionTerm(strings.TrimSpace(term))
+		}
+		groups[i] = strings.Join(tests, " && ")
+	}
+	if len(groups) == 1 {
+		return groups[0]
+	}
+	for i, g := range groups {
+		groups[i] = "{ " + g + "; }"
+	}
+	return strings.Join(groups, " || ")
+}
+
+func (f *bashFunc) conditionTerm(term string) string {
+	for _, op := range []string{"==", "!="} {
+		if idx := strings.Index(term, op); idx >= 0 {
+			name := strings.TrimSpace(term[:idx])
+			want := unquote(strings.TrimSpace(term[idx+len(op):]))
+			test := "="
+			if op == "!=" {
+				test = "!="
+			}
+			return fmt.Sprintf("[ \"$%s\" %s %s ]", f.vars[name], test, singleQuote(want))
+		}
+	}
+	f.usesTruthy = true
+	if name, negated := strings.CutPrefix(term, "!"); negated {
+		return fmt.Sprintf("! __wf_truthy \"$%s\"", f.vars[strings.TrimSpace(name)])
+	}
+	return fmt.Sprintf("__wf_truthy \"$%s\"", f.vars[term])
+}
+
+func hasConfirm(steps []store.Step) bool {
+	for _, s := range steps {
+		if s.Confirm {
+			return true
+		}
+	}
+	return false
+}
+
+// writeIndented writes each line of command indented by a tab plus extra.
+func writeIndented(b *strings.Builder, command, extra string) {
+	for _, line := range strings.Split(strings.TrimRight(command, "\n"), "\n") {
+		b.WriteString("\t" + extra + line + "\n")
+	}
+}
+
+// unquote strips one pair of matching quotes, as wf does for condition
+// values.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
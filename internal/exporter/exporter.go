@@ -0,0 +1,299 @@
+// Package exporter writes wf workflows in the formats internal/importer
+// reads, plus a JSON bundle and standalone bash functions, so workflows
+// can be shared with people using other tools.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/contextvar"
+	"github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// ExportResult contains the outcome of exporting workflows to an external format.
+type ExportResult struct {
+	Exported int      // Workflows written
+	Warnings []string // Features the format cannot represent, per workflow
+}
+
+// Exporter converts wf Workflows into an external format.
+type Exporter interface {
+	Export(writer io.Writer, workflows []store.Workflow) (*ExportResult, error)
+}
+
+// Filter selects the workflows to export. Empty fields match everything;
+// a workflow must match every field that is set.
+type Filter struct {
+	Folder string   // Only workflows in this folder or below, e.g. "infra"
+	Tags   []string // Only workflows with at least one of these tags
+	Names  []string // Only these workflows: full names, base names, or glob patterns such as "deploy-*"
+}
+
+// Match reports whether wf passes the filter.
+func (f Filter) Match(wf store.Workflow) bool {
+	if folder := strings.Trim(f.Folder, "/"); folder != "" && !strings.HasPrefix(wf.Name, folder+"/") {
+		return false
+	}
+	if len(f.Tags) > 0 && !hasAnyTag(wf.Tags, f.Tags) {
+		return false
+	}
+	if len(f.Names) > 0 && !matchesName(wf.Name, f.Names) {
+		return false
+	}
+	return true
+}
+
+// Select returns the workflows that pass the filter, in their original order.
+func Select(workflows []store.Workflow, f Filter) []store.Workflow {
+	var out []store.Workflow
+	for _, wf := range workflows {
+		if f.Match(wf) {
+			out = append(out, wf)
+		}
+	}
+	return out
+}
+
+func hasAnyTag(tags, want []string) bool {
+	for _, t := range tags {
+		for _, w := range want {
+			if strings.EqualFold(t, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesName(name string, patterns []string) bool {
+	base := path.Base(name)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// warner collects fidelity warnings for one workflow in the importer's
+// `<format> <kind> "<name>": <detail>` form.
+type warner struct {
+	prefix   string
+	warnings []string
+}
+
+func newWarner(kind, name string) *warner {
+	return &warner{prefix: fmt.Sprintf("%s %q: ", kind, name)}
+}
+
+func (w *warner) add(format string, a ...any) {
+	msg := w.prefix + fmt.Sprintf(format, a...)
+	for _, existing := range w.warnings {
+		if existing == msg {
+			return
+		}
+	}
+	w.warnings = append(w.warnings, msg)
+}
+
+// singleCommand returns the workflow as one command. Steps are joined with
+// && so a failure still stops the rest, with multi-line steps grouped so
+// the whole step gates the next and continue_on_error steps grouped with
+// || true. A step gated by when cannot be expressed, so ok is false and the
+// workflow must be skipped rather than exported with the step always
+// running; other settings that are lost are reported.
+func singleCommand(wf store.Workflow, w *warner) (command string, ok bool) {
+	if !wf.IsMultiStep() {
+		return wf.Command, true
+	}
+	cmds := make([]string, len(wf.Steps))
+	for i, step := range wf.Steps {
+		if step.When != "" {
+			w.add("skipped: step %d only runs when %q, which cannot be exported (use wf export bash)", i+1, step.When)
+			return "", false
+		}
+		cmds[i] = step.Command
+		if strings.Contains(step.Command, "\n") {
+			cmds[i] = "{\n" + strings.TrimRight(step.Command, "\n") + "\n}"
+		}
+		if step.ContinueOnError {
+			cmds[i] = "{ " + cmds[i] + " || true; }"
+		}
+		if step.Confirm {
+			w.add("step %d: confirm dropped", i+1)
+		}
+	}
+	w.add("%d steps joined with &&", len(wf.Steps))
+	return strings.Join(cmds, " && "), true
+}
+
+// paramsByName returns the workflow's params with stored arg metadata
+// applied, keyed by name.
+func paramsByName(wf store.Workflow) map[string]template.Param {
+	ps := params.ForWorkflow(wf)
+	out := make(map[string]template.Param, len(ps))
+	for _, p := range ps {
+		out[p.Name] = p
+	}
+	return out
+}
+
+// reportParamLosses warns about param behaviour that a plain
+// name-and-default format such as Pet or Warp cannot express.
+func reportParamLosses(wf store.Workflow, w *warner) {
+	for _, p := range params.ForWorkflow(wf) {
+		switch p.Type {
+		case template.ParamEnum:
+			w.add("arg %q: enum options dropped (%s)", p.Name, strings.Join(p.Options, ", "))
+		case template.ParamDynamic:
+			w.add("arg %q: dynamic command dropped (%s)", p.Name, p.DynamicCmd)
+		case template.ParamList:
+			w.add("arg %q: list command dropped (%s)", p.Name, p.ListCmd)
+		case template.ParamSecret:
+			if p.SecretEnv != "" || p.SecretCmd != "" {
+				w.add("arg %q: secret source dropped", p.Name)
+			}
+		}
+		if p.Validation != nil {
+			w.add("arg %q: validate rules dropped", p.Name)
+		}
+	}
+}
+
+// reportTransforms warns that a placeholder's transforms are not applied.
+func reportTransforms(ph template.Placeholder, w *warner) {
+	if len(ph.Transforms) > 0 {
+		w.add("%s: transforms dropped", ph.Text)
+	}
+}
+
+// contextShell returns a POSIX shell expression that produces the value of
+// a {{@...}} context variable, such as $(git rev-parse --short HEAD) for
+// @git.sha. ok is false for variables with no shell equivalent.
+func contextShell(p template.Param) (expr string, ok bool) {
+	name := strings.TrimPrefix(p.Name, contextvar.Prefix)
+	if env, found := strings.CutPrefix(name, "env."); found {
+		if p.Default != "" {
+			return "${" + env + ":-" + doubleQuoteEscape(p.Default) + "}", true
+		}
+		return "${" + env + "}", true
+	}
+	if (name == "date" || name == "time") && p.Default != "" {
+		format, ok := strftime(p.Default)
+		if !ok {
+			return "", false
+		}
+		return "$(date " + singleQuote("+"+format) + ")", true
+	}
+	switch name {
+	case "cwd":
+		return "${PWD}", true
+	case "dir":
+		return `$(basename "$PWD")`, true
+	case "home":
+		return "${HOME}", true
+	case "user":
+		return "${USER}", true
+	case "hostname":
+		return "$(hostname)", true
+	case "date":
+		return "$(date +%Y-%m-%d)", true
+	case "time":
+		return "$(date +%H:%M:%S)", true
+	case "timestamp":
+		return "$(date +%s)", true
+	case "git.branch":
+		return "$(git rev-parse --abbrev-ref HEAD)", true
+	case "git.root":
+		return "$(git rev-parse --show-toplevel)", true
+	case "git.repo":
+		return `$(basename "$(git rev-parse --show-toplevel)")`, true
+	case "git.sha":
+		return "$(git rev-parse --short HEAD)", true
+	}
+	return "", false
+}
+
+// inQuoteContext wraps a shell expression that expands inside double quotes
+// so it yields one word wherever the placeholder sat.
+func inQuoteContext(expr string, quote byte) string {
+	switch quote {
+	case '"':
+		return expr
+	case '\'':
+		return `'"` + expr + `"'`
+	default:
+		return `"` + expr + `"`
+	}
+}
+
+// contextPlaceholder translates a context variable for formats that run
+// commands through a POSIX shell, reporting variables it cannot translate.
+func contextPlaceholder(ph template.Placeholder, w *warner) string {
+	expr, ok := contextShell(ph.Param)
+	if !ok {
+		w.add("%s: context variable has no shell equivalent and is left as-is", ph.Text)
+		return ph.Text
+	}
+	reportTransforms(ph, w)
+	return inQuoteContext(expr, ph.Quote)
+}
+
+// layoutTokens maps Go time layout elements to strftime conversions,
+// longest first so "2006" wins over "2".
+var layoutTokens = []struct{ layout, format string }{
+	{"January", "%B"}, {"Monday", "%A"},
+	{"-07:00", "%:z"}, {"-0700", "%z"},
+	{"2006", "%Y"}, {"Jan", "%b"}, {"Mon", "%a"}, {"MST", "%Z"}, {"002", "%j"},
+	{"01", "%m"}, {"02", "%d"}, {"_2", "%e"}, {"15", "%H"}, {"03", "%I"},
+	{"04", "%M"}, {"05", "%S"}, {"06", "%y"}, {"PM", "%p"},
+	{"1", "%-m"}, {"2", "%-d"}, {"3", "%-I"}, {"4", "%-M"}, {"5", "%-S"},
+}
+
+// strftime converts a Go time layout such as "2006-01-02 15:04" into a
+// date(1) format. Fractional seconds have no equivalent and report false.
+func strftime(layout string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(layout); {
+		if strings.HasPrefix(layout[i:], ".0") || strings.HasPrefix(layout[i:], ".9") {
+			return "", false
+		}
+		matched := false
+		for _, t := range layoutTokens {
+			if strings.HasPrefix(layout[i:], t.layout) {
+				b.WriteString(t.format)
+				i += len(t.layout)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			if layout[i] == '%' {
+				b.WriteByte('%')
+			}
+			b.WriteByte(layout[i])
+			i++
+		}
+	}
+	return b.String(), true
+}
+
+// singleQuote quotes s as one POSIX shell word.
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var doubleQuoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`", "}", `\}`)
+
+// doubleQuoteEscape escapes s for use inside "..." or a ${var:-...} default.
+func doubleQuoteEscape(s string) string {
+	return doubleQuoteEscaper.Replace(s)
+}
@@ -0,0 +1,198 @@
+package exporter
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredriklanga/wf/internal/importer"
+	"github.com/fredriklanga/wf/internal/store"
+)
+
+func sampleWorkflows() []store.Workflow {
+	return []store.Workflow{
+		{
+			Name:        "infra/deploy",
+			Description: "Deploy the app",
+			Command:     `kubectl apply -n {{env|dev|*prod}} -f {{file:app.yaml}} --context '{{ctx}}'`,
+			Tags:        []string{"k8s"},
+			Args: []store.Arg{
+				{Name: "file", Description: "Manifest"},
+				{Name: "ctx", Default: "main"},
+			},
+		},
+		{
+			Name:    "status",
+			Command: `docker inspect --format '\{{.State.Status}}' {{container |> lower}}`,
+			Tags:    []string{"docker"},
+		},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	wfs := sampleWorkflows()
+	names := func(ws []store.Workflow) []string {
+		var out []string
+		for _, w := range ws {
+			out = append(out, w.Name)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"infra/deploy"}, names(Select(wfs, Filter{Folder: "infra/"})))
+	assert.Equal(t, []string{"status"}, names(Select(wfs, Filter{Tags: []string{"Docker"}})))
+	assert.Equal(t, []string{"infra/deploy"}, names(Select(wfs, Filter{Names: []string{"dep*"}})))
+	assert.Equal(t, []string{"infra/deploy", "status"}, names(Select(wfs, Filter{Names: []string{"infra/deploy", "status"}})))
+	assert.Empty(t, Select(wfs, Filter{Folder: "infra", Tags: []string{"docker"}}))
+}
+
+func TestPetExport_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	result, err := (&PetExporter{}).Export(&buf, sampleWorkflows())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Exported)
+	assert.Contains(t, buf.String(), `kubectl apply -n <env=prod> -f <file=app.yaml> --context '<ctx=main>'`)
+	assert.Contains(t, result.Warnings, `pet snippet "infra/deploy": arg "env": enum options dropped (dev, prod)`)
+	assert.Contains(t, result.Warnings, `pet snippet "status": {{container |> lower}}: transforms dropped`)
+
+	back, err := (&importer.PetImporter{}).Import(&buf)
+	require.NoError(t, err)
+	require.Len(t, back.Workflows, 2)
+	assert.Equal(t, "deploy-the-app", back.Workflows[0].Name)
+	assert.Equal(t, []string{"k8s"}, back.Workflows[0].Tags)
+	assert.Equal(t, `docker inspect --format '\{{.State.Status}}' {{container}}`, back.Workflows[1].Command)
+}
+
+func TestWarpExport_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	result, err := (&WarpExporter{}).Export(&buf, sampleWorkflows())
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Exported)
+
+	back, err := (&importer.WarpImporter{}).Import(&buf)
+	require.NoError(t, err)
+	require.Len(t, back.Workflows, 2)
+
+	deploy := back.Workflows[0]
+	assert.Equal(t, "infra/deploy", deploy.Name)
+	assert.Equal(t, `kubectl apply -n {{env}} -f {{file}} --context '{{ctx}}'`, deploy.Command)
+	require.Len(t, deploy.Args, 3)
+	assert.Equal(t, store.Arg{Name: "env", Default: "prod"}, deploy.Args[0])
+	assert.Equal(t, store.Arg{Name: "file", Default: "app.yaml", Description: "Manifest"}, deploy.Args[1])
+	assert.Equal(t, store.Arg{Name: "ctx", Default: "main"}, deploy.Args[2])
+
+	assert.Equal(t, `docker inspect --format '\{{.State.Status}}' {{container}}`, back.Workflows[1].Command)
+}
+
+func TestSingleCommandExports_StepSettings(t *testing.T) {
+	wfs := []store.Workflow{
+		{
+			Name:  "release",
+			Steps: []store.Step{{Command: "make build"}, {Command: "make publish", When: "env == prod"}},
+		},
+		{
+			Name:  "cleanup",
+			Steps: []store.Step{{Command: "make clean", ContinueOnError: true}, {Command: "make"}},
+		},
+	}
+	exporters := map[string]Exporter{"pet": &PetExporter{}, "warp": &WarpExporter{}}
+	for name, exp := range exporters {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			result, err := exp.Export(&buf, wfs)
+			require.NoError(t, err)
+
+			assert.Equal(t, 1, result.Exported)
+			assert.NotContains(t, buf.String(), "make publish", "a gated step is never exported unconditionally")
+			assert.Contains(t, strings.Join(result.Warnings, "\n"), `"release": skipped: step 2 only runs when "env == prod"`)
+			assert.Contains(t, buf.String(), "{ make clean || true; } && make")
+		})
+	}
+}
+
+func TestJSONExport_RoundTripIsLossless(t *testing.T) {
+	min := 1.0
+	wfs := append(sampleWorkflows(), store.Workflow{
+		Name: "release",
+		Steps: []store.Step{
+			{Name: "build", Command: "make build\nmake test"},
+			{Command: "make publish", When: "publish", Confirm: true},
+		},
+		Args: []store.Arg{{Name: "publish", Type: "enum", Options: []string{"yes", "no"}, Validate: &store.Validation{Min: &min}}},
+	})
+	var buf bytes.Buffer
+	result, err := (&JSONExporter{}).Export(&buf, wfs)
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+	assert.True(t, strings.HasPrefix(buf.String(), "{\n  \"version\": 1,"))
+
+	back, err := (&importer.JSONImporter{}).Import(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, wfs, back.Workflows)
+}
+
+func TestBashExport(t *testing.T) {
+	wfs := append(sampleWorkflows(), store.Workflow{
+		Name: "release",
+		Steps: []store.Step{
+			{Command: "echo build {{version}}"},
+			{Command: "echo publish", When: "channel == stable || force"},
+		},
+		Args: []store.Arg{{Name: "token", Type: "secret", SecretEnv: "TOKEN"}},
+	})
+	var buf bytes.Buffer
+	result, err := (&BashExporter{}).Export(&buf, wfs)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Exported)
+	out := buf.String()
+
+	assert.Contains(t, out, "# Usage: infra_deploy [ENV] [FILE] [CTX]\ninfra_deploy() {\n")
+	assert.Contains(t, out, "\tlocal env=\"${1:-prod}\"\n\tcase \"$env\" in\n\t\t'dev' | 'prod') ;;\n")
+	assert.Contains(t, out, `kubectl apply -n "${env}" -f "${file}" --context ''"${ctx}"''`)
+	assert.Contains(t, out, `docker inspect --format '{{.State.Status}}' "${container,,}"`)
+	assert.Contains(t, out, "\tif { [ \"$channel\" = 'stable' ]; } || { __wf_truthy \"$force\"; }; then\n")
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	script := filepath.Join(t.TempDir(), "wf.sh")
+	require.NoError(t, os.WriteFile(script, buf.Bytes(), 0644))
+	run := func(call string) (string, error) {
+		out, err := exec.Command(bash, "-c", "source "+script+"; "+call).CombinedOutput()
+		return string(out), err
+	}
+
+	got, err := run("release 1.2 stable 0")
+	require.NoError(t, err, got)
+	assert.Equal(t, "build 1.2\npublish\n", got)
+
+	got, err = run("release 1.2 beta no")
+	require.NoError(t, err, got)
+	assert.Equal(t, "build 1.2\n", got)
+
+	got, err = run("infra_deploy staging")
+	assert.Error(t, err)
+	assert.Contains(t, got, "env must be one of: dev prod")
+}
+
+func TestStrftime(t *testing.T) {
+	tests := map[string]string{
+		"2006-01-02":      "%Y-%m-%d",
+		"Jan 2, 15:04:05": "%b %-d, %H:%M:%S",
+		"Monday 3PM":      "%A %-I%p",
+		"100%":            "%-m00%%",
+	}
+	for layout, want := range tests {
+		got, ok := strftime(layout)
+		assert.True(t, ok, layout)
+		assert.Equal(t, want, got, layout)
+	}
+	_, ok := strftime("15:04:05.000")
+	assert.False(t, ok)
+}
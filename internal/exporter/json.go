@@ -0,0 +1,40 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fredriklanga/wf/internal/importer"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/goccy/go-yaml"
+)
+
+// JSONExporter writes wf Workflows as a JSON bundle that wf import json
+// reads back without loss.
+type JSONExporter struct{}
+
+// Export writes a bundle holding every workflow in wf's own schema.
+func (e *JSONExporter) Export(writer io.Writer, workflows []store.Workflow) (*ExportResult, error) {
+	bundle := importer.JSONBundle{Version: store.SchemaVersion, Workflows: make([]store.Workflow, len(workflows))}
+	for i, wf := range workflows {
+		wf.Version = 0 // The bundle carries the version
+		bundle.Workflows[i] = wf
+	}
+
+	// Marshal through the YAML encoder so keys match the workflow files.
+	data, err := yaml.MarshalWithOptions(bundle, yaml.JSON())
+	if err != nil {
+		return nil, fmt.Errorf("marshalling JSON bundle: %w", err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, fmt.Errorf("formatting JSON bundle: %w", err)
+	}
+	out.WriteByte('\n')
+	if _, err := out.WriteTo(writer); err != nil {
+		return nil, err
+	}
+	return &ExportResult{Exported: len(workflows)}, nil
+}
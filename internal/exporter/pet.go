@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/contextvar"
+	"github.com/fredriklanga/wf/internal/importer"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// PetExporter writes wf Workflows as a Pet TOML snippet file.
+type PetExporter struct{}
+
+// Export converts workflows to Pet snippets. Placeholders become <name> or
+// <name=default>. Pet names snippets by description, so a workflow without
+// one uses its name instead.
+func (e *PetExporter) Export(writer io.Writer, workflows []store.Workflow) (*ExportResult, error) {
+	result := &ExportResult{}
+	file := importer.PetFile{Snippets: make([]importer.PetSnippet, 0, len(workflows))}
+
+	for _, wf := range workflows {
+		w := newWarner("pet snippet", wf.Name)
+		command, ok := singleCommand(wf, w)
+		if !ok {
+			result.Warnings = append(result.Warnings, w.warnings...)
+			continue
+		}
+		byName := paramsByName(wf)
+		reportParamLosses(wf, w)
+
+		command = template.Rewrite(command, func(ph template.Placeholder) string {
+			if contextvar.IsContext(ph.Param.Name) {
+				return contextPlaceholder(ph, w)
+			}
+			reportTransforms(ph, w)
+			def := byName[ph.Param.Name].Default
+			if def == "" {
+				return "<" + ph.Param.Name + ">"
+			}
+			if strings.Contains(def, ">") {
+				w.add("arg %q: default %q contains > and is dropped", ph.Param.Name, def)
+				return "<" + ph.Param.Name + ">"
+			}
+			return "<" + ph.Param.Name + "=" + def + ">"
+		})
+
+		description := wf.Description
+		if description == "" {
+			description = path.Base(wf.Name)
+		}
+		snippet := importer.PetSnippet{
+			Description: description,
+			Command:     command,
+		}
+		if len(wf.Tags) > 0 {
+			snippet.Tag = wf.Tags
+		} else {
+			snippet.Tag = []string{}
+		}
+		file.Snippets = append(file.Snippets, snippet)
+		result.Warnings = append(result.Warnings, w.warnings...)
+		result.Exported++
+	}
+
+	enc := toml.NewEncoder(writer)
+	if err := enc.Encode(file); err != nil {
+		return nil, fmt.Errorf("writing pet TOML: %w", err)
+	}
+	return result, nil
+}
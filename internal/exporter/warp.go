@@ -0,0 +1,101 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/fredriklanga/wf/internal/contextvar"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/goccy/go-yaml"
+)
+
+// escapedArgLike matches escaped braces that Warp would read back as an
+// argument, such as \{{name}}.
+var escapedArgLike = regexp.MustCompile(`\\\{\{\s*\w+\s*\}\}`)
+
+// WarpExporter writes wf Workflows as a multi-document Warp YAML file.
+type WarpExporter struct{}
+
+// Export converts workflows to Warp workflows. Placeholders become
+// {{name}} and each param becomes an argument carrying its description and
+// default.
+func (e *WarpExporter) Export(writer io.Writer, workflows []store.Workflow) (*ExportResult, error) {
+	result := &ExportResult{}
+
+	for _, wf := range workflows {
+		w := newWarner("warp workflow", wf.Name)
+		command, ok := singleCommand(wf, w)
+		if !ok {
+			result.Warnings = append(result.Warnings, w.warnings...)
+			continue
+		}
+		reportParamLosses(wf, w)
+
+		for _, m := range escapedArgLike.FindAllString(command, -1) {
+			w.add("literal %s will be read as a Warp argument", m[1:])
+		}
+		command = template.Rewrite(command, func(ph template.Placeholder) string {
+			if contextvar.IsContext(ph.Param.Name) {
+				return contextPlaceholder(ph, w)
+			}
+			reportTransforms(ph, w)
+			return "{{" + ph.Param.Name + "}}"
+		})
+
+		descriptions := make(map[string]string, len(wf.Args))
+		for _, arg := range wf.Args {
+			descriptions[arg.Name] = arg.Description
+		}
+		out := warpDocument{
+			Name:        wf.Name,
+			Command:     command,
+			Tags:        wf.Tags,
+			Description: wf.Description,
+		}
+		if out.Tags == nil {
+			out.Tags = []string{}
+		}
+		byName := paramsByName(wf)
+		for _, p := range template.ExtractParams(wf.Template()) {
+			arg := warpDocumentArg{Name: p.Name, Description: descriptions[p.Name]}
+			if def := byName[p.Name].Default; def != "" {
+				arg.DefaultValue = &def
+			}
+			out.Arguments = append(out.Arguments, arg)
+		}
+
+		data, err := yaml.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling warp workflow %q: %w", wf.Name, err)
+		}
+		if result.Exported > 0 {
+			if _, err := io.WriteString(writer, "---\n"); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		result.Warnings = append(result.Warnings, w.warnings...)
+		result.Exported++
+	}
+	return result, nil
+}
+
+// warpDocument is the exported shape of a Warp workflow, the fields
+// importer.WarpWorkflow reads with unset optional ones left out.
+type warpDocument struct {
+	Name        string            `yaml:"name"`
+	Command     string            `yaml:"command"`
+	Tags        []string          `yaml:"tags"`
+	Description string            `yaml:"description,omitempty"`
+	Arguments   []warpDocumentArg `yaml:"arguments,omitempty"`
+}
+
+type warpDocumentArg struct {
+	Name         string  `yaml:"name"`
+	Description  string  `yaml:"description,omitempty"`
+	DefaultValue *string `yaml:"default_value"`
+}
@@ -80,16 +80,24 @@ func ShellPlain(command string) string {
 	return command
 }
 
+// replaceTemplateParams swaps each {{param}} for a sentinel so the lexer
+// sees plain words. Escaped \{{...}} is literal text and is left alone.
 func replaceTemplateParams(command string) (string, map[string]string) {
 	sentinels := make(map[string]string)
-	idx := 0
-	preprocessed := templateParamPattern.ReplaceAllStringFunc(command, func(match string) string {
-		sentinel := "__WF_PARAM_" + strconv.Itoa(idx) + "__"
-		sentinels[sentinel] = match
-		idx++
-		return sentinel
-	})
-	return preprocessed, sentinels
+	var out strings.Builder
+	last := 0
+	for _, m := range templateParamPattern.FindAllStringIndex(command, -1) {
+		if m[0] > 0 && command[m[0]-1] == '\\' {
+			continue
+		}
+		sentinel := "__WF_PARAM_" + strconv.Itoa(len(sentinels)) + "__"
+		sentinels[sentinel] = command[m[0]:m[1]]
+		out.WriteString(command[last:m[0]])
+		out.WriteString(sentinel)
+		last = m[1]
+	}
+	out.WriteString(command[last:])
+	return out.String(), sentinels
 }
 
 func renderTokenValue(value string, sentinels map[string]string, tokenStyle lipgloss.Style, paramStyle lipgloss.Style) string {
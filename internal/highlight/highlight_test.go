@@ -35,6 +35,13 @@ func TestShellPreservesTemplateParams(t *testing.T) {
 	require.Contains(t, plain, "{{project:demo}}")
 }
 
+func TestReplaceTemplateParamsSkipsEscapedBraces(t *testing.T) {
+	out, sentinels := replaceTemplateParams(`docker ps --filter name={{name}} --format '\{{Names}}'`)
+	require.Len(t, sentinels, 1)
+	require.Contains(t, out, `'\{{Names}}'`)
+	require.NotContains(t, out, "{{name}}")
+}
+
 func TestTokenStylesFromColorsHasExpectedMappings(t *testing.T) {
 	styles := TokenStylesFromColors("49", "158", "73", "242", "250")
 	require.NotEmpty(t, styles)
@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/goccy/go-yaml"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ConflictPolicy decides what happens to an imported workflow whose file
+// is already taken.
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // Keep the existing workflow
+	ConflictRename    ConflictPolicy = "rename"    // Import under a free name such as deploy-2
+	ConflictOverwrite ConflictPolicy = "overwrite" // Replace the existing workflow
+	ConflictAsk       ConflictPolicy = "ask"       // Decide interactively per conflict
+)
+
+// ParseConflictPolicy validates a --on-conflict value.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case ConflictSkip, ConflictRename, ConflictOverwrite, ConflictAsk:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid conflict policy %q (want skip, rename, overwrite or ask)", s)
+}
+
+// Action is what an import does with one workflow.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionSkip   Action = "skip"
+)
+
+// Target is the store workflows are imported into.
+type Target interface {
+	Get(name string) (*store.Workflow, error)
+	WorkflowPath(name string) string
+}
+
+// Entry tracks one imported workflow through conflict resolution.
+type Entry struct {
+	Workflow    store.Workflow
+	Existing    *store.Workflow // Workflow already using the same file, nil if none
+	Duplicate   bool            // Existing is an earlier workflow in this import, not a stored one
+	Action      Action
+	RenamedFrom string // Original name when the workflow was renamed
+	Superseded  bool   // Skipped because a later duplicate in this import overwrote it
+}
+
+// Conflict reports whether the workflow would replace another one.
+func (e *Entry) Conflict() bool {
+	return e.Existing != nil
+}
+
+// Plan matches imported workflows against the target. A workflow conflicts
+// when a stored workflow, or an earlier workflow in the same import, uses
+// the same file; names that differ only in case or punctuation collide
+// too. Entries without a conflict are planned as creates. A stored file
+// that cannot be read is an error rather than a free name.
+func Plan(workflows []store.Workflow, target Target) ([]Entry, error) {
+	entries := make([]Entry, len(workflows))
+	batch := make(map[string]int) // file path -> index of first workflow using it
+	for i, wf := range workflows {
+		entries[i] = Entry{Workflow: wf, Action: ActionCreate}
+		fpath := target.WorkflowPath(wf.Name)
+		if j, ok := batch[fpath]; ok {
+			earlier := workflows[j]
+			entries[i].Existing = &earlier
+			entries[i].Duplicate = true
+			continue
+		}
+		batch[fpath] = i
+		existing, err := stored(target, wf.Name)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Existing = existing
+	}
+	return entries, nil
+}
+
+// stored returns the workflow in target that name would replace, or nil
+// when there is none. Only a missing workflow counts as none; one that
+// fails to load must not be overwritten or handed out as a free name.
+func stored(target Target, name string) (*store.Workflow, error) {
+	existing, err := target.Get(name)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checking existing workflow %q: %w", name, err)
+	}
+	return existing, nil
+}
+
+// Decider chooses how to resolve one conflicting entry. A non-empty name
+// with ConflictRename requests that name instead of a generated one.
+type Decider func(e *Entry) (policy ConflictPolicy, name string, err error)
+
+// Resolve sets the action of every entry, calling decide for each
+// conflict in order. Renamed workflows get a name that is free both in
+// the target and among the workflows being imported. Overwriting a
+// duplicate replaces the earlier workflow in the import, which is then
+// skipped, rather than updating a stored workflow. A duplicate whose
+// earlier workflow was renamed or skipped is checked against the target
+// again.
+func Resolve(entries []Entry, target Target, decide Decider) error {
+	claimed := make(map[string]bool)
+	owner := make(map[string]int) // file path -> index of the entry writing it
+	for i := range entries {
+		e := &entries[i]
+		if e.Duplicate && !claimed[target.WorkflowPath(e.Workflow.Name)] {
+			existing, err := stored(target, e.Workflow.Name)
+			if err != nil {
+				return err
+			}
+			e.Duplicate = false
+			e.Existing = existing
+		}
+		if e.Conflict() {
+			policy, name, err := decide(e)
+			if err != nil {
+				return err
+			}
+			switch policy {
+			case ConflictSkip:
+				e.Action = ActionSkip
+			case ConflictOverwrite:
+				e.Action = ActionUpdate
+				if e.Duplicate {
+					earlier := &entries[owner[target.WorkflowPath(e.Workflow.Name)]]
+					e.Action = earlier.Action
+					earlier.Action = ActionSkip
+					earlier.Superseded = true
+				}
+			case ConflictRename:
+				if name == "" {
+					name = e.Workflow.Name
+				}
+				free, err := FreeName(name, target, claimed)
+				if err != nil {
+					return err
+				}
+				e.RenamedFrom = e.Workflow.Name
+				e.Workflow.Name = free
+				e.Action = ActionCreate
+			default:
+				return fmt.Errorf("workflow %q: unsupported conflict policy %q", e.Workflow.Name, policy)
+			}
+		}
+		if e.Action != ActionSkip {
+			fpath := target.WorkflowPath(e.Workflow.Name)
+			claimed[fpath] = true
+			owner[fpath] = i
+		}
+	}
+	return nil
+}
+
+// FreeName returns name, or name with the first free numeric suffix
+// (deploy-2, deploy-3, ...), avoiding stored workflows and claimed paths.
+func FreeName(name string, target Target, claimed map[string]bool) (string, error) {
+	candidate := name
+	for n := 2; ; n++ {
+		if !claimed[target.WorkflowPath(candidate)] {
+			existing, err := stored(target, candidate)
+			if err != nil {
+				return "", err
+			}
+			if existing == nil {
+				return candidate, nil
+			}
+		}
+		candidate = name + "-" + strconv.Itoa(n)
+	}
+}
+
+// Diff returns a unified diff from the existing workflow to the incoming
+// one, as they would be written to disk.
+func Diff(existing, incoming store.Workflow) (string, error) {
+	before, err := workflowLines(existing)
+	if err != nil {
+		return "", err
+	}
+	after, err := workflowLines(incoming)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        before,
+		B:        after,
+		FromFile: "existing",
+		ToFile:   "incoming",
+		Context:  3,
+	})
+}
+
+func workflowLines(w store.Workflow) ([]string, error) {
+	w.Version = 0
+	data, err := yaml.Marshal(&w)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling workflow %q: %w", w.Name, err)
+	}
+	return difflib.SplitLines(string(data)), nil
+}
@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConflictStore(t *testing.T, names ...string) *store.YAMLStore {
+	t.Helper()
+	s := store.NewYAMLStore(t.TempDir())
+	for _, name := range names {
+		require.NoError(t, s.Save(&store.Workflow{Name: name, Command: "echo existing"}))
+	}
+	return s
+}
+
+func mustPlan(t *testing.T, workflows []store.Workflow, target Target) []Entry {
+	t.Helper()
+	entries, err := Plan(workflows, target)
+	require.NoError(t, err)
+	return entries
+}
+
+func fixed(policy ConflictPolicy) Decider {
+	return func(e *Entry) (ConflictPolicy, string, error) { return policy, "", nil }
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	p, err := ParseConflictPolicy("Rename")
+	require.NoError(t, err)
+	assert.Equal(t, ConflictRename, p)
+
+	_, err = ParseConflictPolicy("merge")
+	assert.Error(t, err)
+}
+
+func TestPlan_DetectsStoredAndFilenameConflicts(t *testing.T) {
+	s := newConflictStore(t, "deploy", "infra/backup")
+	entries := mustPlan(t, []store.Workflow{
+		{Name: "Deploy", Command: "echo new"}, // same file as deploy
+		{Name: "infra/backup", Command: "echo new"},
+		{Name: "fresh", Command: "echo new"},
+	}, s)
+
+	require.Len(t, entries, 3)
+	assert.True(t, entries[0].Conflict())
+	assert.Equal(t, "deploy", entries[0].Existing.Name)
+	assert.True(t, entries[1].Conflict())
+	assert.False(t, entries[2].Conflict())
+}
+
+func TestPlan_DetectsDuplicatesWithinImport(t *testing.T) {
+	s := newConflictStore(t)
+	entries := mustPlan(t, []store.Workflow{
+		{Name: "say-hello", Command: "echo one"},
+		{Name: "Say Hello", Command: "echo two"},
+	}, s)
+
+	assert.False(t, entries[0].Conflict())
+	require.True(t, entries[1].Conflict())
+	assert.True(t, entries[1].Duplicate)
+	assert.Equal(t, "echo one", entries[1].Existing.Command)
+}
+
+func TestResolve_OverwriteDuplicateIsNotAnUpdate(t *testing.T) {
+	s := newConflictStore(t)
+	entries := mustPlan(t, []store.Workflow{
+		{Name: "say-hello", Command: "echo one"},
+		{Name: "Say Hello", Command: "echo two"},
+	}, s)
+	require.NoError(t, Resolve(entries, s, fixed(ConflictOverwrite)))
+
+	assert.Equal(t, ActionSkip, entries[0].Action)
+	assert.True(t, entries[0].Superseded)
+	assert.Equal(t, ActionCreate, entries[1].Action)
+}
+
+func TestResolve_OverwriteDuplicateOfStoredWorkflow(t *testing.T) {
+	s := newConflictStore(t, "deploy")
+	entries := mustPlan(t, []store.Workflow{
+		{Name: "deploy", Command: "echo a"},
+		{Name: "Deploy", Command: "echo b"},
+	}, s)
+	require.NoError(t, Resolve(entries, s, fixed(ConflictOverwrite)))
+
+	assert.Equal(t, ActionSkip, entries[0].Action)
+	assert.Equal(t, ActionUpdate, entries[1].Action)
+}
+
+func TestResolve_DuplicateOfSkippedWorkflowConflictsWithStore(t *testing.T) {
+	s := newConflictStore(t, "deploy")
+	entries := mustPlan(t, []store.Workflow{
+		{Name: "deploy", Command: "echo a"},
+		{Name: "deploy", Command: "echo b"},
+	}, s)
+	var duplicates []bool
+	err := Resolve(entries, s, func(e *Entry) (ConflictPolicy, string, error) {
+		duplicates = append(duplicates, e.Duplicate)
+		return ConflictSkip, "", nil
+	})
+	require.NoError(t, err)
+
+	// The first workflow is skipped, so the second one conflicts with the
+	// stored workflow rather than with the first.
+	assert.Equal(t, []bool{false, false}, duplicates)
+	assert.Equal(t, "echo existing", entries[1].Existing.Command)
+}
+
+func TestPlan_UnreadableStoredWorkflowIsNotFree(t *testing.T) {
+	s := store.NewYAMLStore(t.TempDir())
+	require.NoError(t, os.WriteFile(s.WorkflowPath("deploy"), []byte("name: deploy\ncommand: [unclosed\n"), 0644))
+
+	_, err := Plan([]store.Workflow{{Name: "deploy", Command: "echo new"}}, s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "deploy")
+
+	// A rename must not land on the unreadable file either.
+	require.NoError(t, s.Save(&store.Workflow{Name: "other", Command: "echo existing"}))
+	entries := mustPlan(t, []store.Workflow{{Name: "other", Command: "echo new"}}, s)
+	err = Resolve(entries, s, func(e *Entry) (ConflictPolicy, string, error) {
+		return ConflictRename, "deploy", nil
+	})
+	assert.Error(t, err)
+}
+
+func TestResolve_Policies(t *testing.T) {
+	workflows := []store.Workflow{{Name: "deploy", Command: "echo new"}, {Name: "fresh", Command: "echo new"}}
+
+	tests := []struct {
+		policy ConflictPolicy
+		action Action
+		name   string
+	}{
+		{ConflictSkip, ActionSkip, "deploy"},
+		{ConflictOverwrite, ActionUpdate, "deploy"},
+		{ConflictRename, ActionCreate, "deploy-2"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s := newConflictStore(t, "deploy")
+			entries := mustPlan(t, workflows, s)
+			require.NoError(t, Resolve(entries, s, fixed(tt.policy)))
+
+			assert.Equal(t, tt.action, entries[0].Action)
+			assert.Equal(t, tt.name, entries[0].Workflow.Name)
+			assert.Equal(t, ActionCreate, entries[1].Action)
+		})
+	}
+}
+
+func TestResolve_RenameAvoidsStoreAndBatch(t *testing.T) {
+	s := newConflictStore(t, "deploy", "deploy-2")
+	entries := mustPlan(t, []store.Workflow{
+		{Name: "deploy", Command: "echo a"},
+		{Name: "deploy", Command: "echo b"},
+	}, s)
+	require.NoError(t, Resolve(entries, s, fixed(ConflictRename)))
+
+	assert.Equal(t, "deploy-3", entries[0].Workflow.Name)
+	assert.Equal(t, "deploy", entries[0].RenamedFrom)
+	assert.Equal(t, "deploy-4", entries[1].Workflow.Name)
+}
+
+func TestResolve_RenameToRequestedName(t *testing.T) {
+	s := newConflictStore(t, "deploy", "taken")
+	entries := mustPlan(t, []store.Workflow{{Name: "deploy"}, {Name: "other"}}, s)
+	err := Resolve(entries, s, func(e *Entry) (ConflictPolicy, string, error) {
+		return ConflictRename, "taken", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "taken-2", entries[0].Workflow.Name)
+}
+
+func TestResolve_DeciderOnlyCalledForConflicts(t *testing.T) {
+	s := newConflictStore(t, "deploy")
+	entries := mustPlan(t, []store.Workflow{{Name: "deploy"}, {Name: "fresh"}}, s)
+	var asked []string
+	err := Resolve(entries, s, func(e *Entry) (ConflictPolicy, string, error) {
+		asked = append(asked, e.Workflow.Name)
+		return ConflictSkip, "", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy"}, asked)
+}
+
+func TestDiff(t *testing.T) {
+	existing := store.Workflow{Name: "deploy", Command: "echo old", Version: store.SchemaVersion}
+	incoming := store.Workflow{Name: "deploy", Command: "echo new"}
+
+	diff, err := Diff(existing, incoming)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- existing")
+	assert.Contains(t, diff, "-command: echo old")
+	assert.Contains(t, diff, "+command: echo new")
+	assert.NotContains(t, diff, "version")
+
+	same, err := Diff(incoming, incoming)
+	require.NoError(t, err)
+	assert.Empty(t, same)
+}
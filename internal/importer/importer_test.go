@@ -103,6 +103,23 @@ func TestPetImport_SingleSnippet(t *testing.T) {
 	assert.Equal(t, "staging", wf.Args[1].Default)
 }
 
+func TestPetImport_EscapesLiteralBraces(t *testing.T) {
+	toml := `
+[[snippets]]
+  description = "List containers"
+  command = "docker ps --filter name=<name> --format '{{.Names}}'"
+`
+	imp := &PetImporter{}
+	result, err := imp.Import(strings.NewReader(toml))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 1)
+
+	wf := result.Workflows[0]
+	assert.Equal(t, `docker ps --filter name={{name}} --format '\{{.Names}}'`, wf.Command)
+	require.Len(t, wf.Args, 1)
+	assert.Equal(t, "name", wf.Args[0].Name)
+}
+
 func TestPetImport_OutputFieldWarning(t *testing.T) {
 	toml := `
 [[snippets]]
@@ -338,6 +355,8 @@ name: Test
 command: echo {{arg}}
 tags: []
 description: Test
+arguments:
+  - name: arg
 `
 	imp := &WarpImporter{}
 	result, err := imp.Import(strings.NewReader(yaml))
@@ -346,6 +365,35 @@ description: Test
 	assert.Equal(t, "echo {{arg}}", result.Workflows[0].Command)
 }
 
+func TestWarpImport_EscapesLiteralBraces(t *testing.T) {
+	yaml := `
+name: Container status
+command: docker inspect --format '{{.State.Status}}' {{container}}
+arguments:
+  - name: container
+    description: Container name
+`
+	imp := &WarpImporter{}
+	result, err := imp.Import(strings.NewReader(yaml))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 1)
+	assert.Equal(t, `docker inspect --format '\{{.State.Status}}' {{container}}`, result.Workflows[0].Command)
+}
+
+func TestWarpImport_EscapesUndeclaredPlaceholders(t *testing.T) {
+	yaml := `
+name: Container IDs
+command: docker ps --filter name={{name}} --format '{{ID}} {{ name }}'
+arguments:
+  - name: name
+`
+	imp := &WarpImporter{}
+	result, err := imp.Import(strings.NewReader(yaml))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 1)
+	assert.Equal(t, `docker ps --filter name={{name}} --format '\{{ID}} {{ name }}'`, result.Workflows[0].Command)
+}
+
 func TestWarpImport_MultipleWorkflows(t *testing.T) {
 	yaml := `
 name: First
@@ -385,6 +433,190 @@ arguments:
 	assert.Equal(t, "hello", result.Workflows[0].Args[0].Default)
 }
 
+// =============================================================================
+// Navi Import Tests
+// =============================================================================
+
+func TestNaviImport_CheatWithVariables(t *testing.T) {
+	cheat := `% kubernetes, k8s
+
+# Tail logs of a pod
+kubectl logs -f -n <namespace> <pod>
+
+$ namespace: kubectl get ns --no-headers --- --column 1 --delimiter ' '
+$ pod: kubectl get pods -n $namespace --no-headers
+`
+	imp := &NaviImporter{}
+	result, err := imp.Import(strings.NewReader(cheat))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 1)
+
+	wf := result.Workflows[0]
+	assert.Equal(t, "tail-logs-of-a-pod", wf.Name)
+	assert.Equal(t, "kubectl logs -f -n {{namespace}} {{pod}}", wf.Command)
+	assert.Equal(t, []string{"kubernetes", "k8s"}, wf.Tags)
+	require.Len(t, wf.Args, 2)
+	assert.Equal(t, "list", wf.Args[0].Type)
+	assert.Equal(t, "kubectl get ns --no-headers", wf.Args[0].ListCmd)
+	assert.Equal(t, 1, wf.Args[0].ListFieldIndex)
+	assert.Equal(t, " ", wf.Args[0].ListDelimiter)
+	assert.Equal(t, "dynamic", wf.Args[1].Type)
+	assert.Equal(t, "kubectl get pods -n {{namespace}} --no-headers", wf.Args[1].DynamicCmd)
+	assert.Empty(t, result.Warnings)
+}
+
+func TestNaviImport_UnsupportedOptionWarning(t *testing.T) {
+	cheat := `% git
+# Checkout branch
+git checkout <branch>
+$ branch: git branch --format='%(refname:short)' --- --preview 'git log {}'
+`
+	imp := &NaviImporter{}
+	result, err := imp.Import(strings.NewReader(cheat))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 1)
+	assert.Equal(t, "dynamic", result.Workflows[0].Args[0].Type)
+	assert.True(t, warningsContain(result.Warnings, `navi cheat "checkout-branch": arg "branch": option --preview`))
+}
+
+func TestNaviImport_MultiLineAndUniqueNames(t *testing.T) {
+	cheat := `% misc
+# Say hello
+echo hello \
+  world
+
+# Say hello
+echo {{literal}}
+`
+	imp := &NaviImporter{}
+	result, err := imp.Import(strings.NewReader(cheat))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 2)
+	assert.Equal(t, "say-hello", result.Workflows[0].Name)
+	assert.Equal(t, "echo hello \\\n  world", result.Workflows[0].Command)
+	assert.Equal(t, "say-hello-2", result.Workflows[1].Name)
+	assert.Equal(t, `echo \{{literal}}`, result.Workflows[1].Command)
+	assert.Empty(t, result.Workflows[1].Args)
+}
+
+// =============================================================================
+// Shell Import Tests
+// =============================================================================
+
+func TestShellImport_Aliases(t *testing.T) {
+	rc := `# Git shortcuts
+alias gs='git status' gl="git log --oneline"
+alias ll=ls\ -la
+export PATH=$PATH:/opt/bin
+`
+	imp := &ShellImporter{}
+	result, err := imp.Import(strings.NewReader(rc))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 3)
+	assert.Equal(t, "gs", result.Workflows[0].Name)
+	assert.Equal(t, "git status", result.Workflows[0].Command)
+	assert.Equal(t, "Git shortcuts", result.Workflows[0].Description)
+	assert.Equal(t, []string{"alias"}, result.Workflows[0].Tags)
+	assert.Equal(t, "git log --oneline", result.Workflows[1].Command)
+	assert.Equal(t, "ls -la", result.Workflows[2].Command)
+	assert.Empty(t, result.Workflows[2].Description)
+}
+
+func TestShellImport_FunctionPositionalParams(t *testing.T) {
+	rc := `# Deploy to an environment
+deploy() {
+    local env="${1:-dev}"
+    kubectl apply -n "$env" -f "$2"
+}
+
+function mkcd { mkdir -p "$1" && cd "$1"; }
+`
+	imp := &ShellImporter{}
+	result, err := imp.Import(strings.NewReader(rc))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 2)
+
+	deploy := result.Workflows[0]
+	assert.Equal(t, "deploy", deploy.Name)
+	assert.Equal(t, "Deploy to an environment", deploy.Description)
+	assert.Equal(t, `kubectl apply -n "{{env}}" -f "{{arg2}}"`, deploy.Command)
+	require.Len(t, deploy.Args, 2)
+	assert.Equal(t, store.Arg{Name: "env", Default: "dev"}, deploy.Args[0])
+	assert.Equal(t, store.Arg{Name: "arg2"}, deploy.Args[1])
+
+	mkcd := result.Workflows[1]
+	assert.Equal(t, `mkdir -p "{{arg1}}" && cd "{{arg1}}"`, mkcd.Command)
+	assert.Equal(t, []string{"function"}, mkcd.Tags)
+	assert.Empty(t, result.Warnings)
+}
+
+func TestShellImport_FunctionWarnings(t *testing.T) {
+	rc := `g() {
+  git "$@"
+}
+broken() {
+  echo no end
+`
+	imp := &ShellImporter{}
+	result, err := imp.Import(strings.NewReader(rc))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 1)
+	assert.True(t, warningsContain(result.Warnings, `shell function "g": uses $@`))
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Error(), "missing closing }")
+}
+
+// =============================================================================
+// Markdown Import Tests
+// =============================================================================
+
+func TestMarkdownImport_Runbook(t *testing.T) {
+	doc := "# Ops Runbook\n\n## Restart a service\n\nRestart it gracefully:\n\n" +
+		"```bash\nsystemctl restart <service>\n```\n\n" +
+		"## Check disk\n\n```console\n$ df -h \\\n  /var\nFilesystem Size\n```\n\n" +
+		"```python\nprint('skipped')\n```\n"
+	imp := &MarkdownImporter{}
+	result, err := imp.Import(strings.NewReader(doc))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 2)
+
+	restart := result.Workflows[0]
+	assert.Equal(t, "restart-it-gracefully", restart.Name)
+	assert.Equal(t, "systemctl restart {{service}}", restart.Command)
+	assert.Equal(t, []string{"ops runbook"}, restart.Tags)
+	require.Len(t, restart.Args, 1)
+	assert.Equal(t, "service", restart.Args[0].Name)
+
+	disk := result.Workflows[1]
+	assert.Equal(t, "check-disk", disk.Name)
+	assert.Equal(t, "Check disk", disk.Description)
+	assert.Equal(t, "df -h \\\n  /var", disk.Command)
+}
+
+func TestMarkdownImport_Tldr(t *testing.T) {
+	page := "# tar\n\n> Archiving utility.\n\n" +
+		"- Create an archive from files:\n\n`tar cf {{target.tar}} {{path/to/file}}`\n"
+	imp := &MarkdownImporter{}
+	result, err := imp.Import(strings.NewReader(page))
+	require.NoError(t, err)
+	require.Len(t, result.Workflows, 1)
+
+	wf := result.Workflows[0]
+	assert.Equal(t, "create-an-archive-from-files", wf.Name)
+	assert.Equal(t, "Create an archive from files", wf.Description)
+	assert.Equal(t, "tar cf {{target_tar}} {{path_to_file}}", wf.Command)
+	assert.Equal(t, []string{"tar"}, wf.Tags)
+	require.Len(t, wf.Args, 2)
+}
+
+func TestMarkdownImport_UnclosedBlock(t *testing.T) {
+	imp := &MarkdownImporter{}
+	result, err := imp.Import(strings.NewReader("```sh\necho hi\n"))
+	require.NoError(t, err)
+	assert.Empty(t, result.Workflows)
+	require.Len(t, result.Errors, 1)
+}
+
 // =============================================================================
 // Interface Compliance Tests
 // =============================================================================
@@ -397,6 +629,12 @@ func TestWarpImporterImplementsImporter(t *testing.T) {
 	var _ Importer = &WarpImporter{}
 }
 
+func TestNaviShellMarkdownImportersImplementImporter(t *testing.T) {
+	var _ Importer = &NaviImporter{}
+	var _ Importer = &ShellImporter{}
+	var _ Importer = &MarkdownImporter{}
+}
+
 // =============================================================================
 // Helpers
 // =============================================================================
@@ -0,0 +1,47 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/goccy/go-yaml"
+)
+
+// JSONBundle is the file written by wf export json: every exported
+// workflow in wf's own schema, so a bundle round-trips without loss.
+type JSONBundle struct {
+	Version   int              `yaml:"version"`
+	Workflows []store.Workflow `yaml:"workflows"`
+}
+
+// JSONImporter reads JSON bundles written by wf export json.
+type JSONImporter struct{}
+
+// Import reads a JSON bundle from the reader. Bundles written for a newer
+// schema than this build understands are rejected.
+func (j *JSONImporter) Import(reader io.Reader) (*ImportResult, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading JSON bundle: %w", err)
+	}
+
+	var bundle JSONBundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("parsing JSON bundle: %w", err)
+	}
+	if bundle.Version > store.SchemaVersion {
+		return nil, fmt.Errorf("bundle schema version %d is newer than this wf supports (%d); upgrade wf", bundle.Version, store.SchemaVersion)
+	}
+
+	result := &ImportResult{}
+	for i, wf := range bundle.Workflows {
+		if wf.Name == "" || wf.Template() == "" {
+			result.Errors = append(result.Errors, fmt.Errorf("bundle workflow #%d: missing name or command", i+1))
+			continue
+		}
+		wf.Version = 0
+		result.Workflows = append(result.Workflows, wf)
+	}
+	return result, nil
+}
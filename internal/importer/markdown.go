@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+var (
+	// markdownFence matches the opening line of a fenced code block,
+	// capturing the fence and the language of the info string.
+	markdownFence = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w-]*)")
+
+	// tldrCommand matches a tldr-style example: a line holding one inline
+	// code span.
+	tldrCommand = regexp.MustCompile("^\\s*`([^`]+)`\\s*$")
+
+	// tldrParamName matches characters not allowed in a wf param name.
+	tldrParamName = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// markdownShellLangs are the code block languages imported as commands.
+var markdownShellLangs = map[string]bool{
+	"sh": true, "bash": true, "shell": true, "zsh": true, "fish": true,
+	"console": true, "shell-session": true, "terminal": true,
+}
+
+// markdownPromptLangs are languages whose blocks mix prompts with output;
+// only lines starting with "$ " are commands.
+var markdownPromptLangs = map[string]bool{
+	"console": true, "shell-session": true, "terminal": true,
+}
+
+// MarkdownImporter converts shell snippets in Markdown documents, such as
+// runbooks, READMEs and tldr pages, into wf Workflows.
+type MarkdownImporter struct{}
+
+// Import reads a Markdown document from the reader. Each fenced code block
+// tagged as a shell language becomes a workflow, described by the text line
+// just above it or else by the nearest heading, with <name> placeholders
+// turned into {{name}}. tldr pages are also understood: a "- description:"
+// item followed by a `command` line becomes a workflow, keeping its {{...}}
+// placeholders. The document's first-level heading becomes a tag.
+func (m *MarkdownImporter) Import(reader io.Reader) (*ImportResult, error) {
+	var (
+		title   string // first-level heading
+		heading string // nearest heading
+		text    string // last line of prose
+		fence   string // open fence, or "" outside a code block
+		lang    string
+		block   []string
+	)
+	result := &ImportResult{}
+	seen := make(map[string]int)
+
+	add := func(description, command string) {
+		if description == "" {
+			description = heading
+		}
+		name := description
+		if name == "" {
+			name = strings.SplitN(command, "\n", 2)[0]
+		}
+		wf := store.Workflow{
+			Name:        uniqueSlug(slugifyName(name), seen),
+			Command:     command,
+			Description: description,
+		}
+		if title != "" {
+			wf.Tags = []string{strings.ToLower(title)}
+		}
+		for _, p := range template.ExtractParams(command) {
+			wf.Args = append(wf.Args, store.Arg{Name: p.Name})
+		}
+		result.Workflows = append(result.Workflows, wf)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo, blockStart := 0, 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				if markdownShellLangs[lang] {
+					if command := markdownBlockCommand(block, lang); command != "" {
+						add(text, command)
+					} else {
+						result.Warnings = append(result.Warnings, fmt.Sprintf("markdown block %q: no commands at line %d", lang, blockStart))
+					}
+				}
+				fence, block, text = "", nil, ""
+				continue
+			}
+			block = append(block, line)
+			continue
+		}
+
+		switch {
+		case markdownFence.MatchString(line):
+			match := markdownFence.FindStringSubmatch(line)
+			fence, lang, blockStart = match[1], strings.ToLower(match[2]), lineNo
+		case strings.HasPrefix(trimmed, "#"):
+			heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			if strings.HasPrefix(trimmed, "# ") && title == "" {
+				title = heading
+			}
+			text = ""
+		case tldrCommand.MatchString(line) && text != "":
+			command := tldrCommand.FindStringSubmatch(line)[1]
+			add(text, normalizeTldrParams(command))
+			text = ""
+		case trimmed == "" || strings.HasPrefix(trimmed, ">"):
+			// Blank lines and tldr page summaries
+		default:
+			text = strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(trimmed, "- ")), ":")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading markdown file: %w", err)
+	}
+	if fence != "" {
+		result.Errors = append(result.Errors, fmt.Errorf("markdown line %d: unclosed code block", blockStart))
+	}
+	return result, nil
+}
+
+// markdownBlockCommand returns the command held by a code block, keeping
+// only prompt lines for console sessions, and converting <name>
+// placeholders.
+func markdownBlockCommand(block []string, lang string) string {
+	var lines []string
+	continued := false
+	for _, line := range block {
+		if markdownPromptLangs[lang] {
+			cmd, ok := strings.CutPrefix(strings.TrimSpace(line), "$ ")
+			switch {
+			case ok:
+				line = cmd
+			case !continued:
+				continue
+			}
+			continued = strings.HasSuffix(line, `\`)
+		}
+		lines = append(lines, line)
+	}
+	command := strings.TrimSpace(strings.Join(dedent(lines), "\n"))
+	return naviParamRegex.ReplaceAllString(template.EscapeBraces(command), "{{$1}}")
+}
+
+// normalizeTldrParams rewrites tldr placeholders such as {{path/to/file}}
+// into valid wf params such as {{path_to_file}}.
+func normalizeTldrParams(command string) string {
+	return template.Rewrite(command, func(ph template.Placeholder) string {
+		inner := strings.TrimSuffix(strings.TrimPrefix(ph.Text, "{{"), "}}")
+		name := strings.Trim(tldrParamName.ReplaceAllString(inner, "_"), "_")
+		if name == "" {
+			name = "value"
+		}
+		return "{{" + name + "}}"
+	})
+}
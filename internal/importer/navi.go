@@ -0,0 +1,242 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// naviParamRegex matches Navi's <name> placeholders.
+var naviParamRegex = regexp.MustCompile(`<([A-Za-z_][\w-]*)>`)
+
+// naviCheat is one command in a Navi .cheat file.
+type naviCheat struct {
+	description string
+	command     []string
+	tags        []string
+	vars        map[string]naviVar // the section's variables, shared by its cheats
+}
+
+// naviVar is a `$ name: command --- options` line.
+type naviVar struct {
+	command string
+	options string
+}
+
+// NaviImporter converts Navi .cheat files into wf Workflows.
+type NaviImporter struct{}
+
+// Import reads a Navi cheatsheet from the reader. `% tags` start a section,
+// `# text` describes the next command, and `$ name: command` lines become
+// dynamic args, or list args when they use Navi's --column, --delimiter,
+// --header-lines or --multi options. A variable applies to every command in
+// its section, wherever it is defined.
+func (n *NaviImporter) Import(reader io.Reader) (*ImportResult, error) {
+	var (
+		cheats  []*naviCheat
+		tags    []string
+		vars    = map[string]naviVar{}
+		current *naviCheat
+		desc    string
+	)
+	result := &ImportResult{}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			current = nil
+		case strings.HasPrefix(trimmed, "%"):
+			tags = splitNaviTags(strings.TrimPrefix(trimmed, "%"))
+			vars = map[string]naviVar{}
+			current, desc = nil, ""
+		case strings.HasPrefix(trimmed, "#"):
+			desc = strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
+			current = nil
+		case strings.HasPrefix(trimmed, ";"):
+			// Comment
+		case strings.HasPrefix(trimmed, "@"):
+			result.Warnings = append(result.Warnings, fmt.Sprintf("navi line %d %q: extending other cheats is not supported", lineNo, trimmed))
+		case strings.HasPrefix(trimmed, "$"):
+			name, def, ok := strings.Cut(strings.TrimPrefix(trimmed, "$"), ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				result.Errors = append(result.Errors, fmt.Errorf("navi line %d: invalid variable %q", lineNo, trimmed))
+				continue
+			}
+			cmd, opts, _ := strings.Cut(def, "---")
+			vars[strings.TrimSpace(name)] = naviVar{command: strings.TrimSpace(cmd), options: strings.TrimSpace(opts)}
+			current = nil
+		case current != nil:
+			current.command = append(current.command, line)
+		default:
+			current = &naviCheat{description: desc, command: []string{line}, tags: tags, vars: vars}
+			cheats = append(cheats, current)
+			desc = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading navi file: %w", err)
+	}
+
+	seen := make(map[string]int)
+	for _, c := range cheats {
+		command := strings.Join(c.command, "\n")
+		description := c.description
+		if description == "" {
+			description = strings.TrimSpace(c.command[0])
+		}
+		name := uniqueSlug(slugifyName(description), seen)
+
+		command = naviParamRegex.ReplaceAllString(template.EscapeBraces(command), "{{$1}}")
+		var args []store.Arg
+		for _, p := range template.ExtractParams(command) {
+			arg := store.Arg{Name: p.Name}
+			if v, ok := c.vars[p.Name]; ok {
+				for _, w := range applyNaviVar(&arg, v, c.vars) {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("navi cheat %q: %s", name, w))
+				}
+			}
+			args = append(args, arg)
+		}
+
+		result.Workflows = append(result.Workflows, store.Workflow{
+			Name:        name,
+			Command:     command,
+			Description: c.description,
+			Tags:        c.tags,
+			Args:        args,
+		})
+	}
+	return result, nil
+}
+
+// applyNaviVar turns a Navi variable into a dynamic or list arg. References
+// to other variables such as $namespace become {{namespace}} so wf fills
+// them first. It returns the options that have no wf equivalent.
+func applyNaviVar(arg *store.Arg, v naviVar, vars map[string]naviVar) []string {
+	command := template.EscapeBraces(v.command)
+	command = naviVarRef.ReplaceAllStringFunc(command, func(ref string) string {
+		name := strings.Trim(ref, "${}")
+		if _, ok := vars[name]; ok && name != arg.Name {
+			return "{{" + name + "}}"
+		}
+		return ref
+	})
+	if command == "" {
+		return []string{fmt.Sprintf("arg %q: empty variable command", arg.Name)}
+	}
+
+	var warnings []string
+	list := false
+	opts := splitNaviOptions(v.options)
+	for i := 0; i < len(opts); i++ {
+		opt, value := opts[i], ""
+		if i+1 < len(opts) && !strings.HasPrefix(opts[i+1], "--") {
+			value = opts[i+1]
+		}
+		switch opt {
+		case "--multi":
+			arg.ListMulti = true
+			list = true
+			continue
+		case "--column":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				arg.ListFieldIndex = n
+				list = true
+			}
+		case "--delimiter":
+			// Navi delimiters are regular expressions; wf splits on
+			// literal text.
+			if regexp.QuoteMeta(value) == value {
+				arg.ListDelimiter = value
+			}
+			list = true
+		case "--header-lines":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				arg.ListSkipHeader = n
+				list = true
+			}
+		default:
+			if value != "" {
+				opt += " " + value
+			}
+			warnings = append(warnings, fmt.Sprintf("arg %q: option %s not supported", arg.Name, opt))
+		}
+		if value != "" {
+			i++
+		}
+	}
+	if arg.ListFieldIndex > 0 && arg.ListDelimiter == "" {
+		warnings = append(warnings, fmt.Sprintf("arg %q: --column needs a literal --delimiter; whole rows are inserted", arg.Name))
+		arg.ListFieldIndex = 0
+	}
+
+	if list {
+		arg.Type = "list"
+		arg.ListCmd = command
+	} else {
+		arg.Type = "dynamic"
+		arg.DynamicCmd = command
+	}
+	return warnings
+}
+
+// naviVarRef matches $name and ${name} references in a variable command.
+var naviVarRef = regexp.MustCompile(`\$\{?[A-Za-z_][\w]*\}?`)
+
+// splitNaviTags splits a `% tag1, tag2` section line.
+func splitNaviTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// splitNaviOptions splits the options after --- into words, honouring
+// single and double quotes such as --delimiter ' '.
+func splitNaviOptions(s string) []string {
+	var (
+		words []string
+		cur   strings.Builder
+		quote rune
+		in    bool
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, in = r, true
+		case r == ' ' || r == '\t':
+			if in {
+				words = append(words, cur.String())
+				cur.Reset()
+				in = false
+			}
+		default:
+			cur.WriteRune(r)
+			in = true
+		}
+	}
+	if in {
+		words = append(words, cur.String())
+	}
+	return words
+}
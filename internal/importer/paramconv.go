@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -24,6 +25,25 @@ func convertPetParam(command string) string {
 	})
 }
 
+// warpParamRegex matches {{...}} in a Warp command.
+var warpParamRegex = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+
+// escapeLiteralBraces escapes every {{...}} in a Warp command that is not one
+// of the workflow's declared arguments, such as docker's --format
+// '{{.State}}' or '{{ID}}', so wf keeps it as literal text.
+func escapeLiteralBraces(command string, args []WarpArgument) string {
+	declared := make(map[string]bool, len(args))
+	for _, arg := range args {
+		declared[arg.Name] = true
+	}
+	return warpParamRegex.ReplaceAllStringFunc(command, func(match string) string {
+		if declared[strings.TrimSpace(match[2:len(match)-2])] {
+			return match
+		}
+		return `\` + match
+	})
+}
+
 var slugRe = regexp.MustCompile(`[^a-z0-9-]+`)
 var dashRun = regexp.MustCompile(`-{2,}`)
 
@@ -40,3 +60,13 @@ func slugifyName(description string) string {
 	}
 	return s
 }
+
+// uniqueSlug returns slug, or slug with a numeric suffix when an earlier
+// entry in the same file already used it.
+func uniqueSlug(slug string, seen map[string]int) string {
+	seen[slug]++
+	if n := seen[slug]; n > 1 {
+		return fmt.Sprintf("%s-%d", slug, n)
+	}
+	return slug
+}
@@ -67,8 +67,9 @@ func (p *PetImporter) Import(reader io.Reader) (*ImportResult, error) {
 			continue
 		}
 
-		// Convert Pet parameter syntax to wf syntax
-		convertedCmd := convertPetParam(snippet.Command)
+		// Convert Pet parameter syntax to wf syntax. Pet has no {{...}}
+		// placeholders, so any braces already there are literal.
+		convertedCmd := convertPetParam(template.EscapeBraces(snippet.Command))
 
 		// Generate name from description via slugification
 		name := slugifyName(snippet.Description)
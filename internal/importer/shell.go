@@ -0,0 +1,263 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+var (
+	// shellFuncStart matches `name() {`, `function name {` and
+	// `function name() {`, capturing the name and anything after the brace.
+	shellFuncStart = regexp.MustCompile(`^\s*(?:function\s+([\w.:-]+)\s*(?:\(\s*\))?|([\w.:-]+)\s*\(\s*\))\s*\{(.*)$`)
+
+	// shellLocalArg matches `local name="${1:-default}"` style lines that
+	// name a positional parameter.
+	shellLocalArg = regexp.MustCompile(`^\s*(?:local\s+)?([A-Za-z_]\w*)=("?)\$\{?([1-9])(?::-([^}"]*))?\}?("?)\s*;?\s*$`)
+
+	// shellPositional matches $1 and ${1}, and ${1:-default}.
+	shellPositional = regexp.MustCompile(`\$\{([1-9])(?::-([^}]*))?\}|\$([1-9])`)
+
+	// shellUnsupported matches shell features that have no wf equivalent
+	// once a function body becomes a command.
+	shellUnsupported = regexp.MustCompile(`\$[@*#]|\$\{[@*#]\}|\bshift\b|\breturn\b|\blocal\b`)
+)
+
+// ShellImporter converts alias definitions and functions from shell
+// dotfiles, such as .bashrc or .zshrc, into wf Workflows.
+type ShellImporter struct{}
+
+// Import reads a shell script from the reader. Each `alias name='command'`
+// and each function becomes a workflow, described by the comment lines
+// directly above it. Other lines are ignored. In function bodies,
+// positional parameters become params: `local env="${1:-dev}"` yields
+// {{env}} with default dev, and a bare $1 yields {{arg1}}.
+func (s *ShellImporter) Import(reader io.Reader) (*ImportResult, error) {
+	result := &ImportResult{}
+	seen := make(map[string]int)
+	var comments []string
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			comments = append(comments, strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+			continue
+		case strings.HasPrefix(trimmed, "alias "):
+			description := strings.Join(comments, " ")
+			for _, def := range shellWords(strings.TrimPrefix(trimmed, "alias ")) {
+				name, command, ok := strings.Cut(def, "=")
+				if !ok || name == "" || strings.HasPrefix(name, "-") {
+					continue
+				}
+				if strings.TrimSpace(command) == "" {
+					result.Errors = append(result.Errors, fmt.Errorf("shell line %d: alias %q has no command", lineNo, name))
+					continue
+				}
+				result.Workflows = append(result.Workflows, store.Workflow{
+					Name:        uniqueSlug(slugifyName(name), seen),
+					Command:     template.EscapeBraces(command),
+					Description: description,
+					Tags:        []string{"alias"},
+				})
+			}
+		default:
+			m := shellFuncStart.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+			name := m[1] + m[2]
+			body, ok := readShellBody(m[3], scanner, &lineNo)
+			if !ok {
+				result.Errors = append(result.Errors, fmt.Errorf("shell function %q: missing closing }", name))
+				break
+			}
+			wf, warnings := shellFunction(body)
+			wf.Name = uniqueSlug(slugifyName(name), seen)
+			wf.Description = strings.Join(comments, " ")
+			if strings.TrimSpace(wf.Command) == "" {
+				result.Errors = append(result.Errors, fmt.Errorf("shell function %q: empty body", name))
+				break
+			}
+			result.Workflows = append(result.Workflows, wf)
+			for _, w := range warnings {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("shell function %q: %s", wf.Name, w))
+			}
+		}
+		comments = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading shell file: %w", err)
+	}
+	return result, nil
+}
+
+// readShellBody collects a function body up to its closing brace. rest is
+// the text after the opening brace on the first line.
+func readShellBody(rest string, scanner *bufio.Scanner, lineNo *int) ([]string, bool) {
+	depth := 1
+	var body []string
+	line := rest
+	for {
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth <= 0 {
+			// Drop the closing brace and anything after it.
+			if idx := strings.LastIndex(line, "}"); idx >= 0 {
+				line = line[:idx]
+			}
+			if strings.TrimSpace(line) != "" {
+				body = append(body, strings.TrimSuffix(strings.TrimSpace(line), ";"))
+			}
+			return body, true
+		}
+		if strings.TrimSpace(line) != "" {
+			body = append(body, line)
+		}
+		if !scanner.Scan() {
+			return nil, false
+		}
+		*lineNo++
+		line = scanner.Text()
+	}
+}
+
+// shellFunction converts a function body into a workflow command and args.
+func shellFunction(body []string) (store.Workflow, []string) {
+	names := make(map[string]string) // positional number -> param name
+	defaults := make(map[string]string)
+	var lines []string
+	for _, line := range dedent(body) {
+		if m := shellLocalArg.FindStringSubmatch(line); m != nil && m[2] == m[5] {
+			names[m[3]] = m[1]
+			defaults[m[1]] = m[4]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	command := template.EscapeBraces(strings.Join(lines, "\n"))
+
+	// Variables bound from positional parameters become placeholders.
+	vars := make([]string, 0, len(names))
+	for _, name := range names {
+		vars = append(vars, name)
+	}
+	sort.Slice(vars, func(i, j int) bool { return len(vars[i]) > len(vars[j]) })
+	for _, name := range vars {
+		re := regexp.MustCompile(`\$\{` + name + `\}|\$` + name + `\b`)
+		command = re.ReplaceAllString(command, "{{"+name+"}}")
+	}
+	command = shellPositional.ReplaceAllStringFunc(command, func(ref string) string {
+		m := shellPositional.FindStringSubmatch(ref)
+		n, def := m[1]+m[3], m[2]
+		name, ok := names[n]
+		if !ok {
+			name = "arg" + n
+		}
+		if def != "" && defaults[name] == "" {
+			defaults[name] = def
+		}
+		return "{{" + name + "}}"
+	})
+
+	wf := store.Workflow{Command: command, Tags: []string{"function"}}
+	for _, p := range template.ExtractParams(command) {
+		wf.Args = append(wf.Args, store.Arg{Name: p.Name, Default: defaults[p.Name]})
+	}
+
+	var warnings []string
+	found := make(map[string]bool)
+	for _, m := range shellUnsupported.FindAllString(command, -1) {
+		if !found[m] {
+			found[m] = true
+			warnings = append(warnings, fmt.Sprintf("uses %s, which only works inside a function", m))
+		}
+	}
+	return wf, warnings
+}
+
+// dedent removes the indentation shared by every non-blank line.
+func dedent(lines []string) []string {
+	prefix, first := "", true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first || len(indent) < len(prefix) {
+			prefix, first = indent, false
+		}
+	}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = strings.TrimPrefix(line, prefix)
+	}
+	return out
+}
+
+// shellWords splits an alias line into words the way the shell would,
+// removing quotes, so `a='git add' b="ls -la"` gives [a=git add, b=ls -la].
+// A trailing comment ends the line.
+func shellWords(s string) []string {
+	var (
+		words []string
+		cur   strings.Builder
+		in    bool
+		quote byte
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				cur.WriteByte(c)
+			}
+		case quote == '"':
+			switch {
+			case c == '"':
+				quote = 0
+			case c == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) >= 0:
+				i++
+				cur.WriteByte(s[i])
+			default:
+				cur.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote, in = c, true
+		case c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+			in = true
+		case c == ' ' || c == '\t':
+			if in {
+				words = append(words, cur.String())
+				cur.Reset()
+				in = false
+			}
+		case c == '#' && !in:
+			i = len(s)
+		case c == ';' && !in:
+			i = len(s)
+		default:
+			cur.WriteByte(c)
+			in = true
+		}
+	}
+	if in {
+		words = append(words, cur.String())
+	}
+	return words
+}
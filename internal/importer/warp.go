@@ -79,7 +79,7 @@ func (w *WarpImporter) Import(reader io.Reader) (*ImportResult, error) {
 
 		wf := store.Workflow{
 			Name:        warpWF.Name,
-			Command:     warpWF.Command,
+			Command:     escapeLiteralBraces(warpWF.Command, warpWF.Arguments),
 			Description: warpWF.Description,
 			Tags:        warpWF.Tags,
 			Args:        args,
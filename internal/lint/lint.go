@@ -0,0 +1,405 @@
+// Package lint checks workflow files for problems that loading them does
+// not catch: keys wf does not know, args and placeholders that do not line
+// up, enum defaults outside their options, invalid list settings, and names
+// that clash across folders.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// Severity tells whether an issue makes a workflow unusable or is only
+// suspicious.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rule names identify the check that produced an issue, so CI can filter
+// or count them.
+const (
+	RuleParse         = "parse"
+	RuleSchemaVersion = "schema-version"
+	RuleUnknownKey    = "unknown-key"
+	RuleRequired      = "required"
+	RuleTemplate      = "template"
+	RuleUnusedArg     = "unused-arg"
+	RuleMissingArg    = "missing-arg"
+	RuleDuplicateArg  = "duplicate-arg"
+	RuleInvalidArg    = "invalid-arg"
+	RuleEnumDefault   = "enum-default"
+	RuleListField     = "list-field"
+	RuleDuplicateName = "duplicate-name"
+)
+
+// Issue is one problem found in a workflow file.
+type Issue struct {
+	File     string   `json:"file"`
+	Workflow string   `json:"workflow,omitempty"`
+	Field    string   `json:"field,omitempty"` // Key path such as args[1].default
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+}
+
+func (i Issue) String() string {
+	loc := i.File
+	if i.Field != "" {
+		loc += ": " + i.Field
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", loc, i.Severity, i.Message, i.Rule)
+}
+
+// Report is the result of linting a file or directory.
+type Report struct {
+	Files  int     `json:"files"`
+	Issues []Issue `json:"issues"`
+}
+
+// Count returns the number of issues with the given severity.
+func (r Report) Count(sev Severity) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Severity == sev {
+			n++
+		}
+	}
+	return n
+}
+
+// Path lints a single workflow file, or every .yaml and .yml file under a
+// directory. Hidden directories such as .git are skipped. File names in the
+// report are relative to a directory root.
+func Path(path string) (Report, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Report{}, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Report{}, err
+		}
+		issues, _ := File(path, data)
+		return Report{Files: 1, Issues: issues}, nil
+	}
+
+	report := Report{}
+	names := make(map[string][]string) // workflow name -> files defining it
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", p, err)
+		}
+		rel, _ := filepath.Rel(path, p)
+		rel = filepath.ToSlash(rel)
+		issues, w := File(rel, data)
+		report.Files++
+		report.Issues = append(report.Issues, issues...)
+		if w != nil && w.Name != "" {
+			names[w.Name] = append(names[w.Name], rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	report.Issues = append(report.Issues, duplicateNames(names)...)
+	return report, nil
+}
+
+// File lints one workflow file's contents. name is used as the file name in
+// issues. The decoded workflow is returned when the file parses.
+func File(name string, data []byte) ([]Issue, *store.Workflow) {
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return []Issue{{File: name, Severity: SeverityError, Rule: RuleParse, Message: err.Error()}}, nil
+	}
+	var w store.Workflow
+	if err := yaml.Unmarshal(data, &w); err != nil {
+		return []Issue{{File: name, Severity: SeverityError, Rule: RuleParse, Message: err.Error()}}, nil
+	}
+
+	var issues []Issue
+	if w.Version > store.SchemaVersion {
+		issues = append(issues, Issue{Field: "version", Severity: SeverityError, Rule: RuleSchemaVersion,
+			Message: fmt.Sprintf("schema version %d is newer than this wf supports (%d)", w.Version, store.SchemaVersion)})
+	} else if w.Version < 0 {
+		issues = append(issues, Issue{Field: "version", Severity: SeverityError, Rule: RuleSchemaVersion,
+			Message: fmt.Sprintf("invalid schema version %d", w.Version)})
+	}
+	issues = append(issues, unknownKeys(raw)...)
+	issues = append(issues, Workflow(w)...)
+	for i := range issues {
+		issues[i].File = name
+		issues[i].Workflow = w.Name
+	}
+	return issues, &w
+}
+
+// Workflow checks a decoded workflow for semantic problems. Issues have no
+// File set.
+func Workflow(w store.Workflow) []Issue {
+	var issues []Issue
+	add := func(sev Severity, rule, field, format string, a ...any) {
+		issues = append(issues, Issue{Field: field, Severity: sev, Rule: rule, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if strings.TrimSpace(w.Name) == "" {
+		add(SeverityError, RuleRequired, "name", "missing name")
+	}
+	if strings.TrimSpace(w.Template()) == "" {
+		add(SeverityError, RuleRequired, "command", "missing command (set command or steps)")
+	}
+	if w.IsMultiStep() && strings.TrimSpace(w.Command) != "" {
+		add(SeverityWarning, RuleTemplate, "command", "command is ignored because steps are set")
+	}
+
+	checkCommand := func(field, command string) {
+		if err := template.CheckTransforms(command); err != nil {
+			add(SeverityError, RuleTemplate, field, "%v", err)
+		}
+		if err := template.CheckContextVars(command); err != nil {
+			add(SeverityError, RuleTemplate, field, "%v", err)
+		}
+	}
+	if !w.IsMultiStep() {
+		checkCommand("command", w.Command)
+	}
+	for i, step := range w.Steps {
+		field := fmt.Sprintf("steps[%d].command", i)
+		if strings.TrimSpace(step.Command) == "" {
+			add(SeverityError, RuleRequired, field, "step %d has no command", i+1)
+			continue
+		}
+		checkCommand(field, step.Command)
+	}
+	if err := params.CheckDependencies(w); err != nil {
+		add(SeverityError, RuleTemplate, "args", "%v", err)
+	}
+
+	used := make(map[string]bool)
+	for _, p := range params.ForWorkflow(w) {
+		used[p.Name] = true
+	}
+	argIndex := make(map[string]int, len(w.Args))
+	for i, arg := range w.Args {
+		field := fmt.Sprintf("args[%d]", i)
+		if strings.TrimSpace(arg.Name) == "" {
+			add(SeverityError, RuleRequired, field+".name", "arg has no name")
+			continue
+		}
+		if first, dup := argIndex[arg.Name]; dup {
+			add(SeverityError, RuleDuplicateArg, field+".name", "arg %q is already defined at args[%d]", arg.Name, first)
+			continue
+		}
+		argIndex[arg.Name] = i
+		if !used[arg.Name] {
+			add(SeverityWarning, RuleUnusedArg, field, "arg %q does not match any placeholder", arg.Name)
+		}
+		for _, is := range checkArg(arg) {
+			is.Field = field + is.Field
+			issues = append(issues, is)
+		}
+	}
+
+	for _, p := range template.ExtractParams(w.Template()) {
+		if _, ok := argIndex[p.Name]; !ok {
+			add(SeverityWarning, RuleMissingArg, "args", "placeholder {{%s}} has no args entry", p.Name)
+		}
+	}
+
+	// Inline enums such as {{env|dev|prod}} keep their own options, so a
+	// default from an untyped arg must still be one of them.
+	for _, p := range params.ForWorkflow(w) {
+		i, ok := argIndex[p.Name]
+		if !ok || w.Args[i].Type != "" || p.Type != template.ParamEnum {
+			continue
+		}
+		if p.Default != "" && !contains(p.Options, p.Default) {
+			add(SeverityError, RuleEnumDefault, fmt.Sprintf("args[%d].default", i),
+				"default %q is not one of the options %s", p.Default, strings.Join(p.Options, ", "))
+		}
+	}
+	return issues
+}
+
+// checkArg reports problems with a single arg. Field paths are relative to
+// the arg, e.g. ".default".
+func checkArg(arg store.Arg) []Issue {
+	var issues []Issue
+	add := func(sev Severity, rule, field, format string, a ...any) {
+		issues = append(issues, Issue{Field: field, Severity: sev, Rule: rule, Message: fmt.Sprintf(format, a...)})
+	}
+
+	switch arg.Type {
+	case "", "text", "secret":
+	case "enum":
+		if len(arg.Options) == 0 {
+			add(SeverityError, RuleInvalidArg, ".options", "%q is type enum but has no options", arg.Name)
+		} else if arg.Default != "" && !contains(arg.Options, arg.Default) {
+			add(SeverityError, RuleEnumDefault, ".default", "default %q is not one of the options %s", arg.Default, strings.Join(arg.Options, ", "))
+		}
+	case "dynamic":
+		if strings.TrimSpace(arg.DynamicCmd) == "" {
+			add(SeverityError, RuleInvalidArg, ".dynamic_cmd", "%q is type dynamic but has no dynamic_cmd", arg.Name)
+		}
+	case "list":
+		if strings.TrimSpace(arg.ListCmd) == "" {
+			add(SeverityError, RuleInvalidArg, ".list_cmd", "%q is type list but has no list_cmd", arg.Name)
+		}
+	default:
+		add(SeverityError, RuleInvalidArg, ".type", "unknown type %q (want text, enum, dynamic, list, or secret)", arg.Type)
+	}
+
+	if arg.ListFieldIndex < 0 {
+		add(SeverityError, RuleListField, ".list_field_index", "list_field_index %d must be a 1-based field number, or 0 for the whole row", arg.ListFieldIndex)
+	} else if arg.ListFieldIndex > 0 && arg.ListDelimiter == "" {
+		add(SeverityWarning, RuleListField, ".list_field_index", "list_field_index is ignored without list_delimiter; the whole row is used")
+	}
+	if arg.ListSkipHeader < 0 {
+		add(SeverityError, RuleListField, ".list_skip_header", "list_skip_header %d must not be negative", arg.ListSkipHeader)
+	}
+	if arg.Type != "list" && (arg.ListFieldIndex != 0 || arg.ListDelimiter != "" || arg.ListMulti) {
+		add(SeverityWarning, RuleListField, "", "list settings are ignored because %q is not type list", arg.Name)
+	}
+
+	if _, err := params.ParseCacheTTL(arg.CacheTTL); err != nil {
+		add(SeverityError, RuleInvalidArg, ".cache_ttl", "%v", err)
+	}
+	if err := params.ValidateListJoin(arg); err != nil {
+		add(SeverityError, RuleInvalidArg, ".list_quote", "%v", err)
+	}
+	if err := params.ValidateCommandOptions(arg); err != nil {
+		add(SeverityError, RuleInvalidArg, "", "%v", err)
+	}
+	if err := params.CheckRules(params.ValidationFromArg(arg)); err != nil {
+		add(SeverityError, RuleInvalidArg, ".validate", "%v", err)
+	}
+	if arg.Type == "secret" && arg.Default != "" {
+		add(SeverityWarning, RuleInvalidArg, ".default", "%q is type secret but has a default stored in plain text; prefer secret_env or secret_cmd", arg.Name)
+	}
+	return issues
+}
+
+// unknownKeys reports keys in a decoded workflow file that do not map to
+// any field, such as a misspelt dynamic_command.
+func unknownKeys(raw map[string]any) []Issue {
+	var issues []Issue
+	check := func(field string, m map[string]any, known map[string]bool) {
+		for _, key := range sortedKeys(m) {
+			if !known[key] {
+				issues = append(issues, Issue{Field: strings.TrimPrefix(field+"."+key, "."), Severity: SeverityError,
+					Rule: RuleUnknownKey, Message: fmt.Sprintf("unknown key %q", key)})
+			}
+		}
+	}
+	check("", raw, workflowKeys)
+	for i, a := range asList(raw["args"]) {
+		arg, ok := a.(map[string]any)
+		if !ok {
+			continue
+		}
+		field := fmt.Sprintf("args[%d]", i)
+		check(field, arg, argKeys)
+		if v, ok := arg["validate"].(map[string]any); ok {
+			check(field+".validate", v, validationKeys)
+		}
+	}
+	for i, s := range asList(raw["steps"]) {
+		if step, ok := s.(map[string]any); ok {
+			check(fmt.Sprintf("steps[%d]", i), step, stepKeys)
+		}
+	}
+	return issues
+}
+
+var (
+	workflowKeys   = yamlKeys(reflect.TypeOf(store.Workflow{}))
+	argKeys        = yamlKeys(reflect.TypeOf(store.Arg{}))
+	stepKeys       = yamlKeys(reflect.TypeOf(store.Step{}))
+	validationKeys = yamlKeys(reflect.TypeOf(store.Validation{}))
+)
+
+// yamlKeys returns the YAML keys a struct type decodes.
+func yamlKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// duplicateNames reports workflow names defined by more than one file.
+func duplicateNames(names map[string][]string) []Issue {
+	var issues []Issue
+	for _, name := range sortedKeys(names) {
+		files := names[name]
+		if len(files) < 2 {
+			continue
+		}
+		for _, f := range files {
+			var others []string
+			for _, o := range files {
+				if o != f {
+					others = append(others, o)
+				}
+			}
+			issues = append(issues, Issue{File: f, Workflow: name, Field: "name", Severity: SeverityError, Rule: RuleDuplicateName,
+				Message: fmt.Sprintf("name %q is also used by %s", name, strings.Join(others, ", "))})
+		}
+	}
+	return issues
+}
+
+func asList(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fredriklanga/wf/internal/store"
+)
+
+// rules returns "field rule" for each issue, for compact assertions.
+func rules(issues []Issue) []string {
+	out := make([]string, len(issues))
+	for i, is := range issues {
+		out[i] = is.Field + " " + is.Rule
+	}
+	return out
+}
+
+func TestFile_CleanWorkflow(t *testing.T) {
+	data := []byte(`name: deploy
+command: kubectl apply -n {{ns}} -f {{file |> basename}}
+args:
+  - name: ns
+    type: enum
+    options: [dev, prod]
+    default: dev
+  - name: file
+`)
+	issues, w := File("deploy.yaml", data)
+	assert.Empty(t, issues)
+	require.NotNil(t, w)
+	assert.Equal(t, "deploy", w.Name)
+}
+
+func TestFile_UnknownKeys(t *testing.T) {
+	data := []byte(`name: deploy
+command: echo {{branch}}
+descripton: typo
+args:
+  - name: branch
+    dynamic_command: git branch
+    validate:
+      kind: int
+      maximum: 3
+steps_: []
+`)
+	issues, _ := File("deploy.yaml", data)
+	assert.ElementsMatch(t, []string{
+		"descripton unknown-key",
+		"steps_ unknown-key",
+		"args[0].dynamic_command unknown-key",
+		"args[0].validate.maximum unknown-key",
+	}, rules(issues))
+	for _, is := range issues {
+		assert.Equal(t, "deploy.yaml", is.File)
+		assert.Equal(t, "deploy", is.Workflow)
+		assert.Equal(t, SeverityError, is.Severity)
+	}
+}
+
+func TestFile_ParseError(t *testing.T) {
+	issues, w := File("bad.yaml", []byte("name: [unterminated"))
+	require.Len(t, issues, 1)
+	assert.Equal(t, RuleParse, issues[0].Rule)
+	assert.Nil(t, w)
+}
+
+func TestFile_NewerSchemaVersion(t *testing.T) {
+	issues, _ := File("new.yaml", []byte("version: 99\nname: x\ncommand: ls\n"))
+	assert.Equal(t, []string{"version schema-version"}, rules(issues))
+}
+
+func TestWorkflow_ArgsAndPlaceholders(t *testing.T) {
+	w := store.Workflow{
+		Name:    "deploy",
+		Command: "deploy {{env|dev|prod}} {{region}}",
+		Args: []store.Arg{
+			{Name: "env", Default: "staging"},
+			{Name: "unused"},
+		},
+	}
+	assert.ElementsMatch(t, []string{
+		"args[1] unused-arg",
+		"args missing-arg",
+		"args[0].default enum-default",
+	}, rules(Workflow(w)))
+}
+
+func TestWorkflow_StepAndConditionNamesCountAsUsed(t *testing.T) {
+	w := store.Workflow{
+		Name: "release",
+		Steps: []store.Step{
+			{Command: "make build"},
+			{Command: "make publish", When: "publish"},
+		},
+		Args: []store.Arg{{Name: "publish"}},
+	}
+	assert.Empty(t, Workflow(w))
+}
+
+func TestWorkflow_ArgChecks(t *testing.T) {
+	w := store.Workflow{
+		Name:    "x",
+		Command: "run {{a}} {{b}} {{c}} {{d}} {{e}} {{f}}",
+		Args: []store.Arg{
+			{Name: "a", Type: "enum", Options: []string{"x", "y"}, Default: "z"},
+			{Name: "b", Type: "list", ListCmd: "ls", ListFieldIndex: -1},
+			{Name: "c", Type: "list", ListCmd: "ls", ListFieldIndex: 2},
+			{Name: "d", Type: "colour"},
+			{Name: "e", Type: "dynamic", DynamicCmd: "ls", CacheTTL: "soon"},
+			{Name: "f", Type: "enum"},
+			{Name: "a"},
+		},
+	}
+	assert.ElementsMatch(t, []string{
+		"args[0].default enum-default",
+		"args[1].list_field_index list-field",
+		"args[2].list_field_index list-field",
+		"args[3].type invalid-arg",
+		"args[4].cache_ttl invalid-arg",
+		"args[5].options invalid-arg",
+		"args[6].name duplicate-arg",
+	}, rules(Workflow(w)))
+}
+
+func TestWorkflow_TemplateErrors(t *testing.T) {
+	w := store.Workflow{Name: "x", Command: "echo {{a |> shout}} {{@nope}}", Args: []store.Arg{{Name: "a"}}}
+	assert.Equal(t, []string{"command template", "command template"}, rules(Workflow(w)))
+
+	assert.Equal(t, []string{"name required", "command required"}, rules(Workflow(store.Workflow{})))
+}
+
+func TestPath_DuplicateNamesAcrossFolders(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		p := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+	write("infra/deploy.yaml", "name: deploy\ncommand: make deploy\n")
+	write("web/deploy.yml", "name: deploy\ncommand: npm run deploy\n")
+	write("web/build.yaml", "name: build\ncommand: npm run build\n")
+	write(".github/workflows/ci.yaml", "on: push\n")
+	write("README.md", "# workflows\n")
+
+	report, err := Path(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Files)
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, RuleDuplicateName, report.Issues[0].Rule)
+	assert.Equal(t, "infra/deploy.yaml", report.Issues[0].File)
+	assert.Contains(t, report.Issues[0].Message, "web/deploy.yml")
+	assert.Equal(t, 2, report.Count(SeverityError))
+	assert.Equal(t, 0, report.Count(SeverityWarning))
+}
+
+func TestPath_SingleFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "x.yaml")
+	require.NoError(t, os.WriteFile(p, []byte("name: x\ncommand: ls {{dir}}\n"), 0644))
+
+	report, err := Path(p)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Files)
+	assert.Equal(t, []string{"args missing-arg"}, rules(report.Issues))
+	assert.Equal(t, p, report.Issues[0].File)
+}
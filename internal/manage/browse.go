@@ -511,7 +511,7 @@ func (b *BrowseModel) updatePreviewContent() {
 	var parts []string
 
 	// Command
-	parts = append(parts, highlight.Shell(wf.Command, b.tokenStyles))
+	parts = append(parts, highlight.Shell(wf.Template(), b.tokenStyles))
 
 	// Folder (derived from name)
 	if idx := strings.LastIndex(wf.Name, "/"); idx >= 0 {
@@ -25,6 +25,7 @@ type dialogResultMsg struct {
 	dtype     dialogType
 	confirmed bool
 	data      map[string]string // key-value pairs (e.g., "name", "folder")
+	values    map[string]string // filled param values (execute dialog only)
 }
 
 // DialogModel manages an overlay dialog with optional text input or list selection.
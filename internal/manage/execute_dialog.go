@@ -1,21 +1,22 @@
 package manage
 
 import (
-	"context"
 	"errors"
 	"fmt"
-	"os/exec"
+	"os"
 	"strconv"
 	"strings"
-	"time"
 	"unicode"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/fredriklanga/wf/internal/cmdcache"
 	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/runner"
 	"github.com/fredriklanga/wf/internal/store"
 	"github.com/fredriklanga/wf/internal/template"
+	"github.com/fredriklanga/wf/internal/usage"
 	"github.com/sahilm/fuzzy"
 )
 
@@ -42,10 +43,18 @@ const (
 
 type executeDialogDynamicMsg struct {
 	paramIndex int
+	command    string
 	options    []string
 	err        error
 }
 
+// executeDialogSecretMsg carries the result of a secret param's secret_cmd.
+type executeDialogSecretMsg struct {
+	paramIndex int
+	value      string
+	err        error
+}
+
 type ExecuteDialogModel struct {
 	workflow store.Workflow
 	phase    executePhase
@@ -58,14 +67,22 @@ type ExecuteDialogModel struct {
 	paramLoading      []bool
 	paramFailed       []bool
 	paramListStates   []executeDialogListState
+	paramRecent       [][]string // remembered values per param, newest first
+	paramRecentCursor []int
+	paramInvalid      []bool            // failed validation on the last submit attempt
+	sources           parammeta.Sources // commands behind dynamic/list params
 	focusedParam      int
 
 	renderedCommand string
+	renderedDisplay string // renderedCommand with secrets masked
+	renderErr       error  // why the command cannot be rendered, e.g. an invalid when:
 	actionCursor    int
 	actions         []string
 
 	width int
 	theme Theme
+
+	cache *cmdcache.Cache // dynamic/list command output; nil disables caching
 }
 
 type executeDialogListState struct {
@@ -80,10 +97,22 @@ type executeDialogListState struct {
 	loadErrDetail   string
 	showErrorDetail bool
 	emptyAfterSkip  bool
+	multi           bool            // several rows can be chosen with space
+	chosen          map[string]bool // raw rows chosen in multi mode
 }
 
 func NewExecuteDialog(wf store.Workflow, width int, theme Theme) ExecuteDialogModel {
-	params := parammeta.OverlayMetadata(wf.Command, wf.Args)
+	return newExecuteDialog(wf, width, theme, nil, nil)
+}
+
+// newExecuteDialog builds the dialog, prefilling params with values
+// remembered in u and showing dynamic and list output cached in cache
+// (either may be nil).
+func newExecuteDialog(wf store.Workflow, width int, theme Theme, u *usage.State, cache *cmdcache.Cache) ExecuteDialogModel {
+	params := parammeta.ForWorkflow(wf)
+	recent := parammeta.ApplyRecent(params, func(name string) []string {
+		return u.RecentValues(wf.Name, name)
+	})
 
 	d := ExecuteDialogModel{
 		workflow: wf,
@@ -95,11 +124,12 @@ func NewExecuteDialog(wf store.Workflow, width int, theme Theme) ExecuteDialogMo
 		},
 		width: width,
 		theme: theme,
+		cache: cache,
 	}
 
 	if len(params) == 0 {
 		d.phase = phaseActionMenu
-		d.renderedCommand = template.Render(wf.Command, nil)
+		d.renderCommand()
 		return d
 	}
 
@@ -111,6 +141,11 @@ func NewExecuteDialog(wf store.Workflow, width int, theme Theme) ExecuteDialogMo
 	d.paramLoading = make([]bool, len(params))
 	d.paramFailed = make([]bool, len(params))
 	d.paramListStates = make([]executeDialogListState, len(params))
+	d.paramRecent = recent
+	d.paramRecentCursor = make([]int, len(params))
+	d.paramInvalid = make([]bool, len(params))
+	workDir, _ := os.Getwd()
+	d.sources = parammeta.NewSources(params, cache, workDir)
 
 	defaultStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("242"))
 	for i, p := range params {
@@ -135,11 +170,20 @@ func NewExecuteDialog(wf store.Workflow, width int, theme Theme) ExecuteDialogMo
 			if len(p.Options) > 0 {
 				ti.SetValue(p.Options[defIdx])
 			}
-		case template.ParamDynamic:
-			d.paramLoading[i] = true
-			ti.Placeholder = "Loading..."
+		case template.ParamSecret:
+			ti.EchoMode = textinput.EchoPassword
+			ti.EchoCharacter = '•'
+			if v, ok := parammeta.SecretFromEnv(p); ok {
+				ti.SetValue(v)
+			} else if p.SecretCmd != "" {
+				d.paramLoading[i] = true
+				ti.Placeholder = "Resolving..."
+			} else if p.Default != "" {
+				ti.SetValue(p.Default)
+			}
 		case template.ParamList:
-			d.paramListStates[i] = newExecuteDialogListState(p)
+			d.paramListStates[i] = newExecuteDialogListState()
+			d.paramListStates[i].multi = p.ListMulti
 			if p.Default != "" {
 				ti.SetValue(p.Default)
 				ti.TextStyle = defaultStyle
@@ -156,6 +200,8 @@ func NewExecuteDialog(wf store.Workflow, width int, theme Theme) ExecuteDialogMo
 
 		d.paramInputs[i] = ti
 	}
+
+	d.sources.Init(&d)
 	d.focusParam(0)
 
 	return d
@@ -171,55 +217,71 @@ func (d ExecuteDialogModel) Init() tea.Cmd {
 func (d ExecuteDialogModel) InitCmds() []tea.Cmd {
 	var cmds []tea.Cmd
 	for i, p := range d.params {
-		if p.Type != template.ParamDynamic {
-			continue
-		}
 		idx := i
-		dynCmd := p.DynamicCmd
-		cmds = append(cmds, func() tea.Msg {
-			return executeDialogDynamic(idx, dynCmd)
-		})
+		switch {
+		case p.Type == template.ParamDynamic || p.Type == template.ParamList:
+			if cmd := d.sourceCmd(i); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		case p.Type == template.ParamSecret && d.paramLoading[i]:
+			param := p
+			cmds = append(cmds, func() tea.Msg {
+				v, err := parammeta.SecretFromCmd(param)
+				return executeDialogSecretMsg{paramIndex: idx, value: v, err: err}
+			})
+		}
 	}
 	return cmds
 }
 
-func executeDialogDynamic(paramIndex int, command string) executeDialogDynamicMsg {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
-	output, err := cmd.Output()
+func executeDialogDynamic(paramIndex int, command string, opts template.CommandOptions) executeDialogDynamicMsg {
+	msg := executeDialogDynamicMsg{paramIndex: paramIndex, command: command}
+	options, err := parammeta.RunDynamicCommand(command, opts)
 	if err != nil {
-		return executeDialogDynamicMsg{paramIndex: paramIndex, err: fmt.Errorf("dynamic command failed: %w", err)}
+		msg.err = err
+		return msg
 	}
-
-	options := strings.FieldsFunc(string(output), func(r rune) bool { return r == '\n' || r == '\r' })
-	filtered := make([]string, 0, len(options))
-	for i := range options {
-		trimmed := strings.TrimSpace(options[i])
-		if trimmed != "" {
-			filtered = append(filtered, trimmed)
-		}
-	}
-	if len(filtered) == 0 {
-		return executeDialogDynamicMsg{paramIndex: paramIndex, err: fmt.Errorf("dynamic command returned no output")}
+	if len(options) == 0 {
+		msg.err = fmt.Errorf("dynamic command returned no output")
+		return msg
 	}
-	return executeDialogDynamicMsg{paramIndex: paramIndex, options: filtered}
+	msg.options = options
+	return msg
 }
 
-func newExecuteDialogListState(p template.Param) executeDialogListState {
+func newExecuteDialogListState() executeDialogListState {
 	filter := textinput.New()
 	filter.Placeholder = "Filter rows..."
 	filter.CharLimit = 256
 	filter.Prompt = "filter> "
 
-	state := executeDialogListState{filterInput: filter}
-	state.load(p)
-	return state
+	return executeDialogListState{filterInput: filter}
+}
+
+// waitFor empties the list while params referenced by its command have no
+// value yet.
+func (s *executeDialogListState) waitFor(names []string) {
+	*s = executeDialogListState{filterInput: s.filterInput, multi: s.multi}
+	s.loadErrShort = fmt.Sprintf("fill %s first", strings.Join(names, ", "))
+}
+
+// setRows replaces the selectable rows, keeping the current filter and any
+// chosen rows that are still present.
+func (s *executeDialogListState) setRows(rows []parammeta.ListRow) {
+	chosen := s.chosen
+	*s = executeDialogListState{filterInput: s.filterInput, multi: s.multi}
+	s.allRows = append([]parammeta.ListRow(nil), rows...)
+	for _, row := range s.allRows {
+		if chosen[row.Raw] {
+			s.toggleRow(row.Raw)
+		}
+	}
+	s.applyFilter()
 }
 
 func (s *executeDialogListState) load(p template.Param) {
-	source, err := parammeta.LoadListSource(p.ListCmd, p.ListSkipHeader)
+	*s = executeDialogListState{filterInput: s.filterInput, multi: s.multi}
+	source, err := parammeta.LoadListSourceWith(p.ListCmd, p.ListSkipHeader, parammeta.EffectiveCommandOptions(p))
 	if err != nil {
 		var sourceErr *parammeta.ListSourceError
 		if errors.As(err, &sourceErr) {
@@ -334,10 +396,34 @@ func (s *executeDialogListState) updateFilter(msg tea.KeyMsg) tea.Cmd {
 	return cmd
 }
 
+// toggle chooses or unchooses the row under the cursor in multi mode.
+func (s *executeDialogListState) toggle() {
+	if len(s.visibleRows) == 0 {
+		return
+	}
+	s.toggleRow(s.visibleRows[s.cursor].Raw)
+	s.numberBuffer = ""
+	s.parseError = ""
+}
+
+func (s *executeDialogListState) toggleRow(raw string) {
+	if s.chosen == nil {
+		s.chosen = make(map[string]bool)
+	}
+	if s.chosen[raw] {
+		delete(s.chosen, raw)
+		return
+	}
+	s.chosen[raw] = true
+}
+
 func (s *executeDialogListState) confirmSelection(p template.Param) bool {
 	if s.hasLoadError() {
 		return false
 	}
+	if s.multi && len(s.chosen) > 0 {
+		return s.confirmChosen(p)
+	}
 	if len(s.visibleRows) == 0 {
 		s.parseError = s.emptyMessage()
 		return false
@@ -361,6 +447,30 @@ func (s *executeDialogListState) confirmSelection(p template.Param) bool {
 	s.parseError = ""
 	s.numberBuffer = ""
 	s.confirmValue = value
+	if s.multi {
+		s.confirmValue = parammeta.JoinListValues([]string{value}, p.ListJoin, runner.Dialect().QuoteListItem)
+	}
+	return true
+}
+
+// confirmChosen joins the values of all chosen rows, in list order.
+func (s *executeDialogListState) confirmChosen(p template.Param) bool {
+	var values []string
+	for _, row := range s.allRows {
+		if !s.chosen[row.Raw] {
+			continue
+		}
+		value, err := parammeta.ExtractListValue(row.Raw, p.ListDelimiter, p.ListFieldIndex)
+		if err != nil {
+			s.parseError = err.Error()
+			s.confirmValue = ""
+			return false
+		}
+		values = append(values, value)
+	}
+	s.parseError = ""
+	s.numberBuffer = ""
+	s.confirmValue = parammeta.JoinListValues(values, p.ListJoin, runner.Dialect().QuoteListItem)
 	return true
 }
 
@@ -424,32 +534,59 @@ func (d ExecuteDialogModel) Update(msg tea.Msg) (ExecuteDialogModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case executeDialogDynamicMsg:
 		return d.handleDynamicResult(msg)
+	case executeDialogListMsg:
+		return d.handleListResult(msg)
+	case executeDialogSecretMsg:
+		return d.handleSecretResult(msg)
 	case tea.KeyMsg:
 		if d.phase == phaseActionMenu {
 			return d.updateActionMenu(msg)
 		}
-		return d.updateParamFill(msg)
+		prev := d.focusedParam
+		next, cmd := d.updateParamFill(msg)
+		if next.focusedParam == prev {
+			return next, cmd
+		}
+		// Leaving a param may change values that other params' commands
+		// reference.
+		refresh := next.refreshSources()
+		return next, tea.Batch(cmd, refresh)
 	}
 	return d, nil
 }
 
 func (d ExecuteDialogModel) handleDynamicResult(msg executeDialogDynamicMsg) (ExecuteDialogModel, tea.Cmd) {
 	i := msg.paramIndex
-	if i < 0 || i >= len(d.params) {
+	if !d.sources.Done(i, msg.command, msg.options, msg.err) {
 		return d, nil
 	}
 
 	d.paramLoading[i] = false
+
 	if msg.err != nil || len(msg.options) == 0 {
 		d.paramFailed[i] = true
 		d.paramInputs[i].Placeholder = d.params[i].Name
+		return d, d.refreshSources()
+	}
+
+	d.ShowSourceLines(i, msg.options)
+	return d, d.refreshSources()
+}
+
+func (d ExecuteDialogModel) handleSecretResult(msg executeDialogSecretMsg) (ExecuteDialogModel, tea.Cmd) {
+	i := msg.paramIndex
+	if i < 0 || i >= len(d.params) {
 		return d, nil
 	}
 
-	d.paramOptions[i] = msg.options
-	d.paramOptionCursor[i] = 0
-	d.paramTypes[i] = template.ParamEnum
-	d.paramInputs[i].SetValue(msg.options[0])
+	d.paramLoading[i] = false
+	d.paramInputs[i].Placeholder = d.params[i].Name
+	if msg.err != nil {
+		d.paramFailed[i] = true
+		return d, nil
+	}
+	d.paramInputs[i].SetValue(msg.value)
+	d.paramInputs[i].CursorEnd()
 	return d, nil
 }
 
@@ -465,6 +602,8 @@ func (d ExecuteDialogModel) updateParamFill(msg tea.KeyMsg) (ExecuteDialogModel,
 	case "shift+tab":
 		d.moveFocus(d.focusedParam - 1)
 		return d, nil
+	case "ctrl+r":
+		return d, d.forceRefresh(d.focusedParam)
 	}
 
 	if d.isListPickerParam(d.focusedParam) {
@@ -483,6 +622,9 @@ func (d ExecuteDialogModel) updateParamFill(msg tea.KeyMsg) (ExecuteDialogModel,
 			}
 			return d, nil
 		}
+		if d.cycleRecent(-1) {
+			return d, nil
+		}
 	case "down":
 		if d.isListParam(d.focusedParam) {
 			opts := d.paramOptions[d.focusedParam]
@@ -494,11 +636,12 @@ func (d ExecuteDialogModel) updateParamFill(msg tea.KeyMsg) (ExecuteDialogModel,
 			}
 			return d, nil
 		}
+		if d.cycleRecent(1) {
+			return d, nil
+		}
 	case "enter":
 		if d.focusedParam == len(d.paramInputs)-1 || d.allParamsFilled() {
-			d.phase = phaseActionMenu
-			d.actionCursor = 0
-			d.renderedCommand = d.liveRender()
+			d.submit()
 			return d, nil
 		}
 		d.moveFocus(d.focusedParam + 1)
@@ -530,13 +673,18 @@ func (d ExecuteDialogModel) updateListParamFill(msg tea.KeyMsg) (ExecuteDialogMo
 	case "down":
 		state.moveCursor(1)
 		return d, nil
+	case " ":
+		if state.multi {
+			state.toggle()
+			return d, nil
+		}
+		cmd := state.updateFilter(msg)
+		return d, cmd
 	case "enter":
 		if state.hasConfirmation() {
 			d.paramInputs[d.focusedParam].SetValue(state.acceptConfirmedValue())
 			if d.focusedParam == len(d.paramInputs)-1 || d.allParamsFilled() {
-				d.phase = phaseActionMenu
-				d.actionCursor = 0
-				d.renderedCommand = d.liveRender()
+				d.submit()
 				return d, nil
 			}
 			d.moveFocus(d.focusedParam + 1)
@@ -565,6 +713,64 @@ func (d ExecuteDialogModel) updateListParamFill(msg tea.KeyMsg) (ExecuteDialogMo
 	}
 }
 
+// submit validates the filled params and moves on to the action menu. When
+// a value fails validation the dialog stays on the param form with the
+// first failing param focused.
+func (d *ExecuteDialogModel) submit() {
+	// An upstream value was edited without leaving the field; focus the
+	// dependent param so its options are reloaded before submitting.
+	if i := d.sources.Stale(d.values()); i >= 0 {
+		d.moveFocus(i)
+		return
+	}
+	idx, msgs := parammeta.FirstInvalid(d.params, func(i int) string {
+		return d.paramInputs[i].Value()
+	})
+	for i := range msgs {
+		d.paramInvalid[i] = msgs[i] != ""
+	}
+	if idx >= 0 {
+		d.moveFocus(idx)
+		return
+	}
+	if !d.renderCommand() {
+		// The preview shows why the command cannot be rendered.
+		return
+	}
+	d.phase = phaseActionMenu
+	d.actionCursor = 0
+}
+
+// renderCommand renders the command and its masked display form for the
+// action menu. On failure the error is kept in renderErr and false is
+// returned.
+func (d *ExecuteDialogModel) renderCommand() bool {
+	command, err := d.liveRender()
+	if err != nil {
+		d.renderErr = err
+		return false
+	}
+	display, err := d.maskedRender()
+	if err != nil {
+		d.renderErr = err
+		return false
+	}
+	d.renderedCommand, d.renderedDisplay, d.renderErr = command, display, nil
+	return true
+}
+
+// paramError returns the current validation message for a param that
+// failed the last submit attempt.
+func (d ExecuteDialogModel) paramError(i int) string {
+	if i >= len(d.paramInvalid) || !d.paramInvalid[i] {
+		return ""
+	}
+	if err := parammeta.Validate(d.params[i], d.paramInputs[i].Value()); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
 func (d ExecuteDialogModel) updateActionMenu(msg tea.KeyMsg) (ExecuteDialogModel, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
@@ -582,16 +788,23 @@ func (d ExecuteDialogModel) updateActionMenu(msg tea.KeyMsg) (ExecuteDialogModel
 		}
 		return d, nil
 	case "enter":
-		switch executeAction(d.actionCursor) {
+		action := executeAction(d.actionCursor)
+		if d.renderErr != nil && action != actionCancel {
+			return d, nil
+		}
+		switch action {
 		case actionCopy:
 			return d, func() tea.Msg {
 				return dialogResultMsg{
 					dtype:     dialogExecute,
 					confirmed: true,
 					data: map[string]string{
-						"action":  "copy",
-						"command": d.renderedCommand,
+						"action":   "copy",
+						"command":  d.renderedCommand,
+						"display":  d.renderedDisplay,
+						"workflow": d.workflow.Name,
 					},
+					values: d.values(),
 				}
 			}
 		case actionPasteToPrompt:
@@ -600,9 +813,12 @@ func (d ExecuteDialogModel) updateActionMenu(msg tea.KeyMsg) (ExecuteDialogModel
 					dtype:     dialogExecute,
 					confirmed: true,
 					data: map[string]string{
-						"action":  "paste",
-						"command": d.renderedCommand,
+						"action":   "paste",
+						"command":  d.renderedCommand,
+						"display":  d.renderedDisplay,
+						"workflow": d.workflow.Name,
 					},
+					values: d.values(),
 				}
 			}
 		default:
@@ -640,6 +856,49 @@ func (d ExecuteDialogModel) allParamsFilled() bool {
 	return true
 }
 
+// cycleRecent steps the focused text param through its remembered values.
+// It reports false when there is nothing to cycle through.
+func (d *ExecuteDialogModel) cycleRecent(delta int) bool {
+	i := d.focusedParam
+	if i < 0 || i >= len(d.paramRecent) || len(d.paramRecent[i]) == 0 {
+		return false
+	}
+	if d.paramTypes[i] != template.ParamText && !d.paramFailed[i] {
+		return false
+	}
+	recent := d.paramRecent[i]
+	cur := (d.paramRecentCursor[i] + delta + len(recent)) % len(recent)
+	d.paramRecentCursor[i] = cur
+	d.paramInputs[i].SetValue(recent[cur])
+	d.paramInputs[i].CursorEnd()
+	d.updateFocusedTextStyle()
+	return true
+}
+
+// valueNote labels a value that came from a remembered or declared default.
+func (d ExecuteDialogModel) valueNote(i int, value string) string {
+	s := d.theme.Styles()
+	if value == "" {
+		return ""
+	}
+	if len(d.paramRecent[i]) > 0 && value == d.paramRecent[i][0] {
+		return s.Dim.Render(" (last used)")
+	}
+	if value == d.params[i].Default {
+		return s.Dim.Render(" (default)")
+	}
+	return ""
+}
+
+// sourceNote shows whether a dynamic or list param's options came from the
+// cache or are being refreshed.
+func (d ExecuteDialogModel) sourceNote(i int) string {
+	if d.sources.Note(i) == "" {
+		return ""
+	}
+	return d.theme.Styles().Dim.Render(" (" + d.sources.Note(i) + ")")
+}
+
 func (d *ExecuteDialogModel) updateFocusedTextStyle() {
 	if d.focusedParam < 0 || d.focusedParam >= len(d.paramInputs) {
 		return
@@ -655,7 +914,18 @@ func (d *ExecuteDialogModel) updateFocusedTextStyle() {
 	d.paramInputs[d.focusedParam].TextStyle = lipgloss.NewStyle()
 }
 
-func (d ExecuteDialogModel) liveRender() string {
+func (d ExecuteDialogModel) liveRender() (string, error) {
+	return runner.Render(d.workflow, d.values())
+}
+
+// maskedRender renders the command with secret values masked, for display
+// and history.
+func (d ExecuteDialogModel) maskedRender() (string, error) {
+	return runner.RenderMasked(d.workflow, parammeta.MaskSecrets(d.params, d.values()))
+}
+
+// values returns the non-empty param input values keyed by name.
+func (d ExecuteDialogModel) values() map[string]string {
 	values := make(map[string]string)
 	for i, p := range d.params {
 		v := d.paramInputs[i].Value()
@@ -663,7 +933,7 @@ func (d ExecuteDialogModel) liveRender() string {
 			values[p.Name] = v
 		}
 	}
-	return template.Render(d.workflow.Command, values)
+	return values
 }
 
 func (d ExecuteDialogModel) View() string {
@@ -704,6 +974,16 @@ func (d ExecuteDialogModel) viewParamFill() string {
 		switch {
 		case d.paramTypes[i] == template.ParamDynamic && d.paramLoading[i]:
 			rows = append(rows, prefix+label+s.Dim.Render("Loading..."))
+		case d.paramTypes[i] == template.ParamDynamic && d.sources.Command(i) == "":
+			note := " (options load when you move on)"
+			if waiting := parammeta.Waiting(p, d.values()); len(waiting) > 0 {
+				note = " (fill " + strings.Join(waiting, ", ") + " first, or type manually)"
+			}
+			rows = append(rows, prefix+label+d.paramInputs[i].View()+s.Dim.Render(note))
+		case d.paramTypes[i] == template.ParamSecret && d.paramLoading[i]:
+			rows = append(rows, prefix+label+s.Dim.Render("Resolving..."))
+		case d.paramTypes[i] == template.ParamSecret && d.paramFailed[i]:
+			rows = append(rows, prefix+label+d.paramInputs[i].View()+s.Dim.Render(" (secret command failed, type manually)"))
 		case d.paramTypes[i] == template.ParamDynamic && d.paramFailed[i]:
 			rows = append(rows, prefix+label+d.paramInputs[i].View()+s.Dim.Render(" (command failed, type manually)"))
 		case d.isListParam(i):
@@ -713,7 +993,7 @@ func (d ExecuteDialogModel) viewParamFill() string {
 			if cur >= 0 && cur < len(opts) {
 				value = opts[cur]
 			}
-			rows = append(rows, prefix+label+s.Highlight.Render(value))
+			rows = append(rows, prefix+label+s.Highlight.Render(value)+d.valueNote(i, value)+d.sourceNote(i))
 			if isFocused {
 				maxShow := 5
 				start := 0
@@ -762,28 +1042,34 @@ func (d ExecuteDialogModel) viewParamFill() string {
 			if state.hasConfirmation() {
 				valueStyle = s.Highlight
 			}
-			desc := ""
-			if p.Default != "" && value == p.Default {
-				desc = s.Dim.Render(" (default)")
-			}
-			rows = append(rows, prefix+label+valueStyle.Render(value)+desc)
+			rows = append(rows, prefix+label+valueStyle.Render(value)+d.valueNote(i, d.paramInputs[i].Value())+d.sourceNote(i))
 			if isFocused {
 				rows = append(rows, d.renderListParamLines(state)...)
 			}
 		default:
-			desc := ""
-			if p.Default != "" && d.paramInputs[i].Value() == p.Default {
-				desc = s.Dim.Render(" (default)")
+			rows = append(rows, prefix+label+d.paramInputs[i].View()+d.valueNote(i, d.paramInputs[i].Value()))
+			if isFocused && len(d.paramRecent[i]) > 1 {
+				rows = append(rows, "    "+s.Dim.Render("recent: "+strings.Join(d.paramRecent[i], " · ")))
 			}
-			rows = append(rows, prefix+label+d.paramInputs[i].View()+desc)
+		}
+
+		if msg := d.paramError(i); msg != "" {
+			errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+			rows = append(rows, "    "+errStyle.Render("✗ "+msg))
 		}
 	}
 
+	hint := "[tab] next  [shift+tab] prev  [up/down] select  [enter] submit"
 	if d.isListPickerParam(d.focusedParam) {
-		rows = append(rows, "", s.Dim.Render("type to filter  [1-9] jump  [enter] confirm  [tab] next  [esc] cancel"))
-	} else {
-		rows = append(rows, "", s.Dim.Render("[tab] next  [shift+tab] prev  [up/down] select  [enter] submit  [esc] cancel"))
+		hint = "type to filter  [1-9] jump  [enter] confirm  [tab] next"
+		if d.paramListStates[d.focusedParam].multi {
+			hint = "type to filter  [space] toggle  [enter] confirm  [tab] next"
+		}
 	}
+	if d.sources.Command(d.focusedParam) != "" {
+		hint += "  [ctrl+r] refresh"
+	}
+	rows = append(rows, "", s.Dim.Render(hint+"  [esc] cancel"))
 	return lipgloss.JoinVertical(lipgloss.Left, rows...)
 }
 
@@ -842,18 +1128,36 @@ func (d ExecuteDialogModel) renderListParamLines(state executeDialogListState) [
 			rowStyle = s.Highlight
 		}
 		label := fmt.Sprintf("%d. %s", i+1, truncateWithEllipsis(state.visibleRows[i].Raw, executeDialogListPreviewMaxWidth))
+		if state.multi {
+			mark := "[ ] "
+			if state.chosen[state.visibleRows[i].Raw] {
+				mark = "[x] "
+			}
+			label = mark + label
+		}
 		lines = append(lines, prefix+rowStyle.Render(label))
 	}
 	if len(state.visibleRows) > executeDialogListVisibleMaxRows {
 		lines = append(lines, "    "+s.Dim.Render(fmt.Sprintf("showing %d/%d visible rows", end-start, len(state.visibleRows))))
 	}
+	if state.multi && len(state.chosen) > 0 {
+		lines = append(lines, "    "+s.Dim.Render(fmt.Sprintf("%d chosen", len(state.chosen))))
+	}
 	return lines
 }
 
 func (d ExecuteDialogModel) renderPreview() string {
 	s := d.theme.Styles()
 	label := s.Dim.Render("Command preview")
-	command := d.liveRender()
+	command, err := d.maskedRender()
+	if err != nil {
+		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		rows := []string{label, "  " + errStyle.Render("✗ "+err.Error())}
+		for len(rows) < executePreviewMinRows {
+			rows = append(rows, "")
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	}
 	command = strings.ReplaceAll(command, "\n", " ")
 	commandWidth := d.width - 10
 	if commandWidth < 20 {
@@ -921,8 +1225,13 @@ func truncateWithEllipsis(s string, maxLen int) string {
 
 func (d ExecuteDialogModel) viewActionMenu() string {
 	s := d.theme.Styles()
+	command := s.Highlight.Render(d.renderedDisplay)
+	if d.renderErr != nil {
+		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		command = errStyle.Render("✗ " + d.renderErr.Error())
+	}
 	rows := []string{
-		s.Highlight.Render(d.renderedCommand),
+		command,
 		"",
 	}
 
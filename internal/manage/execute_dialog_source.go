@@ -0,0 +1,157 @@
+package manage
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// executeDialogListMsg carries the result of a list param's command run in
+// the background.
+type executeDialogListMsg struct {
+	paramIndex int
+	command    string
+	source     parammeta.ListSource
+	err        error
+}
+
+// sourceCmd returns the command that loads or refreshes param i in the
+// background, or nil when nothing needs to run.
+func (d ExecuteDialogModel) sourceCmd(i int) tea.Cmd {
+	p := d.params[i]
+	command := d.sources.Command(i)
+	switch {
+	case p.Type == template.ParamDynamic && (d.paramLoading[i] || d.sources.Refreshing(i)):
+		opts := parammeta.EffectiveCommandOptions(p)
+		return func() tea.Msg {
+			return executeDialogDynamic(i, command, opts)
+		}
+	case p.Type == template.ParamList && d.sources.Refreshing(i):
+		skip := p.ListSkipHeader
+		opts := parammeta.EffectiveCommandOptions(p)
+		return func() tea.Msg {
+			source, err := parammeta.LoadListSourceWith(command, skip, opts)
+			return executeDialogListMsg{paramIndex: i, command: command, source: source, err: err}
+		}
+	}
+	return nil
+}
+
+// forceRefresh re-runs param i's command, bypassing the cache, while its
+// current options stay visible.
+func (d *ExecuteDialogModel) forceRefresh(i int) tea.Cmd {
+	if i < 0 || i >= len(d.params) || d.sources.Command(i) == "" || d.paramLoading[i] || d.sources.Refreshing(i) {
+		return nil
+	}
+	switch d.params[i].Type {
+	case template.ParamDynamic:
+		if d.paramFailed[i] {
+			d.paramFailed[i] = false
+			d.paramLoading[i] = true
+			d.paramInputs[i].SetValue("")
+			return d.sourceCmd(i)
+		}
+	case template.ParamList:
+	default:
+		return nil
+	}
+	d.sources.StartRefresh(i)
+	return d.sourceCmd(i)
+}
+
+// ShowSourceLines shows command output as param i's options. A dynamic
+// param keeps its current value when it is still offered, and otherwise
+// preselects its default.
+func (d *ExecuteDialogModel) ShowSourceLines(i int, lines []string) {
+	switch d.params[i].Type {
+	case template.ParamDynamic:
+		d.paramLoading[i] = false
+		d.paramFailed[i] = false
+		cur := -1
+		for j, opt := range lines {
+			if opt == d.paramInputs[i].Value() {
+				cur = j
+				break
+			}
+		}
+		if cur < 0 {
+			cur = 0
+			for j, opt := range lines {
+				if opt == d.params[i].Default {
+					cur = j
+					break
+				}
+			}
+		}
+		d.paramOptions[i] = lines
+		d.paramOptionCursor[i] = cur
+		d.paramTypes[i] = template.ParamEnum
+		d.paramInputs[i].SetValue(lines[cur])
+	case template.ParamList:
+		d.paramListStates[i].setRows(parammeta.RowsFromLines(lines))
+	}
+}
+
+// LoadSource starts a dynamic param loading in the background, or loads a
+// list param's rows right away.
+func (d *ExecuteDialogModel) LoadSource(i int, command string) []string {
+	p := d.params[i]
+	switch p.Type {
+	case template.ParamDynamic:
+		d.paramLoading[i] = true
+		d.paramInputs[i].Placeholder = "Loading..."
+	case template.ParamList:
+		p.ListCmd = command
+		state := &d.paramListStates[i]
+		state.load(p)
+		if !state.hasLoadError() && len(state.allRows) > 0 {
+			return parammeta.ListSource{Rows: state.allRows}.Lines()
+		}
+	}
+	return nil
+}
+
+// ResetSource clears param i's value and options.
+func (d *ExecuteDialogModel) ResetSource(i int) {
+	d.paramInputs[i].SetValue("")
+	d.paramTypes[i] = d.params[i].Type
+	d.paramOptions[i] = nil
+	d.paramOptionCursor[i] = 0
+	d.paramFailed[i] = false
+	d.paramLoading[i] = false
+}
+
+// WaitForSource shows that list param i needs values for names first.
+func (d *ExecuteDialogModel) WaitForSource(i int, names []string) {
+	if d.params[i].Type == template.ParamList {
+		d.paramListStates[i].waitFor(names)
+	}
+}
+
+// SourceValue returns param i's current value.
+func (d *ExecuteDialogModel) SourceValue(i int) string {
+	return d.paramInputs[i].Value()
+}
+
+// handleListResult applies a background list refresh. On failure the
+// previous rows stay selectable.
+func (d ExecuteDialogModel) handleListResult(msg executeDialogListMsg) (ExecuteDialogModel, tea.Cmd) {
+	i := msg.paramIndex
+	if !d.sources.Done(i, msg.command, msg.source.Lines(), msg.err) || msg.err != nil {
+		return d, nil
+	}
+	d.paramListStates[i].setRows(msg.source.Rows)
+	return d, nil
+}
+
+// refreshSources re-runs dynamic and list commands whose referenced param
+// values changed since they last ran.
+func (d *ExecuteDialogModel) refreshSources() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, i := range d.sources.Refresh(d, d.values()) {
+		if cmd := d.sourceCmd(i); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return tea.Batch(cmds...)
+}
@@ -1,10 +1,15 @@
 package manage
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fredriklanga/wf/internal/cmdcache"
 	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/usage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -54,6 +59,37 @@ func TestExecuteDialogListSelectionConfirmsExtractedValue(t *testing.T) {
 	assert.Equal(t, "echo beta", dlg.renderedCommand)
 }
 
+func TestExecuteDialogListMultiSelectJoinsWithSeparatorAndQuotes(t *testing.T) {
+	wf := store.Workflow{
+		Name:    "rm",
+		Command: "git branch -D {{branches}}",
+		Args: []store.Arg{{
+			Name:          "branches",
+			Type:          "list",
+			ListCmd:       "printf 'main\nold work\nspike\n'",
+			ListMulti:     true,
+			ListSeparator: " ",
+			ListQuote:     "single",
+		}},
+	}
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	dlg := NewExecuteDialog(wf, 70, DefaultTheme())
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyDown})
+	dlg, _ = dlg.Update(space)
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyDown})
+	dlg, _ = dlg.Update(space)
+	assert.Contains(t, dlg.viewParamFill(), "[x] 2. old work")
+	assert.Contains(t, dlg.viewParamFill(), "[space] toggle")
+
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, dlg.viewParamFill(), "Will insert: 'old work' 'spike'")
+
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, phaseActionMenu, dlg.phase)
+	assert.Equal(t, "git branch -D 'old work' 'spike'", dlg.renderedCommand)
+}
+
 func TestExecuteDialogParseErrorAllowsRetry(t *testing.T) {
 	wf := store.Workflow{
 		Name:    "pods",
@@ -133,3 +169,165 @@ func TestExecuteDialogListConfirmationCommandResult(t *testing.T) {
 	require.Equal(t, phaseActionMenu, dlg.phase)
 	assert.Equal(t, "echo alpha prod", dlg.renderedCommand)
 }
+
+func TestExecuteDialogPrefillsAndCyclesRememberedValues(t *testing.T) {
+	off := false
+	wf := store.Workflow{
+		Name:    "deploy",
+		Command: "deploy {{env:dev}} {{token}}",
+		Args:    []store.Arg{{Name: "token", Remember: &off}},
+	}
+	u := usage.New(filepath.Join(t.TempDir(), "usage.json"))
+	u.RememberValues("deploy", map[string]string{"env": "staging", "token": "old"})
+	u.RememberValues("deploy", map[string]string{"env": "prod"})
+
+	dlg := newExecuteDialog(wf, 70, DefaultTheme(), u, nil)
+	assert.Equal(t, "prod", dlg.paramInputs[0].Value())
+	assert.Equal(t, "", dlg.paramInputs[1].Value(), "remember: false params are not prefilled")
+	view := dlg.viewParamFill()
+	assert.Contains(t, view, "(last used)")
+	assert.Contains(t, view, "recent: prod · staging")
+
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "staging", dlg.paramInputs[0].Value())
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "prod", dlg.paramInputs[0].Value(), "cycling wraps around")
+}
+
+func TestExecuteDialogSecretIsMaskedAndResolvedFromEnv(t *testing.T) {
+	t.Setenv("WF_TEST_TOKEN", "from-env-secret")
+	wf := store.Workflow{
+		Name:    "api",
+		Command: "curl -H 'X-Token: {{token}}' {{url}}",
+		Args:    []store.Arg{{Name: "token", Type: "secret", SecretEnv: "WF_TEST_TOKEN"}},
+	}
+
+	dlg := NewExecuteDialog(wf, 90, DefaultTheme())
+	assert.Equal(t, "from-env-secret", dlg.paramInputs[0].Value())
+
+	view := dlg.viewParamFill()
+	assert.NotContains(t, view, "from-env-secret", "neither the input nor the preview echoes the secret")
+	command, err := dlg.liveRender()
+	require.NoError(t, err)
+	assert.Contains(t, command, "from-env-secret")
+}
+
+func TestExecuteDialogShowsInvalidCondition(t *testing.T) {
+	wf := store.Workflow{
+		Name: "release",
+		Steps: []store.Step{
+			{Command: "make build"},
+			{Command: "make publish", When: "env == prod &&"},
+		},
+		Args: []store.Arg{{Name: "env", Default: "prod"}},
+	}
+
+	dlg := NewExecuteDialog(wf, 90, DefaultTheme())
+	assert.Contains(t, dlg.viewParamFill(), "step 2 when")
+
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, phaseParamFill, dlg.phase, "an unrenderable command is never offered")
+	assert.Error(t, dlg.renderErr)
+}
+
+func TestExecuteDialogSecretResolvedFromCommand(t *testing.T) {
+	wf := store.Workflow{
+		Name:    "api",
+		Command: "login {{token}}",
+		Args:    []store.Arg{{Name: "token", Type: "secret", SecretCmd: "echo cmd-secret"}},
+	}
+
+	dlg := NewExecuteDialog(wf, 70, DefaultTheme())
+	assert.True(t, dlg.paramLoading[0])
+	cmds := dlg.InitCmds()
+	require.Len(t, cmds, 1)
+
+	dlg, _ = dlg.Update(cmds[0]())
+	assert.False(t, dlg.paramLoading[0])
+	assert.Equal(t, "cmd-secret", dlg.paramInputs[0].Value())
+	assert.NotContains(t, dlg.viewParamFill(), "cmd-secret")
+}
+
+func TestExecuteDialogValidationFocusesFirstInvalidParam(t *testing.T) {
+	wf := store.Workflow{
+		Name:    "fetch",
+		Command: "curl {{url}} -o {{out}}",
+		Args: []store.Arg{
+			{Name: "url", Validate: &store.Validation{Kind: "url"}},
+			{Name: "out", Validate: &store.Validation{Required: true}},
+		},
+	}
+
+	dlg := NewExecuteDialog(wf, 80, DefaultTheme())
+	dlg.paramInputs[0].SetValue("example.com")
+	dlg.moveFocus(1)
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	assert.Equal(t, phaseParamFill, dlg.phase)
+	assert.Equal(t, 0, dlg.focusedParam)
+	view := dlg.viewParamFill()
+	assert.Contains(t, view, "must be a URL")
+	assert.Contains(t, view, "required")
+
+	dlg.paramInputs[0].SetValue("https://example.com")
+	dlg.paramInputs[1].SetValue("page.html")
+	dlg, _ = dlg.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, phaseActionMenu, dlg.phase)
+	assert.Equal(t, "curl https://example.com -o page.html", dlg.renderedCommand)
+}
+
+func TestExecuteDialogDependentDynamicRunsWithUpstreamValue(t *testing.T) {
+	wf := store.Workflow{
+		Name:    "deploy",
+		Command: "deploy --env {{env}} --region {{region}}",
+		Args: []store.Arg{
+			{Name: "region", Type: "dynamic", DynamicCmd: "echo {{env}}-east; echo {{env}}-west"},
+		},
+	}
+
+	dlg := NewExecuteDialog(wf, 90, DefaultTheme())
+	assert.Empty(t, dlg.InitCmds(), "region waits for env")
+	assert.Contains(t, dlg.viewParamFill(), "fill env first")
+
+	dlg.paramInputs[0].SetValue("prod")
+	dlg, cmd := dlg.Update(tea.KeyMsg{Type: tea.KeyTab})
+	require.NotNil(t, cmd)
+	assert.True(t, dlg.paramLoading[1])
+
+	// A result for an outdated command is ignored.
+	stale, _ := dlg.Update(executeDialogDynamicMsg{paramIndex: 1, command: "echo old", options: []string{"old"}})
+	assert.True(t, stale.paramLoading[1])
+
+	dlg, _ = dlg.Update(cmd())
+	assert.False(t, dlg.paramLoading[1])
+	assert.Equal(t, []string{"prod-east", "prod-west"}, dlg.paramOptions[1])
+	assert.Equal(t, "prod-east", dlg.paramInputs[1].Value())
+}
+
+func TestExecuteDialogListShowsExpiredCacheAndRefreshes(t *testing.T) {
+	wf := store.Workflow{
+		Name:    "logs",
+		Command: "kubectl logs {{pod}}",
+		Args:    []store.Arg{{Name: "pod", Type: "list", ListCmd: "printf 'api-1\\napi-2\\n'", CacheTTL: "1m"}},
+	}
+	cache := cmdcache.New(filepath.Join(t.TempDir(), "commands"))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, cache.Put("printf 'api-1\\napi-2\\n'", wd, []string{"old-pod"}, time.Now().Add(-time.Hour)))
+
+	dlg := newExecuteDialog(wf, 80, DefaultTheme(), nil, cache)
+	require.Len(t, dlg.paramListStates[0].allRows, 1)
+	assert.Equal(t, "old-pod", dlg.paramListStates[0].allRows[0].Raw)
+	assert.Contains(t, dlg.viewParamFill(), "cached, refreshing")
+
+	cmds := dlg.InitCmds()
+	require.Len(t, cmds, 1)
+	dlg, _ = dlg.Update(cmds[0]())
+	require.Len(t, dlg.paramListStates[0].allRows, 2)
+	assert.Equal(t, "api-1", dlg.paramListStates[0].allRows[0].Raw)
+	assert.NotContains(t, dlg.viewParamFill(), "cached")
+
+	e, ok := cache.Get("printf 'api-1\\napi-2\\n'", wd)
+	require.True(t, ok)
+	assert.Equal(t, []string{"api-1", "api-2"}, e.Lines)
+}
@@ -11,7 +11,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/fredriklanga/wf/internal/ai"
+	parammeta "github.com/fredriklanga/wf/internal/params"
 	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
 )
 
 // Validation errors for form fields.
@@ -73,6 +75,10 @@ type FormModel struct {
 	// Parameter editor (below metadata fields).
 	paramEditor ParamEditorModel
 
+	// Steps of a multi-step workflow. The form does not edit steps; they are
+	// carried through unchanged so saving never drops them.
+	steps []store.Step
+
 	// Which field is currently focused.
 	focused formFieldIndex
 
@@ -133,6 +139,7 @@ func NewFormModel(mode string, wf *store.Workflow, s store.Store, existingTags,
 		m.vals.tagInput = strings.Join(wf.Tags, ", ")
 
 		args = wf.Args
+		m.steps = wf.Steps
 	}
 
 	m.buildInputs()
@@ -448,12 +455,37 @@ func (m FormModel) validate() error {
 	if strings.ContainsAny(m.vals.name, "/\\") {
 		return errNameNoSlash
 	}
-	if strings.TrimSpace(m.vals.command) == "" {
+	if strings.TrimSpace(m.vals.command) == "" && len(m.steps) == 0 {
 		return errCommandRequired
 	}
+	wf := store.Workflow{
+		Command: strings.TrimSpace(m.vals.command),
+		Args:    m.paramEditor.ToArgs(),
+		Steps:   m.steps,
+	}
+	if err := parammeta.CheckDependencies(wf); err != nil {
+		return err
+	}
+	if err := checkPlaceholders(wf.Command); err != nil {
+		return err
+	}
+	for i, step := range wf.Steps {
+		if err := checkPlaceholders(step.Command); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
 	return nil
 }
 
+// checkPlaceholders reports transforms and context variables in command
+// that cannot be rendered.
+func checkPlaceholders(command string) error {
+	if err := template.CheckTransforms(command); err != nil {
+		return err
+	}
+	return template.CheckContextVars(command)
+}
+
 // saveWorkflow builds a Workflow from form fields and persists it via Store.
 func (m FormModel) saveWorkflow() tea.Cmd {
 	v := m.vals
@@ -461,6 +493,7 @@ func (m FormModel) saveWorkflow() tea.Cmd {
 	mode := m.mode
 	originalName := m.originalName
 	args := m.paramEditor.ToArgs()
+	steps := m.steps
 
 	return func() tea.Msg {
 		// Parse tags from comma-separated input.
@@ -480,6 +513,7 @@ func (m FormModel) saveWorkflow() tea.Cmd {
 			Description: strings.TrimSpace(v.description),
 			Tags:        tags,
 			Args:        args,
+			Steps:       steps,
 		}
 
 		// If editing and name changed, delete the old workflow first.
@@ -573,6 +607,9 @@ func (m FormModel) View() string {
 	// Use PaddingLeft to indent all lines of the multi-line textarea consistently.
 	cmdView := lipgloss.NewStyle().PaddingLeft(2).Render(m.cmdInput.View())
 	rows = append(rows, cmdView)
+	if len(m.steps) > 0 {
+		rows = append(rows, labelStyle.Render(fmt.Sprintf("  + %d steps (edit the YAML file to change steps)", len(m.steps))))
+	}
 
 	// Tags.
 	lbl = labelStyle
@@ -677,16 +714,26 @@ func (m *FormModel) updateCommandTemplateOnRename(oldName, newName string) {
 	}
 
 	// Match {{oldName}} or {{oldName:...}} or {{oldName|...}} or {{oldName!...}}
-	// The old name is escaped for regex safety.
-	pattern := `\{\{` + regexp.QuoteMeta(oldName) + `([}:!|][^}]*)?\}\}`
+	// or {{oldName |> ...}}. The old name is escaped for regex safety, and
+	// escaped \{{oldName}} is literal text, so it is left alone.
+	pattern := `\{\{` + regexp.QuoteMeta(oldName) + `([}:!| ][^}]*)?\}\}`
 	re := regexp.MustCompile(pattern)
 
-	updated := re.ReplaceAllStringFunc(cmd, func(match string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(cmd, -1) {
+		if loc[0] > 0 && cmd[loc[0]-1] == '\\' {
+			continue
+		}
 		// Extract the suffix after the old name (e.g., ":default", "|opt1|opt2", "!cmd").
-		inner := match[2 : len(match)-2] // strip {{ and }}
-		suffix := inner[len(oldName):]   // everything after the old name
-		return "{{" + newName + suffix + "}}"
-	})
+		inner := cmd[loc[0]+2 : loc[1]-2] // strip {{ and }}
+		suffix := inner[len(oldName):]    // everything after the old name
+		b.WriteString(cmd[last:loc[0]])
+		b.WriteString("{{" + newName + suffix + "}}")
+		last = loc[1]
+	}
+	b.WriteString(cmd[last:])
+	updated := b.String()
 
 	if updated != cmd {
 		m.cmdInput.SetValue(updated)
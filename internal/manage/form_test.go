@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	parammeta "github.com/fredriklanga/wf/internal/params"
 	"github.com/fredriklanga/wf/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -318,6 +319,36 @@ func TestFormModelValidation(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestFormModelValidationRejectsParamCycle(t *testing.T) {
+	s := &mockStore{}
+	m := NewFormModel("create", nil, s, nil, nil, DefaultTheme())
+	m.vals.name = "deploy"
+	m.vals.command = "deploy {{service}}"
+	m.paramEditor = NewParamEditor([]store.Arg{
+		{Name: "service", Type: "dynamic", DynamicCmd: "services {{region}}"},
+		{Name: "region", Type: "dynamic", DynamicCmd: "regions {{service}}"},
+	}, DefaultTheme(), 80)
+
+	err := m.validate()
+	var cycle *parammeta.CycleError
+	require.ErrorAs(t, err, &cycle)
+	assert.Contains(t, err.Error(), "service → region → service")
+}
+
+func TestFormModelValidationRejectsUnknownTransform(t *testing.T) {
+	s := &mockStore{}
+	m := NewFormModel("create", nil, s, nil, nil, DefaultTheme())
+	m.vals.name = "tag"
+	m.vals.command = "git tag {{version |> shout}}"
+
+	err := m.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown transform "shout"`)
+
+	m.vals.command = `git tag {{version |> trimprefix "v"}}`
+	assert.NoError(t, m.validate())
+}
+
 func TestFormSpinnerAdvancesOnTickForFieldLoading(t *testing.T) {
 	s := &mockStore{}
 	m := NewFormModel("create", nil, s, nil, nil, DefaultTheme())
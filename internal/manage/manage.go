@@ -1,12 +1,19 @@
 package manage
 
 import (
+	"fmt"
+	"os"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
 
+	"github.com/fredriklanga/wf/internal/cmdcache"
 	"github.com/fredriklanga/wf/internal/config"
+	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/runlog"
 	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/usage"
 )
 
 // New creates a new management TUI model.
@@ -57,6 +64,14 @@ func Run(s store.Store) (string, error) {
 	}
 
 	m := New(s, workflows, theme, cfgDir)
+	m.runLog = runlog.New(config.RunLogPath())
+	if u, err := usage.Load(config.UsagePath()); err == nil {
+		m.usage = u
+	}
+	m.cmdCache = cmdcache.New(config.CommandCacheDir())
+	if err := parammeta.LoadCommandDefaults(); err != nil {
+		fmt.Fprintf(os.Stderr, "wf: ignoring invalid config: %v\n", err)
+	}
 	programOptions := []tea.ProgramOption{tea.WithAltScreen()}
 	if ttyOutErr == nil {
 		programOptions = append(programOptions, tea.WithOutput(ttyOut))
@@ -12,7 +12,12 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/fredriklanga/wf/internal/ai"
+	"github.com/fredriklanga/wf/internal/cmdcache"
+	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/runlog"
 	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/fredriklanga/wf/internal/usage"
 )
 
 // viewState tracks which view is active in the management TUI.
@@ -81,6 +86,13 @@ type Model struct {
 	// Flash and result state.
 	flashMsg string
 	result   string
+
+	// runLog records copied and pasted commands; nil disables logging.
+	runLog *runlog.Log
+	// usage tracks frecency and remembered param values; nil disables both.
+	usage *usage.State
+	// cmdCache holds dynamic and list param command output; nil disables caching.
+	cmdCache *cmdcache.Cache
 }
 
 // Init returns the initial command for the management TUI.
@@ -122,7 +134,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dialogResultMsg:
 		return m.handleDialogResult(msg)
 
-	case executeDialogDynamicMsg:
+	case executeDialogDynamicMsg, executeDialogSecretMsg:
 		if m.execDialog == nil {
 			return m, nil
 		}
@@ -205,7 +217,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if dialogWidth > 90 {
 			dialogWidth = 90
 		}
-		dlg := NewExecuteDialog(msg.workflow, dialogWidth, m.theme)
+		dlg := newExecuteDialog(msg.workflow, dialogWidth, m.theme, m.usage, m.cmdCache)
 		m.execDialog = &dlg
 		cmds := []tea.Cmd{dlg.Init()}
 		cmds = append(cmds, dlg.InitCmds()...)
@@ -385,9 +397,24 @@ func (m Model) updateExecDialog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// recordUsage bumps a workflow's frecency and remembers the param values
+// that were used, skipping params marked remember: false.
+func (m Model) recordUsage(workflow string, params []template.Param, values map[string]string) {
+	if m.usage == nil || workflow == "" {
+		return
+	}
+	m.usage.Record(workflow, time.Now())
+	m.usage.RememberValues(workflow, parammeta.Rememberable(params, values))
+	_ = m.usage.Save()
+}
+
 // handleDialogResult processes results from dialog interactions.
 func (m Model) handleDialogResult(msg dialogResultMsg) (tea.Model, tea.Cmd) {
 	if msg.dtype == dialogExecute {
+		var params []template.Param
+		if m.execDialog != nil {
+			params = m.execDialog.params
+		}
 		m.execDialog = nil
 		if !msg.confirmed {
 			return m, nil
@@ -395,18 +422,29 @@ func (m Model) handleDialogResult(msg dialogResultMsg) (tea.Model, tea.Cmd) {
 
 		action := msg.data["action"]
 		command := msg.data["command"]
+		entry := runlog.Entry{
+			Workflow: msg.data["workflow"],
+			Command:  msg.data["display"],
+			Params:   parammeta.MaskSecrets(params, msg.values),
+		}
 		switch action {
 		case "copy":
 			if err := clipboard.WriteAll(command); err != nil {
 				m.browse.aiError = "Clipboard error: " + err.Error()
 				return m, nil
 			}
+			entry.Action = runlog.ActionCopy
+			_ = m.runLog.Append(entry)
+			m.recordUsage(entry.Workflow, params, msg.values)
 			m.flashMsg = "Copied!"
 			m.browse.flashMsg = m.flashMsg
 			return m, tea.Tick(1500*time.Millisecond, func(time.Time) tea.Msg {
 				return clearFlashMsg{}
 			})
 		case "paste":
+			entry.Action = runlog.ActionPaste
+			_ = m.runLog.Append(entry)
+			m.recordUsage(entry.Workflow, params, msg.values)
 			m.result = command
 			return m, tea.Quit
 		}
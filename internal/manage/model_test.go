@@ -2,10 +2,13 @@ package manage
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/runlog"
 	"github.com/fredriklanga/wf/internal/store"
 	"github.com/fredriklanga/wf/internal/template"
 	"github.com/stretchr/testify/assert"
@@ -675,6 +678,49 @@ func TestDialogExecutePasteResultHandling(t *testing.T) {
 	assert.NotNil(t, cmd)
 }
 
+func TestDialogExecutePasteRecordsRunLog(t *testing.T) {
+	s := &mockStore{}
+	m := New(s, nil, DefaultTheme(), "/tmp/test")
+	m.runLog = runlog.New(filepath.Join(t.TempDir(), "history.jsonl"))
+	wf := store.Workflow{
+		Name:    "deploy",
+		Command: "deploy {{env}} {{token}}",
+		Args:    []store.Arg{{Name: "token", Type: "secret"}},
+	}
+	dlg := NewExecuteDialog(wf, 70, m.theme)
+	dlg.paramInputs[0].SetValue("prod")
+	dlg.paramInputs[1].SetValue("s3cret")
+	m.execDialog = &dlg
+	command, err := dlg.liveRender()
+	assert.NoError(t, err)
+	display, err := dlg.maskedRender()
+	assert.NoError(t, err)
+
+	updated, _ := m.Update(dialogResultMsg{
+		dtype:     dialogExecute,
+		confirmed: true,
+		data: map[string]string{
+			"action":   "paste",
+			"command":  command,
+			"display":  display,
+			"workflow": "deploy",
+		},
+		values: dlg.values(),
+	})
+	m = updated.(Model)
+	assert.Equal(t, "deploy prod s3cret", m.result)
+
+	entries, err := m.runLog.Entries()
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "deploy", entries[0].Workflow)
+		assert.Equal(t, runlog.ActionPaste, entries[0].Action)
+		assert.Equal(t, "deploy prod "+parammeta.SecretMask, entries[0].Command)
+		assert.Equal(t, map[string]string{"env": "prod", "token": parammeta.SecretMask}, entries[0].Params)
+		assert.Nil(t, entries[0].ExitCode)
+	}
+}
+
 func TestDialogExecuteCopyResultHandling(t *testing.T) {
 	s := &mockStore{}
 	m := New(s, nil, DefaultTheme(), "/tmp/test")
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	parammeta "github.com/fredriklanga/wf/internal/params"
 	"github.com/fredriklanga/wf/internal/store"
 )
 
@@ -39,12 +40,13 @@ const (
 )
 
 // availableTypes lists the parameter types in selector order.
-var availableTypes = []string{"text", "enum", "dynamic", "list"}
+var availableTypes = []string{"text", "enum", "dynamic", "list", "secret"}
 
 // paramEntry represents a single parameter in the editor.
 type paramEntry struct {
+	arg            store.Arg // original metadata; fields the editor does not expose are saved unchanged
 	name           string
-	paramType      string // "text", "enum", "dynamic", "list"
+	paramType      string // "text", "enum", "dynamic", "list", "secret"
 	defaultVal     string
 	options        []string
 	dynamicCmd     string
@@ -167,6 +169,7 @@ func newParamEntry(arg store.Arg) paramEntry {
 	listSkipHeaderInput.Placeholder = "0"
 
 	return paramEntry{
+		arg:                 arg,
 		name:                arg.Name,
 		paramType:           pt,
 		defaultVal:          arg.Default,
@@ -806,7 +809,7 @@ func (m ParamEditorModel) ValidateForSave() []string {
 	var warnings []string
 	for _, p := range m.params {
 		switch p.paramType {
-		case "text", "list":
+		case "text", "list", "secret":
 			if len(p.options) > 0 {
 				warnings = append(warnings, fmt.Sprintf("'%s' is type %s but has enum options — options will be cleared on save", p.name, p.paramType))
 			}
@@ -823,6 +826,26 @@ func (m ParamEditorModel) ValidateForSave() []string {
 			}
 		}
 
+		if _, err := parammeta.ParseCacheTTL(p.arg.CacheTTL); err != nil {
+			warnings = append(warnings, fmt.Sprintf("'%s' %v", p.name, err))
+		}
+
+		if err := parammeta.ValidateListJoin(p.arg); err != nil {
+			warnings = append(warnings, fmt.Sprintf("'%s' %v", p.name, err))
+		}
+
+		if err := parammeta.ValidateCommandOptions(p.arg); err != nil {
+			warnings = append(warnings, fmt.Sprintf("'%s' %v", p.name, err))
+		}
+
+		if err := parammeta.CheckRules(parammeta.ValidationFromArg(p.arg)); err != nil {
+			warnings = append(warnings, fmt.Sprintf("'%s' has invalid validate rules: %v", p.name, err))
+		}
+
+		if p.paramType == "secret" && p.defaultVal != "" {
+			warnings = append(warnings, fmt.Sprintf("'%s' is type secret but has a default — it will be saved in plain text; prefer secret_env or secret_cmd", p.name))
+		}
+
 		if p.paramType == "list" {
 			if strings.TrimSpace(p.listCmd) == "" {
 				warnings = append(warnings, fmt.Sprintf("'%s' is type list but has no list command", p.name))
@@ -1097,6 +1120,22 @@ func (m ParamEditorModel) renderExpandedParam(
 		lines = append(lines, "    "+dimStyle.Render("Desc: ")+p.description)
 	}
 
+	// Secret sources are edited in YAML; show where the value comes from.
+	if p.paramType == "secret" {
+		var sources []string
+		if p.arg.SecretEnv != "" {
+			sources = append(sources, "$"+p.arg.SecretEnv)
+		}
+		if p.arg.SecretCmd != "" {
+			sources = append(sources, p.arg.SecretCmd)
+		}
+		if len(sources) > 0 {
+			lines = append(lines, "    "+dimStyle.Render("Source: ")+strings.Join(sources, ", then "))
+		} else {
+			lines = append(lines, "    "+dimStyle.Render("Source: prompt (set secret_env or secret_cmd in YAML)"))
+		}
+	}
+
 	// Soft staging indicator: show preserved metadata for non-matching types.
 	if p.paramType != "enum" && len(p.options) > 0 {
 		lines = append(lines, "    "+dimStyle.Render("(enum options preserved: ")+dimStyle.Render(strings.Join(p.options, ", "))+dimStyle.Render(")"))
@@ -1213,11 +1252,33 @@ func (m ParamEditorModel) ToArgs() []store.Arg {
 
 	args := make([]store.Arg, len(m.params))
 	for i, p := range m.params {
-		arg := store.Arg{
-			Name:        p.name,
-			Default:     p.defaultVal,
-			Description: p.description,
-			Type:        p.paramType,
+		arg := p.arg
+		arg.Name = p.name
+		arg.Default = p.defaultVal
+		arg.Description = p.description
+		arg.Type = p.paramType
+		arg.Options = nil
+		arg.DynamicCmd = ""
+		arg.ListCmd = ""
+		arg.ListDelimiter = ""
+		arg.ListFieldIndex = 0
+		arg.ListSkipHeader = 0
+		if p.paramType != "list" {
+			arg.ListMulti = false
+			arg.ListSeparator = ""
+			arg.ListQuote = ""
+			arg.ListPrefix = ""
+		}
+		if p.paramType != "secret" {
+			arg.SecretEnv = ""
+			arg.SecretCmd = ""
+		}
+		if p.paramType != "dynamic" && p.paramType != "list" {
+			arg.CacheTTL = ""
+			arg.Shell = ""
+			arg.Timeout = ""
+			arg.Workdir = ""
+			arg.Env = nil
 		}
 
 		// Only include metadata compatible with the type.
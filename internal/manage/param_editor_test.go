@@ -406,6 +406,22 @@ func TestFormModel_CommandTemplateRenameDynamicSyntax(t *testing.T) {
 	assert.Equal(t, "checkout {{br!git branch --list}}", m.cmdInput.Value())
 }
 
+func TestFormModel_CommandTemplateRenameSkipsEscaped(t *testing.T) {
+	s := &mockStore{}
+	wf := &store.Workflow{
+		Name:    "test-wf",
+		Command: `echo \{{env}} {{env}}`,
+		Args:    []store.Arg{{Name: "env", Type: "text"}},
+	}
+
+	m := NewFormModel("edit", wf, s, nil, nil, DefaultTheme())
+	m.SetDimensions(80, 24)
+
+	m.updateCommandTemplateOnRename("env", "target")
+
+	assert.Equal(t, `echo \{{env}} {{target}}`, m.cmdInput.Value())
+}
+
 func TestParamEditor_VisibleSubFields(t *testing.T) {
 	tests := []struct {
 		paramType string
@@ -521,3 +537,16 @@ func TestParseListNumber(t *testing.T) {
 	assert.False(t, listNumberInvalid(""))
 	assert.False(t, listNumberInvalid("0"))
 }
+
+func TestParamEditor_ToArgsPreservesUnexposedFields(t *testing.T) {
+	off := false
+	args := []store.Arg{
+		{Name: "token", Type: "text", Remember: &off},
+	}
+	m := NewParamEditor(args, DefaultTheme(), 80)
+
+	resultArgs := m.ToArgs()
+	require.Len(t, resultArgs, 1)
+	require.NotNil(t, resultArgs[0].Remember)
+	assert.False(t, *resultArgs[0].Remember)
+}
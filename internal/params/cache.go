@@ -0,0 +1,50 @@
+package params
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseCacheTTL parses a cache_ttl value such as "90s", "10m", or "1d". An
+// empty value means the command output is not cached.
+func ParseCacheTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	var (
+		ttl time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		ttl, err = time.ParseDuration(s)
+	}
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid cache_ttl %q (want a duration such as 90s, 10m, or 1d)", s)
+	}
+	return ttl, nil
+}
+
+// RowsFromLines converts cached output lines back into list rows.
+func RowsFromLines(lines []string) []ListRow {
+	rows := make([]ListRow, len(lines))
+	for i, line := range lines {
+		rows[i] = ListRow{Raw: line}
+	}
+	return rows
+}
+
+// Lines returns the raw text of each selectable row, for caching.
+func (s ListSource) Lines() []string {
+	lines := make([]string, len(s.Rows))
+	for i, row := range s.Rows {
+		lines[i] = row.Raw
+	}
+	return lines
+}
@@ -0,0 +1,41 @@
+package params
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCacheTTL(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"":    0,
+		"90s": 90 * time.Second,
+		"10m": 10 * time.Minute,
+		"2d":  48 * time.Hour,
+	} {
+		got, err := ParseCacheTTL(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"soon", "-5m", "xd"} {
+		_, err := ParseCacheTTL(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestOverlayMetadata_CacheTTL(t *testing.T) {
+	params := OverlayMetadata("kubectl logs {{pod}}", []store.Arg{
+		{Name: "pod", Type: "list", ListCmd: "kubectl get pods", CacheTTL: "5m"},
+	})
+	require.Len(t, params, 1)
+	assert.Equal(t, 5*time.Minute, params[0].CacheTTL)
+}
+
+func TestListSourceLinesRoundTrip(t *testing.T) {
+	src := ListSource{Rows: RowsFromLines([]string{"a 1", "b 2"})}
+	assert.Equal(t, []string{"a 1", "b 2"}, src.Lines())
+}
@@ -0,0 +1,184 @@
+package params
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fredriklanga/wf/internal/config"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// DefaultCommandShell and DefaultCommandTimeout apply when neither the arg
+// nor the global config sets an interpreter or timeout.
+const (
+	DefaultCommandShell   = "sh"
+	DefaultCommandTimeout = 5 * time.Second
+)
+
+// commandDefaults holds the global options set by SetCommandDefaults.
+var commandDefaults template.CommandOptions
+
+// SetCommandDefaults sets the options used for any field an arg leaves
+// unset, normally from the param_commands section of config.yaml.
+func SetCommandDefaults(o template.CommandOptions) {
+	commandDefaults = o
+}
+
+// LoadCommandDefaults sets the command defaults from config.yaml. A missing
+// or unreadable config leaves the built-in defaults in place. An invalid
+// timeout is returned after the other defaults are set, and the built-in
+// timeout applies.
+func LoadCommandDefaults() error {
+	cfg, err := config.LoadAppConfig()
+	if err != nil {
+		return nil
+	}
+	s := cfg.ParamCommands
+	timeout, err := s.CommandTimeout()
+	SetCommandDefaults(template.CommandOptions{
+		Shell:   strings.TrimSpace(s.Shell),
+		Timeout: timeout,
+		Dir:     strings.TrimSpace(s.Workdir),
+		Env:     s.Env,
+	})
+	return err
+}
+
+// CommandOptionsFromArg converts an arg's command settings. An invalid
+// timeout is ignored here and reported by ValidateCommandOptions.
+func CommandOptionsFromArg(arg store.Arg) template.CommandOptions {
+	timeout, _ := time.ParseDuration(strings.TrimSpace(arg.Timeout))
+	return template.CommandOptions{
+		Shell:   strings.TrimSpace(arg.Shell),
+		Timeout: timeout,
+		Dir:     strings.TrimSpace(arg.Workdir),
+		Env:     arg.Env,
+	}
+}
+
+// ValidateCommandOptions reports arg command settings that cannot be used.
+func ValidateCommandOptions(arg store.Arg) error {
+	if strings.TrimSpace(arg.Timeout) != "" {
+		if _, err := config.ParseTimeout(arg.Timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EffectiveCommandOptions returns p's command options with unset fields
+// filled from the global defaults and then the built-in ones. Environment
+// variables from the arg override global ones with the same name.
+func EffectiveCommandOptions(p template.Param) template.CommandOptions {
+	o := p.Exec
+	if o.Shell == "" {
+		o.Shell = commandDefaults.Shell
+	}
+	if o.Shell == "" {
+		o.Shell = DefaultCommandShell
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = commandDefaults.Timeout
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultCommandTimeout
+	}
+	if o.Dir == "" {
+		o.Dir = commandDefaults.Dir
+	}
+	o.Dir = expandHome(o.Dir)
+	if len(commandDefaults.Env) > 0 {
+		env := make(map[string]string, len(commandDefaults.Env)+len(o.Env))
+		for k, v := range commandDefaults.Env {
+			env[k] = v
+		}
+		for k, v := range o.Env {
+			env[k] = v
+		}
+		o.Env = env
+	}
+	return o
+}
+
+// RunDynamicCommand runs a dynamic param's command and returns its
+// non-empty output lines, trimmed. Failures are *ListSourceError values
+// whose Detail names the interpreter and includes stderr.
+func RunDynamicCommand(command string, opts template.CommandOptions) ([]string, error) {
+	output, err := runCommand("dynamic", command, opts)
+	if err != nil {
+		return nil, err
+	}
+	var options []string
+	for _, line := range strings.FieldsFunc(string(output), func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if line = strings.TrimSpace(line); line != "" {
+			options = append(options, line)
+		}
+	}
+	return options, nil
+}
+
+// runCommand runs command through opts.Shell with opts.Timeout, in
+// opts.Dir with opts.Env added to the environment. kind names the param
+// type in error messages.
+func runCommand(kind, command string, opts template.CommandOptions) ([]byte, error) {
+	interp := strings.Fields(opts.Shell)
+	if len(interp) == 0 {
+		interp = []string{DefaultCommandShell}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, interp[0], append(interp[1:], "-c", command)...)
+	// Children of the interpreter can keep the output pipes open after it
+	// is killed; stop waiting for them shortly after the timeout.
+	cmd.WaitDelay = 250 * time.Millisecond
+	cmd.Dir = opts.Dir
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), envList(opts.Env)...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err == nil {
+		return output, nil
+	}
+
+	detail := "interpreter: " + strings.Join(interp, " ") + " -c"
+	if opts.Dir != "" {
+		detail += "\nworkdir: " + opts.Dir
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		detail += "\nstderr: " + msg
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, &ListSourceError{Short: fmt.Sprintf("%s command timed out after %s", kind, timeout), Detail: detail}
+	}
+	return nil, &ListSourceError{Short: fmt.Sprintf("%s command failed: %v", kind, err), Detail: detail}
+}
+
+func envList(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = k + "=" + env[k]
+	}
+	return out
+}
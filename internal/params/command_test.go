@@ -0,0 +1,100 @@
+package params
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlayMetadata_CommandOptions(t *testing.T) {
+	ps := OverlayMetadata("kubectl logs {{pod}}", []store.Arg{{
+		Name:       "pod",
+		Type:       "dynamic",
+		DynamicCmd: "kubectl get pods -o name",
+		Shell:      "bash",
+		Timeout:    "20s",
+		Workdir:    "/srv",
+		Env:        map[string]string{"KUBECONFIG": "/srv/kube"},
+	}})
+
+	require.Len(t, ps, 1)
+	assert.Equal(t, template.CommandOptions{
+		Shell:   "bash",
+		Timeout: 20 * time.Second,
+		Dir:     "/srv",
+		Env:     map[string]string{"KUBECONFIG": "/srv/kube"},
+	}, ps[0].Exec)
+}
+
+func TestEffectiveCommandOptions_FallsBackToGlobalThenBuiltIn(t *testing.T) {
+	t.Cleanup(func() { SetCommandDefaults(template.CommandOptions{}) })
+
+	o := EffectiveCommandOptions(template.Param{})
+	assert.Equal(t, DefaultCommandShell, o.Shell)
+	assert.Equal(t, DefaultCommandTimeout, o.Timeout)
+	assert.Empty(t, o.Dir)
+
+	SetCommandDefaults(template.CommandOptions{
+		Shell:   "bash",
+		Timeout: time.Minute,
+		Dir:     "/tmp",
+		Env:     map[string]string{"A": "global", "B": "global"},
+	})
+	o = EffectiveCommandOptions(template.Param{Exec: template.CommandOptions{
+		Timeout: 2 * time.Second,
+		Env:     map[string]string{"B": "arg"},
+	}})
+	assert.Equal(t, "bash", o.Shell)
+	assert.Equal(t, 2*time.Second, o.Timeout)
+	assert.Equal(t, "/tmp", o.Dir)
+	assert.Equal(t, map[string]string{"A": "global", "B": "arg"}, o.Env)
+}
+
+func TestValidateCommandOptions(t *testing.T) {
+	assert.NoError(t, ValidateCommandOptions(store.Arg{}))
+	assert.NoError(t, ValidateCommandOptions(store.Arg{Timeout: "1m30s"}))
+	assert.Error(t, ValidateCommandOptions(store.Arg{Timeout: "soon"}))
+	assert.Error(t, ValidateCommandOptions(store.Arg{Timeout: "-5s"}))
+}
+
+func TestRunDynamicCommand_UsesWorkdirAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	options, err := RunDynamicCommand(`pwd; printf '%s\n\n' "$WF_TEST_VALUE"`, template.CommandOptions{
+		Shell: "sh",
+		Dir:   dir,
+		Env:   map[string]string{"WF_TEST_VALUE": "hello"},
+	})
+	require.NoError(t, err)
+	require.Len(t, options, 2)
+	assert.Contains(t, options[0], dir)
+	assert.Equal(t, "hello", options[1])
+}
+
+func TestRunDynamicCommand_FailureNamesInterpreterAndStderr(t *testing.T) {
+	_, err := RunDynamicCommand("echo broken >&2; exit 3", template.CommandOptions{Shell: "sh -e"})
+	require.Error(t, err)
+
+	var sourceErr *ListSourceError
+	require.ErrorAs(t, err, &sourceErr)
+	assert.Contains(t, sourceErr.Short, "dynamic command failed")
+	assert.Contains(t, sourceErr.Detail, "interpreter: sh -e -c")
+	assert.Contains(t, sourceErr.Detail, "broken")
+}
+
+func TestLoadListSourceWith_TimeoutKeepsStderr(t *testing.T) {
+	_, err := LoadListSourceWith("echo partial >&2; sleep 5", 0, template.CommandOptions{
+		Shell:   "sh",
+		Timeout: 100 * time.Millisecond,
+	})
+	require.Error(t, err)
+
+	var sourceErr *ListSourceError
+	require.ErrorAs(t, err, &sourceErr)
+	assert.Equal(t, "list command timed out after 100ms", sourceErr.Short)
+	assert.Contains(t, sourceErr.Detail, "interpreter: sh -c")
+	assert.Contains(t, sourceErr.Detail, "partial")
+}
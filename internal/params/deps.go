@@ -0,0 +1,169 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// CycleError reports params whose dynamic or list commands reference each
+// other, so no fill order can satisfy them.
+type CycleError struct {
+	Names []string // The cycle in reference order, first name repeated last
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("parameters depend on each other: %s", strings.Join(e.Names, " → "))
+}
+
+// SourceCommand returns the command that produces a dynamic or list param's
+// options, or "" for other types.
+func SourceCommand(p template.Param) string {
+	switch p.Type {
+	case template.ParamDynamic:
+		return p.DynamicCmd
+	case template.ParamList:
+		return p.ListCmd
+	}
+	return ""
+}
+
+// Dependencies returns the names of other params referenced by p's dynamic
+// or list command, e.g. namespace for `kubectl get pods -n {{namespace}}`.
+func Dependencies(p template.Param) []string {
+	var names []string
+	for _, ref := range template.ExtractParams(SourceCommand(p)) {
+		names = append(names, ref.Name)
+	}
+	return names
+}
+
+// RenderSource fills p's source command with the current values of the
+// params it depends on, quoted for the command's interpreter. ready is false
+// while any of them is still empty, in which case the command should not run
+// yet.
+func RenderSource(p template.Param, values map[string]string) (command string, ready bool) {
+	command = SourceCommand(p)
+	for _, name := range Dependencies(p) {
+		if values[name] == "" {
+			return command, false
+		}
+	}
+	dialect := template.DialectFromShell(EffectiveCommandOptions(p).Shell)
+	return template.RenderQuoted(command, values, template.QuoteOptions{Dialect: dialect}), true
+}
+
+// DependsOnSecret reports whether p's source command references a secret
+// param among ps. Its rendered command then contains the secret, so neither
+// the command nor its output may be cached.
+func DependsOnSecret(p template.Param, ps []template.Param) bool {
+	for _, name := range Dependencies(p) {
+		for _, other := range ps {
+			if other.Name == name && other.Type == template.ParamSecret {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Waiting returns the dependencies of p that have no value yet.
+func Waiting(p template.Param, values map[string]string) []string {
+	var names []string
+	for _, name := range Dependencies(p) {
+		if values[name] == "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// FillOrder returns param indexes ordered so every param comes after the
+// params its source command references. Params keep their template order
+// wherever dependencies allow. References to names that are not params are
+// ignored.
+func FillOrder(ps []template.Param) ([]int, error) {
+	index := make(map[string]int, len(ps))
+	for i, p := range ps {
+		index[p.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(ps))
+	order := make([]int, 0, len(ps))
+	var path []string
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for j, name := range path {
+				if name == ps[i].Name {
+					start = j
+				}
+			}
+			cycle := append(append([]string(nil), path[start:]...), ps[i].Name)
+			return &CycleError{Names: cycle}
+		}
+		state[i] = visiting
+		path = append(path, ps[i].Name)
+		for _, name := range Dependencies(ps[i]) {
+			if j, ok := index[name]; ok {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+		order = append(order, i)
+		return nil
+	}
+
+	for i := range ps {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// CheckDependencies reports a dependency cycle among a workflow's params.
+// It is used when saving so a workflow that could never be filled is
+// rejected up front.
+func CheckDependencies(wf store.Workflow) error {
+	_, err := FillOrder(withSourceRefs(OverlayMetadata(wf.Template(), wf.Args), wf.Args))
+	return err
+}
+
+// withSourceRefs appends params that are only referenced from other params'
+// source commands, such as a namespace used to list pods, until every
+// reference resolves to a param.
+func withSourceRefs(ps []template.Param, args []store.Arg) []template.Param {
+	seen := make(map[string]bool, len(ps))
+	for _, p := range ps {
+		seen[p.Name] = true
+	}
+	for i := 0; i < len(ps); i++ {
+		var extra string
+		for _, name := range Dependencies(ps[i]) {
+			if !seen[name] {
+				seen[name] = true
+				extra += " {{" + name + "}}"
+			}
+		}
+		if extra != "" {
+			ps = append(ps, OverlayMetadata(extra, args)...)
+		}
+	}
+	return ps
+}
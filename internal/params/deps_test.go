@@ -0,0 +1,100 @@
+package params
+
+import (
+	"testing"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSource(t *testing.T) {
+	p := template.Param{Name: "pod", Type: template.ParamList, ListCmd: "kubectl get pods -n {{namespace}}"}
+
+	assert.Equal(t, []string{"namespace"}, Dependencies(p))
+
+	cmd, ready := RenderSource(p, map[string]string{})
+	assert.False(t, ready)
+	assert.Equal(t, "kubectl get pods -n {{namespace}}", cmd)
+	assert.Equal(t, []string{"namespace"}, Waiting(p, nil))
+
+	cmd, ready = RenderSource(p, map[string]string{"namespace": "prod"})
+	assert.True(t, ready)
+	assert.Equal(t, "kubectl get pods -n prod", cmd)
+	assert.Empty(t, Waiting(p, map[string]string{"namespace": "prod"}))
+}
+
+func TestDependsOnSecret(t *testing.T) {
+	ps := []template.Param{
+		{Name: "token", Type: template.ParamSecret},
+		{Name: "namespace", Type: template.ParamText},
+		{Name: "db", Type: template.ParamList, ListCmd: "vault list --token {{token}}"},
+		{Name: "pod", Type: template.ParamList, ListCmd: "kubectl get pods -n {{namespace}}"},
+	}
+	assert.True(t, DependsOnSecret(ps[2], ps))
+	assert.False(t, DependsOnSecret(ps[3], ps))
+}
+
+func TestFillOrder(t *testing.T) {
+	ps := []template.Param{
+		{Name: "pod", Type: template.ParamList, ListCmd: "kubectl get pods -n {{namespace}} --context {{cluster}}"},
+		{Name: "namespace", Type: template.ParamDynamic, DynamicCmd: "kubectl get ns --context {{cluster}}"},
+		{Name: "cluster"},
+		{Name: "cmd"},
+	}
+
+	order, err := FillOrder(ps)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 0, 3}, order)
+}
+
+func TestFillOrder_Cycle(t *testing.T) {
+	ps := []template.Param{
+		{Name: "a", Type: template.ParamDynamic, DynamicCmd: "echo {{b}}"},
+		{Name: "b", Type: template.ParamList, ListCmd: "echo {{a}}"},
+	}
+
+	_, err := FillOrder(ps)
+	var cycle *CycleError
+	require.ErrorAs(t, err, &cycle)
+	assert.Equal(t, []string{"a", "b", "a"}, cycle.Names)
+	assert.Equal(t, "parameters depend on each other: a → b → a", err.Error())
+
+	_, err = FillOrder([]template.Param{{Name: "self", Type: template.ParamDynamic, DynamicCmd: "ls {{self}}"}})
+	assert.ErrorAs(t, err, &cycle)
+}
+
+func TestForWorkflow_OrdersAndAddsSourceRefs(t *testing.T) {
+	wf := store.Workflow{
+		Command: "kubectl logs {{pod}} -n {{namespace}}",
+		Args: []store.Arg{
+			{Name: "pod", Type: "list", ListCmd: "kubectl get pods -n {{namespace}} --context {{cluster}}"},
+			{Name: "cluster", Default: "dev"},
+		},
+	}
+
+	ps := ForWorkflow(wf)
+	var names []string
+	for _, p := range ps {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"namespace", "cluster", "pod"}, names)
+	assert.Equal(t, "dev", ps[1].Default, "params added from source refs get their metadata")
+	assert.NoError(t, CheckDependencies(wf))
+}
+
+func TestCheckDependencies_ReportsCycle(t *testing.T) {
+	wf := store.Workflow{
+		Command: "deploy {{service}}",
+		Args: []store.Arg{
+			{Name: "service", Type: "dynamic", DynamicCmd: "list-services {{region}}"},
+			{Name: "region", Type: "dynamic", DynamicCmd: "list-regions {{service}}"},
+		},
+	}
+
+	err := CheckDependencies(wf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service → region → service")
+	assert.Len(t, ForWorkflow(wf), 2, "params are still returned so the cycle can be shown")
+}
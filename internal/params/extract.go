@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
 )
 
 // RetryableError marks errors that allow the user to retry selection.
@@ -49,3 +52,36 @@ func ExtractListValue(raw, delimiter string, fieldIndex int) (string, error) {
 
 	return strings.TrimSpace(parts[fieldIndex-1]), nil
 }
+
+// JoinListValues combines the values chosen in a multi-select list param
+// according to join, e.g. ["a", "b"] with Prefix "-f" gives "-f a -f b".
+// quote applies join.Quote to each value, usually the QuoteListItem of the
+// dialect the command is rendered for.
+func JoinListValues(values []string, join template.ListJoin, quote func(v, style string) string) string {
+	sep := join.Separator
+	if sep == "" {
+		sep = " "
+	}
+	items := make([]string, len(values))
+	for i, v := range values {
+		v = quote(v, join.Quote)
+		switch {
+		case join.Prefix == "":
+		case strings.HasSuffix(join.Prefix, "="):
+			v = join.Prefix + v
+		default:
+			v = join.Prefix + " " + v
+		}
+		items[i] = v
+	}
+	return strings.Join(items, sep)
+}
+
+// ValidateListJoin reports multi-select join settings that cannot be applied.
+func ValidateListJoin(arg store.Arg) error {
+	switch arg.ListQuote {
+	case "", "single", "double", "none":
+		return nil
+	}
+	return fmt.Errorf("invalid list_quote %q (want single, double or none)", arg.ListQuote)
+}
@@ -3,15 +3,11 @@ package params
 import (
 	"bufio"
 	"bytes"
-	"context"
-	"errors"
-	"fmt"
-	"os/exec"
 	"strings"
-	"time"
+
+	"github.com/fredriklanga/wf/internal/template"
 )
 
-const listSourceTimeout = 5 * time.Second
 const listSourceMaxTokenSize = 1024 * 1024
 
 // ListRow preserves the raw command output row for later display and extraction.
@@ -37,20 +33,23 @@ func (e *ListSourceError) Error() string {
 	return e.Short
 }
 
-// LoadListSource runs a shell command, loads non-empty output rows, and applies
-// header skipping before returning selectable rows.
+// LoadListSource runs a shell command with the default command options,
+// loads non-empty output rows, and applies header skipping before returning
+// selectable rows.
 func LoadListSource(command string, skipHeader int) (ListSource, error) {
+	return LoadListSourceWith(command, skipHeader, EffectiveCommandOptions(template.Param{}))
+}
+
+// LoadListSourceWith is LoadListSource using the given interpreter, timeout,
+// working directory and environment.
+func LoadListSourceWith(command string, skipHeader int, opts template.CommandOptions) (ListSource, error) {
 	if skipHeader < 0 {
 		skipHeader = 0
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), listSourceTimeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
-	output, err := cmd.Output()
+	output, err := runCommand("list", command, opts)
 	if err != nil {
-		return ListSource{}, buildListSourceError(err)
+		return ListSource{}, err
 	}
 
 	rows, err := scanListRows(output)
@@ -87,19 +86,3 @@ func scanListRows(output []byte) ([]ListRow, error) {
 
 	return rows, nil
 }
-
-func buildListSourceError(err error) error {
-	if errors.Is(err, context.DeadlineExceeded) {
-		return &ListSourceError{Short: fmt.Sprintf("list command timed out after %s", listSourceTimeout)}
-	}
-
-	short := fmt.Sprintf("list command failed: %v", err)
-	detail := ""
-
-	var exitErr *exec.ExitError
-	if errors.As(err, &exitErr) {
-		detail = strings.TrimSpace(string(exitErr.Stderr))
-	}
-
-	return &ListSourceError{Short: short, Detail: detail}
-}
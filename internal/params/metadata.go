@@ -10,7 +10,9 @@ import (
 //
 // Inline defaults remain authoritative; stored defaults fill only missing values.
 // Stored type metadata is authoritative when present so saved workflow args can
-// change runtime behavior without changing inline template syntax.
+// change runtime behavior without changing inline template syntax. Settings
+// with no inline syntax, such as cache_ttl or timeout, apply even when the arg
+// sets no type.
 func OverlayMetadata(command string, args []store.Arg) []template.Param {
 	params := template.ExtractParams(command)
 	if len(params) == 0 {
@@ -31,6 +33,20 @@ func OverlayMetadata(command string, args []store.Arg) []template.Param {
 		if params[i].Default == "" && arg.Default != "" {
 			params[i].Default = arg.Default
 		}
+		params[i].NoRemember = !arg.Remembers()
+		params[i].Validation = ValidationFromArg(arg)
+		// Inline placeholders have no syntax for these, so they apply
+		// whether or not the arg also sets a type.
+		params[i].ListMulti = arg.ListMulti
+		params[i].ListJoin = template.ListJoin{
+			Separator: arg.ListSeparator,
+			Quote:     arg.ListQuote,
+			Prefix:    arg.ListPrefix,
+		}
+		params[i].SecretEnv = arg.SecretEnv
+		params[i].SecretCmd = arg.SecretCmd
+		params[i].CacheTTL, _ = ParseCacheTTL(arg.CacheTTL)
+		params[i].Exec = CommandOptionsFromArg(arg)
 
 		if arg.Type == "" {
 			continue
@@ -54,3 +70,67 @@ func cloneStrings(values []string) []string {
 	}
 	return append([]string(nil), values...)
 }
+
+// ForWorkflow returns the params a workflow needs filled before it can run:
+// placeholders from its command (or every step), followed by names that are
+// only referenced by step when: conditions or by other params' dynamic and
+// list commands. Params are returned in fill order, so a param always comes
+// after the params its command references; with a dependency cycle the
+// template order is kept and CheckDependencies reports the problem.
+func ForWorkflow(wf store.Workflow) []template.Param {
+	command := wf.Template()
+	seen := make(map[string]bool)
+	for _, p := range template.ExtractParams(command) {
+		seen[p.Name] = true
+	}
+	for _, step := range wf.Steps {
+		for _, name := range template.ConditionNames(step.When) {
+			if !seen[name] {
+				seen[name] = true
+				command += " {{" + name + "}}"
+			}
+		}
+	}
+	ps := withSourceRefs(OverlayMetadata(command, wf.Args), wf.Args)
+	order, err := FillOrder(ps)
+	if err != nil || len(ps) == 0 {
+		return ps
+	}
+	ordered := make([]template.Param, len(ps))
+	for i, idx := range order {
+		ordered[i] = ps[idx]
+	}
+	return ordered
+}
+
+// Rememberable returns the subset of values whose params allow remembering.
+// Secret values are never included.
+func Rememberable(ps []template.Param, values map[string]string) map[string]string {
+	out := make(map[string]string, len(values))
+	for _, p := range ps {
+		if v, ok := values[p.Name]; ok && !p.NoRemember && p.Type != template.ParamSecret {
+			out[p.Name] = v
+		}
+	}
+	return out
+}
+
+// ApplyRecent replaces the default of every rememberable param that has
+// remembered values with the most recent one, and returns the remembered
+// values per param index for offering as completions.
+func ApplyRecent(ps []template.Param, recent func(name string) []string) [][]string {
+	out := make([][]string, len(ps))
+	if recent == nil {
+		return out
+	}
+	for i := range ps {
+		if ps[i].NoRemember || ps[i].Type == template.ParamSecret {
+			continue
+		}
+		if values := recent(ps[i].Name); len(values) > 0 {
+			ps[i].Default = values[0]
+			out[i] = values
+		}
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package params
 
 import (
 	"testing"
+	"time"
 
 	"github.com/fredriklanga/wf/internal/store"
 	"github.com/fredriklanga/wf/internal/template"
@@ -49,6 +50,22 @@ func TestOverlayMetadata_StoredListMetadataOverridesExtractedText(t *testing.T)
 	assert.Equal(t, "from-store", params[2].Default)
 }
 
+func TestOverlayMetadata_UntypedArgSetsCommandSettings(t *testing.T) {
+	params := OverlayMetadata("kubectl logs {{pod!kubectl get pods -o name}}", []store.Arg{{
+		Name:     "pod",
+		CacheTTL: "5m",
+		Shell:    "bash",
+		Timeout:  "20s",
+	}})
+
+	require.Len(t, params, 1)
+	assert.Equal(t, template.ParamDynamic, params[0].Type)
+	assert.Equal(t, "kubectl get pods -o name", params[0].DynamicCmd)
+	assert.Equal(t, 5*time.Minute, params[0].CacheTTL)
+	assert.Equal(t, "bash", params[0].Exec.Shell)
+	assert.Equal(t, 20*time.Second, params[0].Exec.Timeout)
+}
+
 func TestOverlayMetadata_StoredEnumOptionsReplaceInlineOptions(t *testing.T) {
 	params := OverlayMetadata("deploy {{env|dev|staging|prod}}", []store.Arg{{
 		Name:    "env",
@@ -93,3 +110,93 @@ func TestExtractListValue(t *testing.T) {
 		})
 	}
 }
+
+func TestJoinListValues(t *testing.T) {
+	values := []string{"a.txt", "it's here"}
+	tests := []struct {
+		name string
+		join template.ListJoin
+		want string
+	}{
+		{"default space", template.ListJoin{}, `a.txt 'it'\''s here'`},
+		{"separator", template.ListJoin{Separator: ","}, `a.txt,'it'\''s here'`},
+		{"unquoted", template.ListJoin{Quote: "none"}, "a.txt it's here"},
+		{"single quotes", template.ListJoin{Quote: "single"}, `'a.txt' 'it'\''s here'`},
+		{"double quotes", template.ListJoin{Quote: "double", Separator: " "}, `"a.txt" "it's here"`},
+		{"flag prefix", template.ListJoin{Prefix: "-f", Quote: "single"}, `-f 'a.txt' -f 'it'\''s here'`},
+		{"assignment prefix", template.ListJoin{Prefix: "--file="}, `--file=a.txt --file='it'\''s here'`},
+	}
+	posix := template.DialectPOSIX.QuoteListItem
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, JoinListValues(values, tt.join, posix))
+		})
+	}
+	assert.Empty(t, JoinListValues(nil, template.ListJoin{}, posix))
+	assert.Error(t, ValidateListJoin(store.Arg{ListQuote: "backtick"}))
+}
+
+func TestJoinListValues_QuotesForDialect(t *testing.T) {
+	values := []string{"it's", "$HOME"}
+	single := template.ListJoin{Quote: "single"}
+	double := template.ListJoin{Quote: "double"}
+
+	assert.Equal(t, `'it\'s' '$HOME'`, JoinListValues(values, single, template.DialectFish.QuoteListItem))
+	assert.Equal(t, `"it's" "\$HOME"`, JoinListValues(values, double, template.DialectFish.QuoteListItem))
+	assert.Equal(t, `'it''s' '$HOME'`, JoinListValues(values, single, template.DialectPowerShell.QuoteListItem))
+	assert.Equal(t, "\"it's\" \"`$HOME\"", JoinListValues(values, double, template.DialectPowerShell.QuoteListItem))
+}
+
+func TestForWorkflowIncludesConditionOnlyParams(t *testing.T) {
+	wf := store.Workflow{
+		Name: "release",
+		Steps: []store.Step{
+			{Name: "build", Command: "make build VERSION={{version}}"},
+			{Name: "notify", Command: "notify-send done", When: "env == prod"},
+		},
+		Args: []store.Arg{
+			{Name: "env", Type: "enum", Options: []string{"dev", "prod"}},
+		},
+	}
+
+	params := ForWorkflow(wf)
+
+	require.Len(t, params, 2)
+	assert.Equal(t, "version", params[0].Name)
+	assert.Equal(t, "env", params[1].Name)
+	assert.Equal(t, template.ParamEnum, params[1].Type)
+	assert.Equal(t, []string{"dev", "prod"}, params[1].Options)
+}
+
+func TestOverlayMetadata_RememberFalseSetsNoRemember(t *testing.T) {
+	off := false
+	params := OverlayMetadata("login {{user}} {{token}}", []store.Arg{
+		{Name: "token", Remember: &off},
+	})
+
+	require.Len(t, params, 2)
+	assert.False(t, params[0].NoRemember)
+	assert.True(t, params[1].NoRemember)
+}
+
+func TestApplyRecentAndRememberable(t *testing.T) {
+	params := []template.Param{
+		{Name: "env", Default: "dev"},
+		{Name: "token", NoRemember: true},
+		{Name: "fresh", Default: "x"},
+	}
+	recent := map[string][]string{
+		"env":   {"prod", "staging"},
+		"token": {"leaked"},
+	}
+
+	got := ApplyRecent(params, func(name string) []string { return recent[name] })
+
+	assert.Equal(t, [][]string{{"prod", "staging"}, nil, nil}, got)
+	assert.Equal(t, "prod", params[0].Default, "last used value replaces the default")
+	assert.Equal(t, "", params[1].Default, "remember: false params are never prefilled")
+	assert.Equal(t, "x", params[2].Default)
+
+	kept := Rememberable(params, map[string]string{"env": "prod", "token": "s3cret", "fresh": "y"})
+	assert.Equal(t, map[string]string{"env": "prod", "fresh": "y"}, kept)
+}
@@ -0,0 +1,94 @@
+package params
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// SecretMask stands in for secret values wherever a command is displayed or
+// recorded.
+const SecretMask = "••••••"
+
+// secretCmdTimeout is generous because commands like `pass show` may wait
+// for a pinentry prompt.
+const secretCmdTimeout = 30 * time.Second
+
+// MaskSecrets returns a copy of values with every non-empty secret param
+// value replaced by SecretMask.
+func MaskSecrets(ps []template.Param, values map[string]string) map[string]string {
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		out[k] = v
+	}
+	for _, p := range ps {
+		if p.Type == template.ParamSecret && out[p.Name] != "" {
+			out[p.Name] = SecretMask
+		}
+	}
+	return out
+}
+
+// SecretFromEnv returns the value of a secret param's secret_env variable.
+// ok is false when no variable is configured or it is unset or empty.
+func SecretFromEnv(p template.Param) (value string, ok bool) {
+	if p.SecretEnv == "" {
+		return "", false
+	}
+	value = os.Getenv(p.SecretEnv)
+	return value, value != ""
+}
+
+// SecretFromCmd runs a secret param's secret_cmd and returns the first line
+// of its output, following the `pass` convention of putting the secret on
+// the first line.
+func SecretFromCmd(p template.Param) (string, error) {
+	if p.SecretCmd == "" {
+		return "", errors.New("no secret command configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretCmdTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.SecretCmd)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("secret command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("secret command failed: %w", err)
+	}
+
+	value, _, _ := strings.Cut(string(output), "\n")
+	value = strings.TrimRight(value, "\r")
+	if value == "" {
+		return "", errors.New("secret command printed nothing")
+	}
+	return value, nil
+}
+
+// ResolveSecret returns a secret param's value from secret_env, falling
+// back to secret_cmd. ok is false when neither source is configured or the
+// environment variable is unset and no command is configured.
+func ResolveSecret(p template.Param) (value string, ok bool, err error) {
+	if v, ok := SecretFromEnv(p); ok {
+		return v, true, nil
+	}
+	if p.SecretCmd == "" {
+		return "", false, nil
+	}
+	v, err := SecretFromCmd(p)
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
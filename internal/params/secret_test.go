@@ -0,0 +1,60 @@
+package params
+
+import (
+	"testing"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlayMetadata_SecretType(t *testing.T) {
+	params := OverlayMetadata("curl -H 'Authorization: {{token}}' {{url}}", []store.Arg{
+		{Name: "token", Type: "secret", SecretEnv: "API_TOKEN", SecretCmd: "pass show api"},
+	})
+
+	require.Len(t, params, 2)
+	assert.Equal(t, template.ParamSecret, params[0].Type)
+	assert.Equal(t, "API_TOKEN", params[0].SecretEnv)
+	assert.Equal(t, "pass show api", params[0].SecretCmd)
+	assert.True(t, params[0].NoRemember, "secrets are never remembered")
+}
+
+func TestMaskSecrets(t *testing.T) {
+	params := []template.Param{
+		{Name: "token", Type: template.ParamSecret},
+		{Name: "empty", Type: template.ParamSecret},
+		{Name: "url"},
+	}
+	values := map[string]string{"token": "s3cret", "url": "https://x", "empty": ""}
+
+	masked := MaskSecrets(params, values)
+
+	assert.Equal(t, map[string]string{"token": SecretMask, "url": "https://x", "empty": ""}, masked)
+	assert.Equal(t, "s3cret", values["token"], "input map is not modified")
+	assert.Empty(t, Rememberable(params, values)["token"])
+}
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("WF_TEST_SECRET", "from-env")
+
+	v, ok, err := ResolveSecret(template.Param{SecretEnv: "WF_TEST_SECRET", SecretCmd: "echo from-cmd"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "from-env", v, "environment wins over command")
+
+	v, ok, err = ResolveSecret(template.Param{SecretEnv: "WF_TEST_UNSET", SecretCmd: "printf 'pw\\nuser: me\\n'"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "pw", v, "only the first output line is used")
+
+	_, ok, err = ResolveSecret(template.Param{SecretEnv: "WF_TEST_UNSET"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = ResolveSecret(template.Param{SecretCmd: "echo nope >&2; exit 1"})
+	require.Error(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), "nope")
+}
@@ -0,0 +1,233 @@
+package params
+
+import (
+	"time"
+
+	"github.com/fredriklanga/wf/internal/cmdcache"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// SourceForm is the param form a Sources drives. The picker and the manage
+// execute dialog implement it over their own inputs and list states.
+type SourceForm interface {
+	// ShowSourceLines shows lines as param i's options.
+	ShowSourceLines(i int, lines []string)
+	// LoadSource starts loading param i from command. Dynamic params load
+	// in the background; list params load right away and return their rows
+	// as lines, or nil when the command failed.
+	LoadSource(i int, command string) []string
+	// ResetSource clears param i's value and options before its command
+	// changes.
+	ResetSource(i int)
+	// WaitForSource shows that param i's command needs values for names.
+	WaitForSource(i int, names []string)
+	// SourceValue returns param i's current value.
+	SourceValue(i int) string
+}
+
+// Sources tracks the commands behind a form's dynamic and list params: the
+// command each one last ran with referenced params filled in, whether it is
+// re-running in the background, and the cache or refresh status shown next
+// to it. Output of params with a cache_ttl is cached in cache, keyed by
+// command and the directory it runs in.
+type Sources struct {
+	params     []template.Param
+	cache      *cmdcache.Cache
+	workDir    string
+	commands   []string
+	refreshing []bool
+	notes      []string
+}
+
+// NewSources returns the source state for ps. workDir is where commands run
+// unless a param sets a workdir; cache may be nil to disable caching.
+func NewSources(ps []template.Param, cache *cmdcache.Cache, workDir string) Sources {
+	return Sources{
+		params:     ps,
+		cache:      cache,
+		workDir:    workDir,
+		commands:   make([]string, len(ps)),
+		refreshing: make([]bool, len(ps)),
+		notes:      make([]string, len(ps)),
+	}
+}
+
+// Command returns the command param i last ran, or "" when it has none.
+func (s *Sources) Command(i int) string {
+	if i < 0 || i >= len(s.commands) {
+		return ""
+	}
+	return s.commands[i]
+}
+
+// Refreshing reports whether param i's command is re-running while its
+// current options stay visible.
+func (s *Sources) Refreshing(i int) bool {
+	return i >= 0 && i < len(s.refreshing) && s.refreshing[i]
+}
+
+// Note returns the cache or refresh status of param i, or "".
+func (s *Sources) Note(i int) string {
+	if i < 0 || i >= len(s.notes) {
+		return ""
+	}
+	return s.notes[i]
+}
+
+// Init primes every dynamic and list param whose referenced values are
+// filled. Params come in fill order, so the values a command may reference
+// are known by the time it is reached.
+func (s *Sources) Init(f SourceForm) {
+	values := make(map[string]string, len(s.params))
+	for i, p := range s.params {
+		if p.Type == template.ParamDynamic || p.Type == template.ParamList {
+			if command, ready := RenderSource(p, values); ready {
+				s.Prime(f, i, command)
+			} else {
+				f.WaitForSource(i, Waiting(p, values))
+			}
+		}
+		if v := f.SourceValue(i); v != "" {
+			values[p.Name] = v
+		}
+	}
+}
+
+// Prime points param i at command. Output cached within the param's
+// cache_ttl is shown right away; expired output is shown while the command
+// re-runs in the background. Without a usable cache the form loads it.
+func (s *Sources) Prime(f SourceForm, i int, command string) {
+	s.commands[i] = command
+	s.refreshing[i] = false
+	s.notes[i] = ""
+
+	if s.cacheable(i) {
+		if e, ok := s.cache.Get(command, s.dir(i)); ok {
+			f.ShowSourceLines(i, e.Lines)
+			s.notes[i] = "cached"
+			if e.Expired(s.params[i].CacheTTL, time.Now()) {
+				s.refreshing[i] = true
+				s.notes[i] = "cached, refreshing…"
+			}
+			return
+		}
+	}
+	if lines := f.LoadSource(i, command); len(lines) > 0 {
+		s.store(i, lines)
+	}
+}
+
+// StartRefresh marks param i as re-running its command while its current
+// options stay visible.
+func (s *Sources) StartRefresh(i int) {
+	s.refreshing[i] = true
+	s.notes[i] = "refreshing…"
+}
+
+// Done records that command finished for param i with lines as output. It
+// reports false when the output should be ignored: the param has moved on
+// to another command, or a background refresh failed and the previous
+// options stay. Successful output is cached.
+func (s *Sources) Done(i int, command string, lines []string, err error) bool {
+	if i < 0 || i >= len(s.commands) || command != s.commands[i] {
+		// An upstream value changed while this command was running.
+		return false
+	}
+	refreshed := s.refreshing[i]
+	s.refreshing[i] = false
+	s.notes[i] = ""
+	if err != nil || len(lines) == 0 {
+		if refreshed {
+			what := "options"
+			if s.params[i].Type == template.ParamList {
+				what = "rows"
+			}
+			s.notes[i] = "refresh failed, showing previous " + what
+			return false
+		}
+		return true
+	}
+	s.store(i, lines)
+	return true
+}
+
+// Refresh re-primes dynamic and list params whose referenced values changed
+// since their commands last ran, and returns the indexes of those with a new
+// command. A param waiting on an empty value is cleared until it is filled
+// in. values is updated as params are cleared.
+func (s *Sources) Refresh(f SourceForm, values map[string]string) []int {
+	var changed []int
+	for i, p := range s.params {
+		command, stale := s.next(i, values)
+		if !stale {
+			continue
+		}
+		f.ResetSource(i)
+		if command == "" {
+			s.commands[i] = ""
+			s.refreshing[i] = false
+			s.notes[i] = ""
+			f.WaitForSource(i, Waiting(p, values))
+		} else {
+			s.Prime(f, i, command)
+			changed = append(changed, i)
+		}
+
+		if v := f.SourceValue(i); v != "" {
+			values[p.Name] = v
+		} else {
+			delete(values, p.Name)
+		}
+	}
+	return changed
+}
+
+// Stale returns the index of the first dynamic or list param whose command
+// no longer matches the values it references, or -1.
+func (s *Sources) Stale(values map[string]string) int {
+	for i := range s.params {
+		if _, stale := s.next(i, values); stale {
+			return i
+		}
+	}
+	return -1
+}
+
+// next returns the command param i should run for values, "" while a
+// referenced value is empty, and whether it differs from the last one.
+// Params without references never go stale.
+func (s *Sources) next(i int, values map[string]string) (string, bool) {
+	p := s.params[i]
+	if len(Dependencies(p)) == 0 {
+		return "", false
+	}
+	command, ready := RenderSource(p, values)
+	if !ready {
+		command = ""
+	}
+	return command, command != s.commands[i]
+}
+
+// cacheable reports whether param i's output may be cached: it has a
+// cache_ttl and its command does not contain a secret.
+func (s *Sources) cacheable(i int) bool {
+	return s.params[i].CacheTTL > 0 && !DependsOnSecret(s.params[i], s.params)
+}
+
+// store caches param i's command output. Caching is best-effort; a write
+// failure only costs a slower next load.
+func (s *Sources) store(i int, lines []string) {
+	if !s.cacheable(i) || len(lines) == 0 {
+		return
+	}
+	_ = s.cache.Put(s.commands[i], s.dir(i), lines, time.Now())
+}
+
+// dir is the directory param i's command runs in, which keys its cache
+// entries: the param's workdir when set, else the working directory.
+func (s *Sources) dir(i int) string {
+	if dir := EffectiveCommandOptions(s.params[i]).Dir; dir != "" {
+		return dir
+	}
+	return s.workDir
+}
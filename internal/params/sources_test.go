@@ -0,0 +1,101 @@
+package params
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fredriklanga/wf/internal/cmdcache"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeForm records what Sources asks of a form.
+type fakeForm struct {
+	values  []string
+	shown   map[int][]string
+	loaded  map[int]string
+	waiting map[int][]string
+}
+
+func newFakeForm(n int) *fakeForm {
+	return &fakeForm{
+		values:  make([]string, n),
+		shown:   make(map[int][]string),
+		loaded:  make(map[int]string),
+		waiting: make(map[int][]string),
+	}
+}
+
+func (f *fakeForm) ShowSourceLines(i int, lines []string) { f.shown[i] = lines }
+func (f *fakeForm) LoadSource(i int, command string) []string {
+	f.loaded[i] = command
+	return nil
+}
+func (f *fakeForm) ResetSource(i int)                   { f.values[i] = "" }
+func (f *fakeForm) WaitForSource(i int, names []string) { f.waiting[i] = names }
+func (f *fakeForm) SourceValue(i int) string            { return f.values[i] }
+
+func TestSources_CachedOutputAndRefresh(t *testing.T) {
+	ps := []template.Param{{Name: "ctx", Type: template.ParamDynamic, DynamicCmd: "kubectl config get-contexts", CacheTTL: time.Minute}}
+	cache := cmdcache.New(filepath.Join(t.TempDir(), "commands"))
+	require.NoError(t, cache.Put("kubectl config get-contexts", "/work", []string{"dev"}, time.Now().Add(-time.Hour)))
+
+	s := NewSources(ps, cache, "/work")
+	f := newFakeForm(1)
+	s.Init(f)
+	assert.Equal(t, []string{"dev"}, f.shown[0])
+	assert.True(t, s.Refreshing(0))
+	assert.Equal(t, "cached, refreshing…", s.Note(0))
+
+	// A failed refresh keeps the cached options.
+	assert.False(t, s.Done(0, "kubectl config get-contexts", nil, errors.New("boom")))
+	assert.Equal(t, "refresh failed, showing previous options", s.Note(0))
+
+	s.StartRefresh(0)
+	assert.True(t, s.Done(0, "kubectl config get-contexts", []string{"dev", "prod"}, nil))
+	assert.Empty(t, s.Note(0))
+	e, ok := cache.Get("kubectl config get-contexts", "/work")
+	require.True(t, ok)
+	assert.Equal(t, []string{"dev", "prod"}, e.Lines)
+}
+
+func TestSources_RefreshFollowsDependencies(t *testing.T) {
+	ps := []template.Param{
+		{Name: "namespace", Type: template.ParamText},
+		{Name: "pod", Type: template.ParamList, ListCmd: "kubectl get pods -n {{namespace}}"},
+	}
+	s := NewSources(ps, nil, "/work")
+	f := newFakeForm(2)
+	s.Init(f)
+	assert.Equal(t, []string{"namespace"}, f.waiting[1])
+	assert.Empty(t, s.Command(1))
+
+	values := map[string]string{"namespace": "prod"}
+	assert.Equal(t, 1, s.Stale(values))
+	assert.Equal(t, []int{1}, s.Refresh(f, values))
+	assert.Equal(t, "kubectl get pods -n prod", f.loaded[1])
+	assert.Equal(t, -1, s.Stale(values))
+
+	// Output for a command the param has moved on from is ignored.
+	assert.False(t, s.Done(1, "kubectl get pods -n dev", []string{"api"}, nil))
+}
+
+func TestSources_SecretDependenciesAreNotCached(t *testing.T) {
+	ps := []template.Param{
+		{Name: "token", Type: template.ParamSecret},
+		{Name: "db", Type: template.ParamDynamic, DynamicCmd: "vault list --token {{token}}", CacheTTL: time.Minute},
+	}
+	dir := filepath.Join(t.TempDir(), "commands")
+	cache := cmdcache.New(dir)
+	s := NewSources(ps, cache, "/work")
+	f := newFakeForm(2)
+	f.values[0] = "s3cret"
+	s.Init(f)
+
+	require.True(t, s.Done(1, "vault list --token s3cret", []string{"main"}, nil))
+	_, ok := cache.Get("vault list --token s3cret", "/work")
+	assert.False(t, ok)
+}
@@ -0,0 +1,170 @@
+package params
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// Validation kinds accepted in validate.kind.
+var validationKinds = map[string]bool{
+	"int":   true,
+	"float": true,
+	"port":  true,
+	"url":   true,
+	"file":  true,
+	"dir":   true,
+	"path":  true,
+}
+
+// ValidationFromArg converts an arg's stored validate block into the rules
+// carried on a template.Param. It returns nil when the arg has no rules.
+func ValidationFromArg(arg store.Arg) *template.Validation {
+	v := arg.Validate
+	if v == nil {
+		return nil
+	}
+	return &template.Validation{
+		Required: v.Required,
+		Kind:     v.Kind,
+		Pattern:  v.Pattern,
+		Min:      v.Min,
+		Max:      v.Max,
+	}
+}
+
+// CheckRules reports problems with the rules themselves, such as an unknown
+// kind or a pattern that does not compile. It is used when saving and
+// linting workflows so broken rules are caught before anyone fills a param.
+func CheckRules(v *template.Validation) error {
+	if v == nil {
+		return nil
+	}
+	if v.Kind != "" && !validationKinds[v.Kind] {
+		return fmt.Errorf("unknown validate kind %q (want int, float, port, url, file, dir, or path)", v.Kind)
+	}
+	if v.Pattern != "" {
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			return fmt.Errorf("invalid validate pattern: %w", err)
+		}
+	}
+	if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+		return fmt.Errorf("validate min %g is greater than max %g", *v.Min, *v.Max)
+	}
+	return nil
+}
+
+// Validate checks value against p's validation rules and returns a short
+// message suitable for showing next to the input, or nil when the value is
+// acceptable. Empty values pass unless the param is required.
+func Validate(p template.Param, value string) error {
+	v := p.Validation
+	if v == nil {
+		return nil
+	}
+	if strings.TrimSpace(value) == "" {
+		if v.Required {
+			return fmt.Errorf("required")
+		}
+		return nil
+	}
+	if err := CheckRules(v); err != nil {
+		return err
+	}
+
+	switch v.Kind {
+	case "int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		if err := checkRange(float64(n), v); err != nil {
+			return err
+		}
+	case "float":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		if err := checkRange(f, v); err != nil {
+			return err
+		}
+	case "port":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("must be a port between 1 and 65535")
+		}
+		if err := checkRange(float64(n), v); err != nil {
+			return err
+		}
+	case "url":
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("must be a URL such as https://example.com")
+		}
+	case "file", "dir", "path":
+		info, err := os.Stat(expandHome(value))
+		if err != nil {
+			return fmt.Errorf("%s does not exist", value)
+		}
+		if v.Kind == "file" && info.IsDir() {
+			return fmt.Errorf("%s is a directory, not a file", value)
+		}
+		if v.Kind == "dir" && !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", value)
+		}
+	}
+
+	if v.Pattern != "" {
+		re := regexp.MustCompile("^(?:" + v.Pattern + ")$")
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match %s", v.Pattern)
+		}
+	}
+	return nil
+}
+
+// FirstInvalid validates every param against its value and returns the
+// index of the first failure with one message per param ("" when valid).
+// The index is -1 when all values pass.
+func FirstInvalid(ps []template.Param, value func(i int) string) (int, []string) {
+	first := -1
+	msgs := make([]string, len(ps))
+	for i, p := range ps {
+		if err := Validate(p, value(i)); err != nil {
+			msgs[i] = err.Error()
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	return first, msgs
+}
+
+func checkRange(n float64, v *template.Validation) error {
+	switch {
+	case v.Min != nil && v.Max != nil && (n < *v.Min || n > *v.Max):
+		return fmt.Errorf("must be between %g and %g", *v.Min, *v.Max)
+	case v.Min != nil && n < *v.Min:
+		return fmt.Errorf("must be at least %g", *v.Min)
+	case v.Max != nil && n > *v.Max:
+		return fmt.Errorf("must be at most %g", *v.Max)
+	}
+	return nil
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
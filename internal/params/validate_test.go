@@ -0,0 +1,100 @@
+package params
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float(v float64) *float64 { return &v }
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f.txt")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0600))
+
+	tests := []struct {
+		name  string
+		rules template.Validation
+		value string
+		want  string // "" = valid
+	}{
+		{"no rules empty", template.Validation{}, "", ""},
+		{"required empty", template.Validation{Required: true}, "  ", "required"},
+		{"optional empty skips kind", template.Validation{Kind: "int"}, "", ""},
+		{"int ok", template.Validation{Kind: "int"}, "42", ""},
+		{"int bad", template.Validation{Kind: "int"}, "4.2", "must be a whole number"},
+		{"int range", template.Validation{Kind: "int", Min: float(1), Max: float(10)}, "11", "must be between 1 and 10"},
+		{"int min", template.Validation{Kind: "int", Min: float(1)}, "0", "must be at least 1"},
+		{"float max", template.Validation{Kind: "float", Max: float(0.5)}, "0.75", "must be at most 0.5"},
+		{"float bad", template.Validation{Kind: "float"}, "abc", "must be a number"},
+		{"port ok", template.Validation{Kind: "port"}, "8080", ""},
+		{"port out of range", template.Validation{Kind: "port"}, "70000", "must be a port between 1 and 65535"},
+		{"port custom min", template.Validation{Kind: "port", Min: float(1024)}, "80", "must be at least 1024"},
+		{"url ok", template.Validation{Kind: "url"}, "https://example.com/x", ""},
+		{"url bad", template.Validation{Kind: "url"}, "example.com", "must be a URL such as https://example.com"},
+		{"file ok", template.Validation{Kind: "file"}, file, ""},
+		{"file is dir", template.Validation{Kind: "file"}, dir, dir + " is a directory, not a file"},
+		{"dir ok", template.Validation{Kind: "dir"}, dir, ""},
+		{"dir is file", template.Validation{Kind: "dir"}, file, file + " is not a directory"},
+		{"path missing", template.Validation{Kind: "path"}, filepath.Join(dir, "nope"), filepath.Join(dir, "nope") + " does not exist"},
+		{"pattern ok", template.Validation{Pattern: `v\d+\.\d+`}, "v1.2", ""},
+		{"pattern anchored", template.Validation{Pattern: `v\d+`}, "xv1", `must match v\d+`},
+		{"pattern alternation anchored", template.Validation{Pattern: `dev|prod`}, "prodx", "must match dev|prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := tt.rules
+			err := Validate(template.Param{Name: "p", Validation: &rules}, tt.value)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	assert.NoError(t, CheckRules(nil))
+	assert.NoError(t, CheckRules(&template.Validation{Kind: "port", Pattern: `\d+`}))
+	assert.Error(t, CheckRules(&template.Validation{Kind: "email"}))
+	assert.Error(t, CheckRules(&template.Validation{Pattern: "("}))
+	assert.Error(t, CheckRules(&template.Validation{Min: float(5), Max: float(1)}))
+}
+
+func TestFirstInvalid(t *testing.T) {
+	ps := []template.Param{
+		{Name: "a"},
+		{Name: "b", Validation: &template.Validation{Kind: "int"}},
+		{Name: "c", Validation: &template.Validation{Required: true}},
+	}
+	values := []string{"x", "nope", ""}
+
+	idx, msgs := FirstInvalid(ps, func(i int) string { return values[i] })
+	assert.Equal(t, 1, idx)
+	assert.Equal(t, []string{"", "must be a whole number", "required"}, msgs)
+
+	values = []string{"x", "3", "y"}
+	idx, _ = FirstInvalid(ps, func(i int) string { return values[i] })
+	assert.Equal(t, -1, idx)
+}
+
+func TestOverlayMetadata_CarriesValidation(t *testing.T) {
+	params := OverlayMetadata("serve --port {{port:8080}}", []store.Arg{
+		{Name: "port", Validate: &store.Validation{Required: true, Kind: "port", Min: float(1024)}},
+	})
+
+	require.Len(t, params, 1)
+	require.NotNil(t, params[0].Validation)
+	assert.True(t, params[0].Validation.Required)
+	assert.Equal(t, "port", params[0].Validation.Kind)
+	assert.Equal(t, 1024.0, *params[0].Validation.Min)
+}
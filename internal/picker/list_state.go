@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/runner"
 	"github.com/fredriklanga/wf/internal/template"
 	"github.com/sahilm/fuzzy"
 )
@@ -29,6 +30,8 @@ type listPickerState struct {
 	loadErrDetail   string
 	showErrorDetail bool
 	emptyAfterSkip  bool
+	multi           bool            // several rows can be chosen with space
+	chosen          map[string]bool // raw rows chosen in multi mode
 }
 
 type listRowSource []parammeta.ListRow
@@ -36,30 +39,21 @@ type listRowSource []parammeta.ListRow
 func (rs listRowSource) String(i int) string { return rs[i].Raw }
 func (rs listRowSource) Len() int            { return len(rs) }
 
-func newListPickerState(p template.Param) listPickerState {
+// newListPickerState builds an empty list substate; rows are loaded once
+// the command's referenced params are known.
+func newListPickerState() listPickerState {
 	filter := textinput.New()
 	filter.Placeholder = "Filter rows..."
 	filter.CharLimit = 256
 	filter.Prompt = "filter> "
 
-	state := listPickerState{filterInput: filter}
-	state.load(p)
-	return state
+	return listPickerState{filterInput: filter}
 }
 
 func (s *listPickerState) load(p template.Param) {
-	s.loadErrShort = ""
-	s.loadErrDetail = ""
-	s.showErrorDetail = false
-	s.parseError = ""
-	s.confirmValue = ""
-	s.numberBuffer = ""
-	s.cursor = 0
-	s.allRows = nil
-	s.visibleRows = nil
-	s.emptyAfterSkip = false
+	s.reset()
 
-	source, err := parammeta.LoadListSource(p.ListCmd, p.ListSkipHeader)
+	source, err := parammeta.LoadListSourceWith(p.ListCmd, p.ListSkipHeader, parammeta.EffectiveCommandOptions(p))
 	if err != nil {
 		var sourceErr *parammeta.ListSourceError
 		if errors.As(err, &sourceErr) {
@@ -76,6 +70,41 @@ func (s *listPickerState) load(p template.Param) {
 	s.applyFilter()
 }
 
+// setRows replaces the selectable rows, keeping the current filter and any
+// chosen rows that are still present.
+func (s *listPickerState) setRows(rows []parammeta.ListRow) {
+	chosen := s.chosen
+	s.reset()
+	s.allRows = append([]parammeta.ListRow(nil), rows...)
+	for _, row := range s.allRows {
+		if chosen[row.Raw] {
+			s.toggleRow(row.Raw)
+		}
+	}
+	s.applyFilter()
+}
+
+// waitFor empties the list while params referenced by its command have no
+// value yet.
+func (s *listPickerState) waitFor(names []string) {
+	s.reset()
+	s.loadErrShort = fmt.Sprintf("fill %s first", strings.Join(names, ", "))
+}
+
+func (s *listPickerState) reset() {
+	s.loadErrShort = ""
+	s.loadErrDetail = ""
+	s.showErrorDetail = false
+	s.parseError = ""
+	s.confirmValue = ""
+	s.numberBuffer = ""
+	s.cursor = 0
+	s.allRows = nil
+	s.visibleRows = nil
+	s.emptyAfterSkip = false
+	s.chosen = nil
+}
+
 func (s *listPickerState) focus() {
 	s.filterInput.Focus()
 }
@@ -173,10 +202,34 @@ func (s *listPickerState) updateFilter(msg tea.KeyMsg) tea.Cmd {
 	return cmd
 }
 
+// toggle chooses or unchooses the row under the cursor in multi mode.
+func (s *listPickerState) toggle() {
+	if len(s.visibleRows) == 0 {
+		return
+	}
+	s.toggleRow(s.visibleRows[s.cursor].Raw)
+	s.numberBuffer = ""
+	s.parseError = ""
+}
+
+func (s *listPickerState) toggleRow(raw string) {
+	if s.chosen == nil {
+		s.chosen = make(map[string]bool)
+	}
+	if s.chosen[raw] {
+		delete(s.chosen, raw)
+		return
+	}
+	s.chosen[raw] = true
+}
+
 func (s *listPickerState) confirmSelection(p template.Param) bool {
 	if s.hasLoadError() {
 		return false
 	}
+	if s.multi && len(s.chosen) > 0 {
+		return s.confirmChosen(p)
+	}
 	if len(s.visibleRows) == 0 {
 		s.parseError = s.emptyMessage()
 		return false
@@ -203,6 +256,30 @@ func (s *listPickerState) confirmSelection(p template.Param) bool {
 	s.parseError = ""
 	s.numberBuffer = ""
 	s.confirmValue = value
+	if s.multi {
+		s.confirmValue = parammeta.JoinListValues([]string{value}, p.ListJoin, runner.Dialect().QuoteListItem)
+	}
+	return true
+}
+
+// confirmChosen joins the values of all chosen rows, in list order.
+func (s *listPickerState) confirmChosen(p template.Param) bool {
+	var values []string
+	for _, row := range s.allRows {
+		if !s.chosen[row.Raw] {
+			continue
+		}
+		value, err := parammeta.ExtractListValue(row.Raw, p.ListDelimiter, p.ListFieldIndex)
+		if err != nil {
+			s.parseError = err.Error()
+			s.confirmValue = ""
+			return false
+		}
+		values = append(values, value)
+	}
+	s.parseError = ""
+	s.numberBuffer = ""
+	s.confirmValue = parammeta.JoinListValues(values, p.ListJoin, runner.Dialect().QuoteListItem)
 	return true
 }
 
@@ -288,12 +365,26 @@ func (s listPickerState) renderLines() []string {
 			rowStyle = highlightStyle
 		}
 		label := fmt.Sprintf("%d. %s", i+1, truncateStr(s.visibleRows[i].Raw, listPreviewMaxWidth))
+		if s.multi {
+			mark := "[ ] "
+			if s.chosen[s.visibleRows[i].Raw] {
+				mark = "[x] "
+			}
+			label = mark + label
+		}
 		lines = append(lines, prefix+rowStyle.Render(label))
 	}
 
 	if len(s.visibleRows) > listVisibleMaxRows {
 		lines = append(lines, "    "+dimStyle.Render(fmt.Sprintf("showing %d/%d visible rows", end-start, len(s.visibleRows))))
 	}
+	if s.multi {
+		if len(s.chosen) > 0 {
+			lines = append(lines, "    "+dimStyle.Render(fmt.Sprintf("%d chosen", len(s.chosen))))
+		}
+		lines = append(lines, "    "+hintStyle.Render("type to filter  [space] toggle  [↑/↓] move  [enter] select"))
+		return lines
+	}
 	lines = append(lines, "    "+hintStyle.Render("type to filter  [1-9] jump  [↑/↓] move  [enter] select"))
 	return lines
 }
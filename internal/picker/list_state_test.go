@@ -130,3 +130,44 @@ func TestListPickerStateShowsCommandFailureDetailOnDemand(t *testing.T) {
 	m = updated.(Model)
 	assert.Contains(t, m.viewParamFill(), "stderr detail")
 }
+
+func TestListPickerStateMultiSelectJoinsChosenRowsWithPrefix(t *testing.T) {
+	wf := store.Workflow{
+		Name:    "logs",
+		Command: "kubectl logs {{pods}}",
+		Args: []store.Arg{{
+			Name:       "pods",
+			Type:       "list",
+			ListCmd:    "printf 'web-a\nweb-b\nweb-c\n'",
+			ListMulti:  true,
+			ListPrefix: "-l",
+		}},
+	}
+
+	m := Model{selected: &wf}
+	initParamFill(&m)
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	updated, _ := m.updateParamFill(space)
+	m = updated.(Model)
+	updated, _ = m.updateParamFill(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(Model)
+	updated, _ = m.updateParamFill(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(Model)
+	updated, _ = m.updateParamFill(space)
+	m = updated.(Model)
+
+	view := m.viewParamFill()
+	assert.Contains(t, view, "[x] 1. web-a")
+	assert.Contains(t, view, "[ ] 2. web-b")
+	assert.Contains(t, view, "2 chosen")
+	assert.Empty(t, m.paramListStates[0].filterInput.Value())
+
+	updated, _ = m.updateParamFill(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Contains(t, m.viewParamFill(), "Will insert: -l web-a -l web-c")
+
+	updated, _ = m.updateParamFill(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Equal(t, "kubectl logs -l web-a -l web-c", m.Result)
+}
@@ -9,9 +9,12 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/fredriklanga/wf/internal/cmdcache"
 	"github.com/fredriklanga/wf/internal/highlight"
+	parammeta "github.com/fredriklanga/wf/internal/params"
 	"github.com/fredriklanga/wf/internal/store"
 	"github.com/fredriklanga/wf/internal/template"
+	"github.com/fredriklanga/wf/internal/usage"
 	"github.com/sahilm/fuzzy"
 )
 
@@ -38,6 +41,7 @@ type SearchResult struct {
 type Model struct {
 	state     viewState
 	workflows []store.Workflow // all loaded workflows, immutable after init
+	usage     *usage.State     // frecency ranking; nil keeps store order
 
 	// Search state
 	searchInput textinput.Model
@@ -57,9 +61,19 @@ type Model struct {
 	paramLoading      []bool               // true while dynamic command is executing
 	paramFailed       []bool               // true if dynamic command failed (fallback to text)
 	paramListStates   []listPickerState    // dedicated list picker substate per list param
+	paramRecent       [][]string           // remembered values per param, newest first
+	paramRecentCursor []int                // position within paramRecent while cycling
+	paramInvalid      []bool               // failed validation on the last submit attempt
+	sources           parammeta.Sources    // commands behind dynamic/list params
+	cache             *cmdcache.Cache      // dynamic/list command output; nil disables caching
 
 	// Result is the final output command, read by caller after tea.Quit.
 	Result string
+	// Workflow and Values identify what produced Result, for the run log.
+	// Secret values are masked in Values and MaskedResult.
+	Workflow     string
+	Values       map[string]string
+	MaskedResult string
 
 	// Flash message (brief feedback, e.g. "Copied!")
 	flashMsg string
@@ -70,8 +84,9 @@ type Model struct {
 	maxVisible int
 }
 
-// New creates a new picker Model from a list of workflows.
-func New(workflows []store.Workflow) Model {
+// New creates a new picker Model from a list of workflows. When u is non-nil,
+// results are ranked by frecency and each selection is recorded in it.
+func New(workflows []store.Workflow, u *usage.State) Model {
 	ti := textinput.New()
 	ti.Placeholder = "Search workflows... (@tag to filter)"
 	ti.Focus()
@@ -83,6 +98,7 @@ func New(workflows []store.Workflow) Model {
 	m := Model{
 		state:       StateSearch,
 		workflows:   workflows,
+		usage:       u,
 		searchInput: ti,
 		preview:     vp,
 		maxVisible:  10,
@@ -93,6 +109,12 @@ func New(workflows []store.Workflow) Model {
 	return m
 }
 
+// SetCommandCache enables caching of dynamic and list param command output
+// for params that declare a cache_ttl.
+func (m *Model) SetCommandCache(c *cmdcache.Cache) {
+	m.cache = c
+}
+
 // Init returns the initial command. Bubble Tea automatically sends WindowSizeMsg.
 func (m Model) Init() tea.Cmd {
 	return textinput.Blink
@@ -121,13 +143,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case dynamicResultMsg:
 		return m.handleDynamicResult(msg)
+	case listResultMsg:
+		return m.handleListResult(msg)
+	case secretResultMsg:
+		return m.handleSecretResult(msg)
 
 	case tea.KeyMsg:
 		switch m.state {
 		case StateSearch:
 			return m.updateSearch(msg)
 		case StateParamFill:
-			return m.updateParamFill(msg)
+			prev := m.focusedParam
+			next, cmd := m.updateParamFill(msg)
+			nm, ok := next.(Model)
+			if !ok || nm.focusedParam == prev {
+				return next, cmd
+			}
+			// Leaving a param may change values that other params'
+			// commands reference.
+			refresh := nm.refreshSources()
+			return nm, tea.Batch(cmd, refresh)
 		}
 	}
 
@@ -160,10 +195,16 @@ func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		wf := m.results[m.cursor].Workflow
-		params := template.ExtractParams(wf.Command)
-		if len(params) == 0 {
+		if len(parammeta.ForWorkflow(wf)) == 0 {
 			// Zero-param workflow: output directly
-			m.Result = wf.Command
+			m.selected = &wf
+			if err := m.finish(nil); err != nil {
+				m.selected = nil
+				m.flashMsg = "✗ " + err.Error()
+				return m, tea.Tick(3*time.Second, func(time.Time) tea.Msg {
+					return clearFlashMsg{}
+				})
+			}
 			return m, tea.Quit
 		}
 		// Transition to param fill
@@ -181,7 +222,7 @@ func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.results) == 0 {
 			return m, nil
 		}
-		cmd := m.results[m.cursor].Workflow.Command
+		cmd := m.results[m.cursor].Workflow.Template()
 		if err := clipboard.WriteAll(cmd); err != nil {
 			m.flashMsg = "✗ clipboard error"
 		} else {
@@ -205,6 +246,12 @@ func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *Model) performSearch(raw string) {
 	tagFilter, fuzzyQuery := ParseQuery(raw)
 	matches := Search(fuzzyQuery, tagFilter, m.workflows)
+	if m.usage != nil {
+		now := time.Now()
+		RankByScore(matches, m.workflows, func(name string) float64 {
+			return m.usage.Score(name, now)
+		})
+	}
 
 	m.results = make([]SearchResult, len(matches))
 	for i, match := range matches {
@@ -220,10 +267,11 @@ func (m *Model) performSearch(raw string) {
 	m.updatePreview()
 }
 
-// updatePreview sets the preview pane content to the selected workflow's command.
+// updatePreview sets the preview pane content to the selected workflow's
+// command, with context variables such as {{@git.branch}} resolved.
 func (m *Model) updatePreview() {
 	if len(m.results) > 0 && m.cursor < len(m.results) {
-		cmd := m.results[m.cursor].Workflow.Command
+		cmd := template.ResolveContext(m.results[m.cursor].Workflow.Template())
 		m.preview.SetContent(highlight.Shell(cmd, m.tokenStyles))
 	} else {
 		m.preview.SetContent("")
@@ -233,25 +281,41 @@ func (m *Model) updatePreview() {
 // handleDynamicResult processes a completed dynamic parameter command.
 func (m Model) handleDynamicResult(msg dynamicResultMsg) (tea.Model, tea.Cmd) {
 	idx := msg.paramIndex
-	if idx < 0 || idx >= len(m.paramLoading) {
+	if !m.sources.Done(idx, msg.command, msg.options, msg.err) {
 		return m, nil
 	}
 
 	m.paramLoading[idx] = false
-
 	if msg.err != nil || len(msg.options) == 0 {
 		// Failed: fall back to free-text input
 		m.paramFailed[idx] = true
 		m.paramInputs[idx].Placeholder = m.params[idx].Name
 		m.paramInputs[idx].SetValue("")
+		return m, m.refreshSources()
+	}
+
+	// Success: populate option list, keeping the current choice or
+	// preselecting the default when present
+	m.ShowSourceLines(idx, msg.options)
+	return m, m.refreshSources()
+}
+
+// handleSecretResult fills a secret parameter resolved by its secret_cmd.
+// On failure the input stays empty so the value can be typed instead.
+func (m Model) handleSecretResult(msg secretResultMsg) (tea.Model, tea.Cmd) {
+	idx := msg.paramIndex
+	if idx < 0 || idx >= len(m.paramLoading) {
 		return m, nil
 	}
 
-	// Success: populate option list
-	m.paramOptions[idx] = msg.options
-	m.paramOptionCursor[idx] = 0
-	m.paramInputs[idx].SetValue(msg.options[0])
-	m.paramInputs[idx].Placeholder = ""
+	m.paramLoading[idx] = false
+	m.paramInputs[idx].Placeholder = m.params[idx].Name
+	if msg.err != nil {
+		m.paramFailed[idx] = true
+		return m, nil
+	}
+	m.paramInputs[idx].SetValue(msg.value)
+	m.paramInputs[idx].CursorEnd()
 	return m, nil
 }
 
@@ -0,0 +1,253 @@
+package picker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fredriklanga/wf/internal/cmdcache"
+	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_RanksByUsageAndRecordsSelection(t *testing.T) {
+	workflows := sampleWorkflows()
+	path := filepath.Join(t.TempDir(), "usage.json")
+	u := usage.New(path)
+	u.Record("git-push", time.Now())
+
+	m := New(workflows, u)
+	require.NotEmpty(t, m.results)
+	assert.Equal(t, "git-push", m.results[0].Workflow.Name)
+
+	// Select docker-build (second in store order, no params) twice so it
+	// overtakes git-push.
+	for i := 0; i < 2; i++ {
+		m = New(workflows, u)
+		for j, r := range m.results {
+			if r.Workflow.Name == "docker-build" {
+				m.cursor = j
+			}
+		}
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = updated.(Model)
+		assert.Equal(t, "docker build -t myimage .", m.Result)
+		assert.Equal(t, "docker-build", m.Workflow)
+	}
+
+	reloaded, err := usage.Load(path)
+	require.NoError(t, err)
+	now := time.Now()
+	assert.Greater(t, reloaded.Score("docker-build", now), reloaded.Score("git-push", now))
+
+	m = New(workflows, reloaded)
+	assert.Equal(t, "docker-build", m.results[0].Workflow.Name)
+}
+
+func TestNew_NilUsageKeepsStoreOrder(t *testing.T) {
+	workflows := sampleWorkflows()
+	m := New(workflows, nil)
+	require.Len(t, m.results, 3)
+	for i, r := range m.results {
+		assert.Equal(t, workflows[i].Name, r.Workflow.Name)
+	}
+}
+
+func TestParamFill_PrefillsAndRemembersValues(t *testing.T) {
+	workflows := []store.Workflow{{Name: "greet", Command: "echo {{name:world}}"}}
+	path := filepath.Join(t.TempDir(), "usage.json")
+	u := usage.New(path)
+	u.RememberValues("greet", map[string]string{"name": "alice"})
+
+	m := New(workflows, u)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	require.Equal(t, StateParamFill, m.state)
+	assert.Equal(t, "alice", m.paramInputs[0].Value())
+	assert.Contains(t, m.View(), "(last used)")
+
+	m.paramInputs[0].SetValue("bob")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Equal(t, "echo bob", m.Result)
+
+	reloaded, err := usage.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "alice"}, reloaded.RecentValues("greet", "name"))
+}
+
+func TestParamFill_SecretIsMaskedEverywhereButResult(t *testing.T) {
+	workflows := []store.Workflow{{
+		Name:    "api",
+		Command: "login {{user}} {{token}}",
+		Args:    []store.Arg{{Name: "token", Type: "secret"}},
+	}}
+	path := filepath.Join(t.TempDir(), "usage.json")
+	u := usage.New(path)
+
+	m := New(workflows, u)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	m.paramInputs[0].SetValue("me")
+	m.paramInputs[1].SetValue("hunter2")
+	assert.NotContains(t, m.View(), "hunter2")
+
+	m.focusedParam = 1
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+
+	assert.Equal(t, "login me hunter2", m.Result)
+	assert.Equal(t, "login me "+parammeta.SecretMask, m.MaskedResult)
+	assert.Equal(t, parammeta.SecretMask, m.Values["token"])
+
+	reloaded, err := usage.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"me"}, reloaded.RecentValues("api", "user"))
+	assert.Empty(t, reloaded.RecentValues("api", "token"))
+}
+
+func TestParamFill_ValidationBlocksSubmit(t *testing.T) {
+	workflows := []store.Workflow{{
+		Name:    "serve",
+		Command: "serve --port {{port}}",
+		Args:    []store.Arg{{Name: "port", Validate: &store.Validation{Kind: "port"}}},
+	}}
+
+	m := New(workflows, nil)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	m.paramInputs[0].SetValue("http")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Nil(t, cmd, "invalid values do not quit")
+	assert.Empty(t, m.Result)
+	assert.Contains(t, m.View(), "must be a port between 1 and 65535")
+
+	m.paramInputs[0].SetValue("8080")
+	assert.NotContains(t, m.View(), "must be a port", "the message clears once the value is fixed")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Equal(t, "serve --port 8080", m.Result)
+}
+
+func TestParamFill_DependentListReloadsWhenUpstreamChanges(t *testing.T) {
+	workflows := []store.Workflow{{
+		Name:    "logs",
+		Command: "kubectl logs {{pod}}",
+		Args: []store.Arg{
+			{Name: "pod", Type: "list", ListCmd: "printf '%s-a\\n%s-b\\n' {{ns}} {{ns}}"},
+		},
+	}}
+
+	m := New(workflows, nil)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	require.Len(t, m.params, 2)
+	assert.Equal(t, "ns", m.params[0].Name, "referenced params are filled first")
+	assert.Equal(t, "fill ns first", m.paramListStates[1].loadErrShort)
+
+	m.paramInputs[0].SetValue("web")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(Model)
+	require.Len(t, m.paramListStates[1].allRows, 2)
+	assert.Equal(t, "web-a", m.paramListStates[1].allRows[0].Raw)
+
+	m.paramInputs[1].SetValue("web-b")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(Model)
+	m.paramInputs[0].SetValue("api")
+
+	// Submitting with a stale dependent moves focus there and reloads it.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Empty(t, m.Result)
+	assert.Equal(t, 1, m.focusedParam)
+	assert.Equal(t, "", m.paramInputs[1].Value(), "the old selection is cleared")
+	assert.Equal(t, "api-a", m.paramListStates[1].allRows[0].Raw)
+}
+
+func TestParamFill_CachedDynamicOptionsRefreshInBackground(t *testing.T) {
+	workflows := []store.Workflow{{
+		Name:    "ctx",
+		Command: "kubectl config use-context {{ctx}}",
+		Args:    []store.Arg{{Name: "ctx", Type: "dynamic", DynamicCmd: "echo dev; echo prod", CacheTTL: "10m"}},
+	}}
+	cache := cmdcache.New(filepath.Join(t.TempDir(), "commands"))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	// Fresh cache: options appear immediately and nothing runs.
+	require.NoError(t, cache.Put("echo dev; echo prod", wd, []string{"old-a", "old-b"}, time.Now()))
+	m := New(workflows, nil)
+	m.SetCommandCache(cache)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	assert.Nil(t, cmd)
+	assert.Equal(t, []string{"old-a", "old-b"}, m.paramOptions[0])
+	assert.Contains(t, m.View(), "(cached)")
+
+	// ctrl+r re-runs the command and replaces the cached options.
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updated.(Model)
+	require.NotNil(t, cmd)
+	assert.Contains(t, m.View(), "refreshing")
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	assert.Equal(t, []string{"dev", "prod"}, m.paramOptions[0])
+	e, ok := cache.Get("echo dev; echo prod", wd)
+	require.True(t, ok)
+	assert.Equal(t, []string{"dev", "prod"}, e.Lines)
+
+	// Expired cache: stale options show while the command re-runs.
+	require.NoError(t, cache.Put("echo dev; echo prod", wd, []string{"stale"}, time.Now().Add(-time.Hour)))
+	m = New(workflows, nil)
+	m.SetCommandCache(cache)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	require.NotNil(t, cmd)
+	assert.Equal(t, "stale", m.paramInputs[0].Value())
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	assert.Equal(t, "dev", m.paramInputs[0].Value())
+}
+
+func TestParamFill_SourcesUsingSecretsAreNotCached(t *testing.T) {
+	workflows := []store.Workflow{{
+		Name:    "db",
+		Command: "psql {{db}}",
+		Args: []store.Arg{
+			{Name: "token", Type: "secret", Default: "s3cret"},
+			{Name: "db", Type: "list", ListCmd: "echo {{token}}-main", CacheTTL: "10m"},
+		},
+	}}
+	dir := filepath.Join(t.TempDir(), "commands")
+	m := New(workflows, nil)
+	m.SetCommandCache(cmdcache.New(dir))
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+
+	require.NotEmpty(t, m.paramListStates[1].allRows)
+	assert.Equal(t, "s3cret-main", m.paramListStates[1].allRows[0].Raw)
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err), "nothing is written to the cache")
+}
+
+func TestSearch_InvalidConditionIsShownNotOutput(t *testing.T) {
+	workflows := []store.Workflow{{
+		Name:  "release",
+		Steps: []store.Step{{Command: "make build"}, {Command: "make publish", When: "&&"}},
+	}}
+	m := New(workflows, nil)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+
+	assert.Empty(t, m.Result)
+	assert.Equal(t, StateSearch, m.state)
+	assert.Contains(t, m.View(), "step 2 when")
+}
@@ -1,9 +1,8 @@
 package picker
 
 import (
-	"context"
 	"fmt"
-	"os/exec"
+	"os"
 	"strings"
 	"time"
 
@@ -11,19 +10,36 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/runner"
 	"github.com/fredriklanga/wf/internal/template"
 )
 
 // dynamicResultMsg is sent when a dynamic parameter's shell command completes.
 type dynamicResultMsg struct {
 	paramIndex int
+	command    string
 	options    []string
 	err        error
 }
 
+// secretResultMsg is sent when a secret parameter's secret_cmd completes.
+type secretResultMsg struct {
+	paramIndex int
+	value      string
+	err        error
+}
+
 // initParamFill prepares the parameter fill state from the selected workflow.
 func initParamFill(m *Model) {
-	m.params = parammeta.OverlayMetadata(m.selected.Command, m.selected.Args)
+	m.params = parammeta.ForWorkflow(*m.selected)
+	wfName := m.selected.Name
+	m.paramRecent = parammeta.ApplyRecent(m.params, func(name string) []string {
+		return m.usage.RecentValues(wfName, name)
+	})
+	m.paramRecentCursor = make([]int, len(m.params))
+	m.paramInvalid = make([]bool, len(m.params))
+	workDir, _ := os.Getwd()
+	m.sources = parammeta.NewSources(m.params, m.cache, workDir)
 
 	n := len(m.params)
 	m.paramInputs = make([]textinput.Model, n)
@@ -62,12 +78,9 @@ func initParamFill(m *Model) {
 			// The value is set programmatically from option selection
 			ti.Placeholder = ""
 
-		case template.ParamDynamic:
-			m.paramLoading[i] = true
-			ti.Placeholder = "Loading..."
-
 		case template.ParamList:
-			m.paramListStates[i] = newListPickerState(p)
+			m.paramListStates[i] = newListPickerState()
+			m.paramListStates[i].multi = p.ListMulti
 			if p.Default != "" {
 				ti.SetValue(p.Default)
 				ti.TextStyle = defaultTextStyle
@@ -75,6 +88,18 @@ func initParamFill(m *Model) {
 			}
 			ti.Placeholder = "Choose from list"
 
+		case template.ParamSecret:
+			ti.EchoMode = textinput.EchoPassword
+			ti.EchoCharacter = '•'
+			if v, ok := parammeta.SecretFromEnv(p); ok {
+				ti.SetValue(v)
+			} else if p.SecretCmd != "" {
+				m.paramLoading[i] = true
+				ti.Placeholder = "Resolving..."
+			} else if p.Default != "" {
+				ti.SetValue(p.Default)
+			}
+
 		default: // ParamText
 			if p.Default != "" {
 				ti.SetValue(p.Default)
@@ -85,6 +110,8 @@ func initParamFill(m *Model) {
 
 		m.paramInputs[i] = ti
 	}
+
+	m.sources.Init(m)
 	m.focusedParam = 0
 	m.focusParam(0)
 }
@@ -94,47 +121,38 @@ func initParamFill(m *Model) {
 func initParamFillCmds(m *Model) []tea.Cmd {
 	var cmds []tea.Cmd
 	for i, p := range m.params {
-		if p.Type == template.ParamDynamic {
+		if cmd := m.sourceCmd(i); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if p.Type == template.ParamSecret && m.paramLoading[i] {
 			idx := i
-			dynCmd := p.DynamicCmd
+			param := p
 			cmds = append(cmds, func() tea.Msg {
-				return executeDynamic(idx, dynCmd)
+				v, err := parammeta.SecretFromCmd(param)
+				return secretResultMsg{paramIndex: idx, value: v, err: err}
 			})
 		}
 	}
 	return cmds
 }
 
-// executeDynamic runs a shell command with a 5-second timeout and returns options.
-func executeDynamic(paramIndex int, command string) dynamicResultMsg {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// executeDynamic runs a dynamic param's command with the param's command
+// options and returns its options.
+func executeDynamic(paramIndex int, command string, opts template.CommandOptions) dynamicResultMsg {
+	msg := dynamicResultMsg{paramIndex: paramIndex, command: command}
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
-	output, err := cmd.Output()
+	options, err := parammeta.RunDynamicCommand(command, opts)
 	if err != nil {
-		return dynamicResultMsg{
-			paramIndex: paramIndex,
-			err:        fmt.Errorf("dynamic command failed: %w", err),
-		}
+		msg.err = err
+		return msg
 	}
-
-	options := strings.FieldsFunc(string(output), func(r rune) bool { return r == '\n' || r == '\r' })
-	for i := range options {
-		options[i] = strings.TrimSpace(options[i])
-	}
-
 	if len(options) == 0 {
-		return dynamicResultMsg{
-			paramIndex: paramIndex,
-			err:        fmt.Errorf("dynamic command returned no output"),
-		}
+		msg.err = fmt.Errorf("dynamic command returned no output")
+		return msg
 	}
 
-	return dynamicResultMsg{
-		paramIndex: paramIndex,
-		options:    options,
-	}
+	msg.options = options
+	return msg
 }
 
 func (m *Model) focusParam(index int) {
@@ -169,7 +187,7 @@ func (m *Model) moveFocus(next int) {
 }
 
 // liveRender builds the command preview with current input values.
-func liveRender(m *Model) string {
+func liveRender(m *Model) (string, error) {
 	values := make(map[string]string)
 	for i, p := range m.params {
 		v := m.paramInputs[i].Value()
@@ -177,7 +195,7 @@ func liveRender(m *Model) string {
 			values[p.Name] = v
 		}
 	}
-	return template.Render(m.selected.Command, values)
+	return runner.RenderMasked(*m.selected, parammeta.MaskSecrets(m.params, values))
 }
 
 // updateParamFill handles key events in the parameter fill state.
@@ -194,6 +212,9 @@ func (m Model) updateParamFill(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "shift+tab":
 		m.moveFocus(m.focusedParam - 1)
 		return m, nil
+
+	case "ctrl+r":
+		return m, m.forceRefresh(m.focusedParam)
 	}
 
 	if m.isListPickerParam(m.focusedParam) {
@@ -213,7 +234,10 @@ func (m Model) updateParamFill(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		}
-		// For text params, pass through to textinput
+		// For text params, step to a newer remembered value
+		if m.cycleRecent(-1) {
+			return m, nil
+		}
 		var cmd tea.Cmd
 		m.paramInputs[m.focusedParam], cmd = m.paramInputs[m.focusedParam].Update(msg)
 		m.updateFocusedTextStyle()
@@ -231,7 +255,10 @@ func (m Model) updateParamFill(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		}
-		// For text params, pass through to textinput
+		// For text params, step to an older remembered value
+		if m.cycleRecent(1) {
+			return m, nil
+		}
 		var cmd tea.Cmd
 		m.paramInputs[m.focusedParam], cmd = m.paramInputs[m.focusedParam].Update(msg)
 		m.updateFocusedTextStyle()
@@ -240,14 +267,13 @@ func (m Model) updateParamFill(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		// If on last param or all filled, render and quit
 		if m.focusedParam == len(m.paramInputs)-1 || allParamsFilled(m) {
-			values := make(map[string]string)
-			for i, p := range m.params {
-				v := m.paramInputs[i].Value()
-				if v != "" {
-					values[p.Name] = v
-				}
+			if !m.checkParams() {
+				return m, nil
+			}
+			if err := m.finish(collectValues(m)); err != nil {
+				// The preview shows why the workflow cannot be rendered.
+				return m, nil
 			}
-			m.Result = template.Render(m.selected.Command, values)
 			return m, tea.Quit
 		}
 		// Otherwise advance to next param (same as tab)
@@ -282,18 +308,24 @@ func (m Model) updateFocusedListParam(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "down":
 		state.moveCursor(1)
 		return m, nil
+	case " ":
+		if state.multi {
+			state.toggle()
+			return m, nil
+		}
+		cmd := state.updateFilter(msg)
+		return m, cmd
 	case "enter":
 		if state.hasConfirmation() {
 			m.paramInputs[m.focusedParam].SetValue(state.acceptConfirmedValue())
 			if m.focusedParam == len(m.paramInputs)-1 || allParamsFilled(m) {
-				values := make(map[string]string)
-				for i, p := range m.params {
-					v := m.paramInputs[i].Value()
-					if v != "" {
-						values[p.Name] = v
-					}
+				if !m.checkParams() {
+					return m, nil
+				}
+				if err := m.finish(collectValues(m)); err != nil {
+					// The preview shows why the workflow cannot be rendered.
+					return m, nil
 				}
-				m.Result = template.Render(m.selected.Command, values)
 				return m, tea.Quit
 			}
 			m.moveFocus(m.focusedParam + 1)
@@ -322,6 +354,50 @@ func (m Model) updateFocusedListParam(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// cycleRecent replaces the focused text param's value with the next (delta
+// 1) or previous (delta -1) remembered value. It reports false when the
+// param has no remembered values to cycle through.
+func (m *Model) cycleRecent(delta int) bool {
+	i := m.focusedParam
+	if i < 0 || i >= len(m.paramRecent) || len(m.paramRecent[i]) == 0 {
+		return false
+	}
+	if m.paramTypes[i] != template.ParamText && !m.paramFailed[i] {
+		return false
+	}
+	recent := m.paramRecent[i]
+	cur := (m.paramRecentCursor[i] + delta + len(recent)) % len(recent)
+	m.paramRecentCursor[i] = cur
+	m.paramInputs[i].SetValue(recent[cur])
+	m.paramInputs[i].CursorEnd()
+	m.updateFocusedTextStyle()
+	return true
+}
+
+// valueNote labels a param value that came from a remembered or declared
+// default, so the user can tell it apart from something they typed.
+func (m Model) valueNote(i int, value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(m.paramRecent[i]) > 0 && value == m.paramRecent[i][0] {
+		return dimStyle.Render(" (last used)")
+	}
+	if value == m.params[i].Default {
+		return dimStyle.Render(" (default)")
+	}
+	return ""
+}
+
+// sourceNote shows whether a dynamic or list param's options came from the
+// cache or are being refreshed.
+func (m Model) sourceNote(i int) string {
+	if m.sources.Note(i) == "" {
+		return ""
+	}
+	return dimStyle.Render(" (" + m.sources.Note(i) + ")")
+}
+
 func (m *Model) updateFocusedTextStyle() {
 	if m.focusedParam < 0 || m.focusedParam >= len(m.paramInputs) {
 		return
@@ -378,7 +454,10 @@ func (m Model) viewParamFill() string {
 	sections = append(sections, "")
 
 	// Live-rendered command
-	rendered := liveRender(&m)
+	rendered, err := liveRender(&m)
+	if err != nil {
+		rendered = errorStyle.Render("✗ " + err.Error())
+	}
 	sections = append(sections, previewBorderStyle.Render(rendered))
 	sections = append(sections, "")
 
@@ -403,10 +482,19 @@ func (m Model) viewParamFill() string {
 		switch {
 		case m.paramTypes[i] == template.ParamDynamic && m.paramLoading[i]:
 			// Dynamic param still loading
-			loadingText := dimStyle.Render("Loading... (" + p.DynamicCmd + ")")
+			loadingText := dimStyle.Render("Loading... (" + m.sources.Command(i) + ")")
 			row := prefix + style.Render(label+": ") + loadingText
 			sections = append(sections, row)
 
+		case m.paramTypes[i] == template.ParamDynamic && !m.paramLoading[i] && m.sources.Command(i) == "":
+			// Dynamic param waiting for params its command references
+			note := dimStyle.Render(" (options load when you move on)")
+			if waiting := parammeta.Waiting(p, collectValues(m)); len(waiting) > 0 {
+				note = dimStyle.Render(" (fill " + strings.Join(waiting, ", ") + " first, or type manually)")
+			}
+			row := prefix + style.Render(label+": ") + m.paramInputs[i].View() + note
+			sections = append(sections, row)
+
 		case m.paramTypes[i] == template.ParamDynamic && m.paramFailed[i]:
 			// Dynamic param failed — show error and text input
 			inputView := m.paramInputs[i].View()
@@ -414,6 +502,14 @@ func (m Model) viewParamFill() string {
 			row := prefix + style.Render(label+": ") + inputView + errNote
 			sections = append(sections, row)
 
+		case m.paramTypes[i] == template.ParamSecret && m.paramLoading[i]:
+			row := prefix + style.Render(label+": ") + dimStyle.Render("Resolving... ("+p.SecretCmd+")")
+			sections = append(sections, row)
+
+		case m.paramTypes[i] == template.ParamSecret && m.paramFailed[i]:
+			row := prefix + style.Render(label+": ") + m.paramInputs[i].View() + dimStyle.Render(" (secret command failed, type manually)")
+			sections = append(sections, row)
+
 		case m.isListParam(i):
 			// Enum or successful dynamic — show option list
 			opts := m.paramOptions[i]
@@ -425,10 +521,7 @@ func (m Model) viewParamFill() string {
 				selectedVal = opts[cur]
 			}
 
-			var descStr string
-			if p.Default != "" && selectedVal == p.Default {
-				descStr = dimStyle.Render(" (default)")
-			}
+			descStr := m.valueNote(i, selectedVal) + m.sourceNote(i)
 
 			row := prefix + style.Render(label+": ") + highlightStyle.Render(selectedVal) + descStr
 			sections = append(sections, row)
@@ -491,10 +584,7 @@ func (m Model) viewParamFill() string {
 				valueStyle = highlightStyle
 			}
 
-			var descStr string
-			if p.Default != "" && selectedVal == p.Default {
-				descStr = dimStyle.Render(" (default)")
-			}
+			descStr := m.valueNote(i, selectedVal) + m.sourceNote(i)
 
 			row := prefix + style.Render(label+": ") + valueStyle.Render(selectedVal) + descStr
 			sections = append(sections, row)
@@ -505,12 +595,17 @@ func (m Model) viewParamFill() string {
 		default:
 			// Text param — unchanged
 			inputView := m.paramInputs[i].View()
-			var descStr string
-			if p.Default != "" && m.paramInputs[i].Value() == p.Default {
-				descStr = dimStyle.Render(" (default)")
-			}
+			descStr := m.valueNote(i, m.paramInputs[i].Value())
 			row := prefix + style.Render(label+": ") + inputView + descStr
 			sections = append(sections, row)
+			if isFocused && len(m.paramRecent[i]) > 1 {
+				recent := dimStyle.Render("recent: " + strings.Join(m.paramRecent[i], " · "))
+				sections = append(sections, "    "+recent)
+			}
+		}
+
+		if msg := m.paramError(i); msg != "" {
+			sections = append(sections, "    "+errorStyle.Render("✗ "+msg))
 		}
 	}
 
@@ -524,13 +619,92 @@ func (m Model) viewParamFill() string {
 			break
 		}
 	}
+	var hint string
 	if m.isListPickerParam(m.focusedParam) {
-		sections = append(sections, hintStyle.Render("  type to filter  1-9 select row  enter confirm  tab next  esc cancel"))
+		hint = "  type to filter  1-9 select row  enter confirm  tab next"
+	} else if m.focusedParam < len(m.paramRecent) && len(m.paramRecent[m.focusedParam]) > 1 && !m.isListParam(m.focusedParam) {
+		hint = "  ↑↓ recent values  tab next  shift+tab prev  enter paste to shell"
 	} else if hasListParam {
-		sections = append(sections, hintStyle.Render("  ↑↓ select option  tab next  shift+tab prev  enter paste to shell  esc cancel"))
+		hint = "  ↑↓ select option  tab next  shift+tab prev  enter paste to shell"
 	} else {
-		sections = append(sections, hintStyle.Render("  tab next  shift+tab prev  enter paste to shell  esc cancel"))
+		hint = "  tab next  shift+tab prev  enter paste to shell"
+	}
+	if m.sources.Command(m.focusedParam) != "" {
+		hint += "  ctrl+r refresh"
 	}
+	sections = append(sections, hintStyle.Render(hint+"  esc cancel"))
 
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
+
+// checkParams validates every param before submission. On failure it
+// marks the failing params, focuses the first one, and reports false.
+func (m *Model) checkParams() bool {
+	// An upstream value was edited without leaving the field; focus the
+	// dependent param so its options are reloaded before submitting.
+	if i := m.sources.Stale(collectValues(*m)); i >= 0 {
+		m.moveFocus(i)
+		return false
+	}
+	idx, msgs := parammeta.FirstInvalid(m.params, func(i int) string {
+		return m.paramInputs[i].Value()
+	})
+	for i := range msgs {
+		m.paramInvalid[i] = msgs[i] != ""
+	}
+	if idx < 0 {
+		return true
+	}
+	m.moveFocus(idx)
+	return false
+}
+
+// paramError returns the current validation message for a param that
+// failed the last submit attempt, so the message clears as soon as the
+// value is fixed.
+func (m Model) paramError(i int) string {
+	if i >= len(m.paramInvalid) || !m.paramInvalid[i] {
+		return ""
+	}
+	if err := parammeta.Validate(m.params[i], m.paramInputs[i].Value()); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// collectValues returns the non-empty param input values keyed by name.
+func collectValues(m Model) map[string]string {
+	values := make(map[string]string)
+	for i, p := range m.params {
+		v := m.paramInputs[i].Value()
+		if v != "" {
+			values[p.Name] = v
+		}
+	}
+	return values
+}
+
+// finish renders the selected workflow with values, records what was chosen
+// so the caller can log it, and updates frecency and remembered values.
+// Nothing is recorded when the workflow cannot be rendered.
+func (m *Model) finish(values map[string]string) error {
+	masked := parammeta.MaskSecrets(m.params, values)
+	result, err := runner.Render(*m.selected, values)
+	if err != nil {
+		return err
+	}
+	maskedResult, err := runner.RenderMasked(*m.selected, masked)
+	if err != nil {
+		return err
+	}
+	m.Result = result
+	m.Workflow = m.selected.Name
+	m.Values = masked
+	m.MaskedResult = maskedResult
+	if m.usage != nil {
+		m.usage.Record(m.selected.Name, time.Now())
+		m.usage.RememberValues(m.selected.Name, parammeta.Rememberable(m.params, values))
+		_ = m.usage.Save()
+	}
+	return nil
+}
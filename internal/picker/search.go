@@ -1,6 +1,7 @@
 package picker
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/fredriklanga/wf/internal/store"
@@ -37,7 +38,7 @@ type WorkflowSource []store.Workflow
 // String returns the searchable text for workflow at index i.
 func (ws WorkflowSource) String(i int) string {
 	w := ws[i]
-	return w.Name + " " + w.Description + " " + strings.Join(w.Tags, " ") + " " + w.Command
+	return w.Name + " " + w.Description + " " + strings.Join(w.Tags, " ") + " " + w.Template()
 }
 
 // Len returns the number of workflows in the source.
@@ -84,6 +85,32 @@ func Search(query, tagFilter string, workflows []store.Workflow) []fuzzy.Match {
 	return results
 }
 
+// RankByScore reorders matches so that, among matches with equal fuzzy
+// scores, workflows with a higher score(name) come first. Empty-query
+// matches all share a fuzzy score of zero, so they end up ordered purely by
+// score. The sort is stable: equal scores keep their existing order.
+func RankByScore(matches []fuzzy.Match, workflows []store.Workflow, score func(name string) float64) {
+	if score == nil || len(matches) < 2 {
+		return
+	}
+	scores := make([]float64, len(workflows))
+	for i := range workflows {
+		scores[i] = score(workflows[i].Name)
+	}
+	at := func(m fuzzy.Match) float64 {
+		if m.Index < 0 || m.Index >= len(scores) {
+			return 0
+		}
+		return scores[m.Index]
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return at(matches[i]) > at(matches[j])
+	})
+}
+
 // filterByTag returns workflows that have a tag matching the given filter
 // (case-insensitive comparison).
 func filterByTag(workflows []store.Workflow, tag string) []store.Workflow {
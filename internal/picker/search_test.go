@@ -190,3 +190,35 @@ func TestWorkflowSource(t *testing.T) {
 		assert.Contains(t, s, "kubectl apply -f deployment.yaml")
 	})
 }
+
+func TestRankByScore_EmptyQueryOrdersByScore(t *testing.T) {
+	workflows := sampleWorkflows()
+	scores := map[string]float64{"git-push": 5, "docker-build": 1}
+
+	results := Search("", "", workflows)
+	RankByScore(results, workflows, func(name string) float64 { return scores[name] })
+
+	require.Len(t, results, 3)
+	assert.Equal(t, "git-push", workflows[results[0].Index].Name)
+	assert.Equal(t, "docker-build", workflows[results[1].Index].Name)
+	assert.Equal(t, "deploy-app", workflows[results[2].Index].Name, "unscored workflows keep store order at the end")
+}
+
+func TestRankByScore_BreaksFuzzyTiesOnly(t *testing.T) {
+	workflows := []store.Workflow{
+		{Name: "logs-api", Command: "kubectl logs api"},
+		{Name: "logs-web", Command: "kubectl logs web"},
+		{Name: "lint", Command: "golangci-lint run"},
+	}
+	scores := map[string]float64{"logs-web": 3, "lint": 100}
+
+	results := Search("logs", "", workflows)
+	require.GreaterOrEqual(t, len(results), 2)
+	RankByScore(results, workflows, func(name string) float64 { return scores[name] })
+
+	assert.Equal(t, "logs-web", workflows[results[0].Index].Name, "frecency breaks the tie between equal matches")
+	assert.Equal(t, "logs-api", workflows[results[1].Index].Name)
+	for i := 1; i < len(results); i++ {
+		assert.GreaterOrEqual(t, results[i-1].Score, results[i].Score, "fuzzy score still dominates")
+	}
+}
@@ -0,0 +1,157 @@
+package picker
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	parammeta "github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// listResultMsg is sent when a list parameter's command completes in the
+// background.
+type listResultMsg struct {
+	paramIndex int
+	command    string
+	source     parammeta.ListSource
+	err        error
+}
+
+// sourceCmd returns the command that loads or refreshes param i in the
+// background, or nil when nothing needs to run.
+func (m Model) sourceCmd(i int) tea.Cmd {
+	p := m.params[i]
+	command := m.sources.Command(i)
+	switch {
+	case p.Type == template.ParamDynamic && (m.paramLoading[i] || m.sources.Refreshing(i)):
+		opts := parammeta.EffectiveCommandOptions(p)
+		return func() tea.Msg {
+			return executeDynamic(i, command, opts)
+		}
+	case p.Type == template.ParamList && m.sources.Refreshing(i):
+		skip := p.ListSkipHeader
+		opts := parammeta.EffectiveCommandOptions(p)
+		return func() tea.Msg {
+			source, err := parammeta.LoadListSourceWith(command, skip, opts)
+			return listResultMsg{paramIndex: i, command: command, source: source, err: err}
+		}
+	}
+	return nil
+}
+
+// forceRefresh re-runs param i's command, bypassing the cache, while its
+// current options stay visible.
+func (m *Model) forceRefresh(i int) tea.Cmd {
+	if i < 0 || i >= len(m.params) || m.sources.Command(i) == "" || m.paramLoading[i] || m.sources.Refreshing(i) {
+		return nil
+	}
+	switch m.params[i].Type {
+	case template.ParamDynamic:
+		if m.paramFailed[i] {
+			m.paramFailed[i] = false
+			m.paramLoading[i] = true
+			m.paramInputs[i].SetValue("")
+			return m.sourceCmd(i)
+		}
+	case template.ParamList:
+	default:
+		return nil
+	}
+	m.sources.StartRefresh(i)
+	return m.sourceCmd(i)
+}
+
+// ShowSourceLines shows command output as param i's options. A dynamic
+// param keeps its current value when it is still offered, and otherwise
+// preselects its default.
+func (m *Model) ShowSourceLines(i int, lines []string) {
+	switch m.params[i].Type {
+	case template.ParamDynamic:
+		m.paramLoading[i] = false
+		m.paramFailed[i] = false
+		m.paramOptions[i] = lines
+		cur := -1
+		for j, opt := range lines {
+			if opt == m.paramInputs[i].Value() {
+				cur = j
+				break
+			}
+		}
+		if cur < 0 {
+			cur = 0
+			for j, opt := range lines {
+				if opt == m.params[i].Default {
+					cur = j
+					break
+				}
+			}
+		}
+		m.paramOptionCursor[i] = cur
+		m.paramInputs[i].SetValue(lines[cur])
+		m.paramInputs[i].Placeholder = ""
+	case template.ParamList:
+		m.paramListStates[i].setRows(parammeta.RowsFromLines(lines))
+	}
+}
+
+// LoadSource starts a dynamic param loading in the background, or loads a
+// list param's rows right away.
+func (m *Model) LoadSource(i int, command string) []string {
+	p := m.params[i]
+	switch p.Type {
+	case template.ParamDynamic:
+		m.paramLoading[i] = true
+		m.paramInputs[i].Placeholder = "Loading..."
+	case template.ParamList:
+		p.ListCmd = command
+		state := &m.paramListStates[i]
+		state.load(p)
+		if !state.hasLoadError() && len(state.allRows) > 0 {
+			return parammeta.ListSource{Rows: state.allRows}.Lines()
+		}
+	}
+	return nil
+}
+
+// ResetSource clears param i's value and options.
+func (m *Model) ResetSource(i int) {
+	m.paramInputs[i].SetValue("")
+	m.paramOptions[i] = nil
+	m.paramOptionCursor[i] = 0
+	m.paramFailed[i] = false
+	m.paramLoading[i] = false
+}
+
+// WaitForSource shows that param i needs values for names first.
+func (m *Model) WaitForSource(i int, names []string) {
+	m.paramInputs[i].Placeholder = m.params[i].Name
+	if m.params[i].Type == template.ParamList {
+		m.paramListStates[i].waitFor(names)
+	}
+}
+
+// SourceValue returns param i's current value.
+func (m *Model) SourceValue(i int) string {
+	return m.paramInputs[i].Value()
+}
+
+// handleListResult applies a background list refresh. On failure the
+// previous rows stay selectable.
+func (m Model) handleListResult(msg listResultMsg) (tea.Model, tea.Cmd) {
+	idx := msg.paramIndex
+	if !m.sources.Done(idx, msg.command, msg.source.Lines(), msg.err) || msg.err != nil {
+		return m, nil
+	}
+	m.paramListStates[idx].setRows(msg.source.Rows)
+	return m, nil
+}
+
+// refreshSources re-runs dynamic and list commands whose referenced param
+// values changed since they last ran.
+func (m *Model) refreshSources() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, i := range m.sources.Refresh(m, collectValues(*m)) {
+		if cmd := m.sourceCmd(i); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return tea.Batch(cmds...)
+}
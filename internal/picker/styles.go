@@ -55,4 +55,7 @@ var (
 
 	// hintStyle renders footer hint text.
 	hintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	// errorStyle renders inline param validation errors.
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
 )
@@ -0,0 +1,246 @@
+// Package runlog records workflow executions in a local JSON Lines file so
+// they can be queried later (wf history) and used to rank workflows and
+// suggest parameter values.
+package runlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Actions describing how a rendered command left wf.
+const (
+	ActionPrint = "print" // written to stdout for shell integration to paste
+	ActionCopy  = "copy"  // copied to the clipboard
+	ActionPaste = "paste" // pasted to the prompt from wf manage
+	ActionRun   = "run"   // executed by wf run
+)
+
+// Entry is a single recorded use of a workflow.
+type Entry struct {
+	Time     time.Time         `json:"time"`
+	Workflow string            `json:"workflow"`
+	Action   string            `json:"action"`
+	Command  string            `json:"command"`
+	Params   map[string]string `json:"params,omitempty"`
+	Dir      string            `json:"dir,omitempty"`
+	ExitCode *int              `json:"exit_code,omitempty"` // nil when wf did not run the command
+}
+
+// Failed reports whether the entry recorded a non-zero exit code.
+func (e Entry) Failed() bool {
+	return e.ExitCode != nil && *e.ExitCode != 0
+}
+
+// MaxSize is how large the log file may grow. Once an append takes it past
+// MaxSize, the oldest entries are dropped until it is half that size.
+const MaxSize = 4 << 20
+
+// Log is an append-only run log backed by a JSON Lines file.
+// A nil *Log discards every entry, so callers can log unconditionally.
+type Log struct {
+	path    string
+	maxSize int64
+}
+
+// New returns a Log that reads and writes the file at path.
+func New(path string) *Log {
+	return &Log{path: path, maxSize: MaxSize}
+}
+
+// Path returns the log file location.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes e to the end of the log. Zero Time and empty Dir are filled
+// with the current time and working directory.
+func (l *Log) Append(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Dir == "" {
+		e.Dir, _ = os.Getwd()
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding run log entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("creating run log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening run log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("writing run log: %w", err)
+	}
+	if info, err := f.Stat(); err == nil && l.maxSize > 0 && info.Size() > l.maxSize {
+		return l.trim()
+	}
+	return nil
+}
+
+// trim rewrites the log with the newest lines that fit in half of maxSize.
+// The new file replaces the old one by rename, so readers never see a
+// partial log.
+func (l *Log) trim() error {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return fmt.Errorf("reading run log: %w", err)
+	}
+	keep := l.maxSize / 2
+	start := 0
+	if int64(len(data)) > keep {
+		start = len(data) - int(keep)
+		// Start after a line break so no entry is cut in half.
+		if i := bytes.IndexByte(data[start-1:], '\n'); i >= 0 {
+			start += i
+		} else {
+			start = len(data)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), ".history-*")
+	if err != nil {
+		return fmt.Errorf("trimming run log: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data[start:]); err != nil {
+		tmp.Close()
+		return fmt.Errorf("trimming run log: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("trimming run log: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("trimming run log: %w", err)
+	}
+	return nil
+}
+
+// Entries returns every entry in the log, oldest first. A missing log file
+// yields no entries; malformed lines are skipped.
+func (l *Log) Entries() ([]Entry, error) {
+	if l == nil {
+		return nil, nil
+	}
+	f, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening run log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Workflow == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading run log: %w", err)
+	}
+	return entries, nil
+}
+
+// Filter narrows a Query. Zero fields match everything.
+type Filter struct {
+	Workflow   string    // exact workflow name
+	Since      time.Time // entries at or after this time
+	FailedOnly bool      // only entries with a non-zero exit code
+	Limit      int       // maximum entries returned; 0 = no limit
+}
+
+// Query returns the entries matching f, newest first.
+func Query(entries []Entry, f Filter) []Entry {
+	var out []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if f.Workflow != "" && e.Workflow != f.Workflow {
+			continue
+		}
+		if !f.Since.IsZero() && e.Time.Before(f.Since) {
+			continue
+		}
+		if f.FailedOnly && !e.Failed() {
+			continue
+		}
+		out = append(out, e)
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+	}
+	return out
+}
+
+// Stat summarises the recorded uses of one workflow.
+type Stat struct {
+	Workflow string
+	Count    int
+	Runs     int // entries executed by wf, i.e. with a known exit code
+	Failures int
+	LastUsed time.Time
+}
+
+// Stats aggregates entries per workflow, most used first. Ties are broken
+// by the most recent use, then by name.
+func Stats(entries []Entry) []Stat {
+	byName := make(map[string]*Stat)
+	var order []string
+	for _, e := range entries {
+		st, ok := byName[e.Workflow]
+		if !ok {
+			st = &Stat{Workflow: e.Workflow}
+			byName[e.Workflow] = st
+			order = append(order, e.Workflow)
+		}
+		st.Count++
+		if e.ExitCode != nil {
+			st.Runs++
+		}
+		if e.Failed() {
+			st.Failures++
+		}
+		if e.Time.After(st.LastUsed) {
+			st.LastUsed = e.Time
+		}
+	}
+
+	stats := make([]Stat, 0, len(order))
+	for _, name := range order {
+		stats = append(stats, *byName[name])
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		if !stats[i].LastUsed.Equal(stats[j].LastUsed) {
+			return stats[i].LastUsed.After(stats[j].LastUsed)
+		}
+		return stats[i].Workflow < stats[j].Workflow
+	})
+	return stats
+}
@@ -0,0 +1,148 @@
+package runlog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestAppendAndEntriesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+	log := New(path)
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, log.Append(Entry{
+		Time:     ts,
+		Workflow: "deploy",
+		Action:   ActionRun,
+		Command:  "deploy prod",
+		Params:   map[string]string{"env": "prod"},
+		Dir:      "/srv",
+		ExitCode: intPtr(2),
+	}))
+	require.NoError(t, log.Append(Entry{Workflow: "ls", Action: ActionPrint, Command: "ls -la"}))
+
+	entries, err := log.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.True(t, entries[0].Time.Equal(ts))
+	assert.Equal(t, "deploy", entries[0].Workflow)
+	assert.Equal(t, map[string]string{"env": "prod"}, entries[0].Params)
+	assert.Equal(t, "/srv", entries[0].Dir)
+	require.NotNil(t, entries[0].ExitCode)
+	assert.Equal(t, 2, *entries[0].ExitCode)
+	assert.True(t, entries[0].Failed())
+
+	assert.Nil(t, entries[1].ExitCode, "picked commands have no exit code")
+	assert.False(t, entries[1].Time.IsZero(), "time is filled on append")
+	assert.NotEmpty(t, entries[1].Dir, "working directory is filled on append")
+}
+
+func TestEntriesMissingFileAndMalformedLines(t *testing.T) {
+	dir := t.TempDir()
+
+	entries, err := New(filepath.Join(dir, "absent.jsonl")).Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	path := filepath.Join(dir, "history.jsonl")
+	data := "not json\n{\"workflow\":\"ok\",\"command\":\"true\"}\n{\"command\":\"nameless\"}\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	entries, err = New(path).Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ok", entries[0].Workflow)
+}
+
+func TestNilLogIsNoop(t *testing.T) {
+	var log *Log
+	assert.NoError(t, log.Append(Entry{Workflow: "x"}))
+	entries, err := log.Entries()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestQuery(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: base, Workflow: "a", ExitCode: intPtr(0)},
+		{Time: base.Add(time.Hour), Workflow: "b", ExitCode: intPtr(1)},
+		{Time: base.Add(2 * time.Hour), Workflow: "a"},
+		{Time: base.Add(3 * time.Hour), Workflow: "a", ExitCode: intPtr(3)},
+	}
+
+	got := Query(entries, Filter{})
+	require.Len(t, got, 4)
+	assert.True(t, got[0].Time.Equal(base.Add(3*time.Hour)), "newest first")
+
+	got = Query(entries, Filter{Workflow: "a", Limit: 2})
+	require.Len(t, got, 2)
+	assert.True(t, got[1].Time.Equal(base.Add(2*time.Hour)))
+
+	got = Query(entries, Filter{Since: base.Add(90 * time.Minute)})
+	assert.Len(t, got, 2)
+
+	got = Query(entries, Filter{FailedOnly: true})
+	require.Len(t, got, 2)
+	assert.Equal(t, "a", got[0].Workflow)
+	assert.Equal(t, "b", got[1].Workflow)
+}
+
+func TestStats(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: base, Workflow: "a", ExitCode: intPtr(0)},
+		{Time: base.Add(time.Hour), Workflow: "b", ExitCode: intPtr(1)},
+		{Time: base.Add(2 * time.Hour), Workflow: "a"},
+		{Time: base.Add(3 * time.Hour), Workflow: "c"},
+		{Time: base.Add(4 * time.Hour), Workflow: "b"},
+	}
+
+	stats := Stats(entries)
+	require.Len(t, stats, 3)
+
+	// a and b both have 2 uses; b was used more recently.
+	assert.Equal(t, "b", stats[0].Workflow)
+	assert.Equal(t, 2, stats[0].Count)
+	assert.Equal(t, 1, stats[0].Runs)
+	assert.Equal(t, 1, stats[0].Failures)
+	assert.True(t, stats[0].LastUsed.Equal(base.Add(4*time.Hour)))
+
+	assert.Equal(t, "a", stats[1].Workflow)
+	assert.Equal(t, 0, stats[1].Failures)
+	assert.Equal(t, "c", stats[2].Workflow)
+}
+
+func TestAppendTrimsOldestEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	log := &Log{path: path, maxSize: 2048}
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, log.Append(Entry{Workflow: "wf-" + strconv.Itoa(i), Command: "echo", Dir: "/tmp"}))
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(2048))
+
+	entries, err := log.Entries()
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	assert.Equal(t, "wf-99", entries[len(entries)-1].Workflow)
+	assert.Less(t, len(entries), 100)
+
+	// Every kept line is a whole entry.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, len(entries), strings.Count(string(data), "\n"))
+}
@@ -0,0 +1,90 @@
+// Package runner executes rendered workflow commands in the user's shell.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Options controls how a command is executed. Zero values inherit the
+// current process's standard streams, working directory, and environment.
+type Options struct {
+	Shell  string   // interpreter path; empty = user's login shell
+	Dir    string   // working directory; empty = current directory
+	Env    []string // extra KEY=VALUE entries appended to os.Environ()
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Shell returns the interpreter used to run workflow commands.
+// $SHELL wins when set so commands behave exactly as they would when
+// pasted to the prompt; otherwise a platform default is used.
+func Shell() string {
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return sh
+	}
+	return defaultShell()
+}
+
+// Command builds an *exec.Cmd that runs command through the given shell.
+// The argument style (-c vs -Command) is chosen from the shell's base name.
+func Command(ctx context.Context, shell, command string) *exec.Cmd {
+	if shell == "" {
+		shell = Shell()
+	}
+	return exec.CommandContext(ctx, shell, shellArgs(shell, command)...)
+}
+
+// Run executes command and returns its exit code. A non-zero exit code is
+// not an error; err is only set when the shell could not be started.
+func Run(ctx context.Context, command string, opts Options) (int, error) {
+	cmd := Command(ctx, opts.Shell, command)
+	cmd.Dir = opts.Dir
+	cmd.Env = append(os.Environ(), opts.Env...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	if cmd.Stdin == nil {
+		cmd.Stdin = os.Stdin
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+
+	err := cmd.Run()
+	if err == nil {
+		return 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	return -1, fmt.Errorf("running %s: %w", filepath.Base(cmd.Path), err)
+}
+
+// shellArgs returns the interpreter arguments for running a single command string.
+func shellArgs(shell, command string) []string {
+	name := shell
+	if idx := strings.LastIndexAny(name, `/\`); idx >= 0 {
+		name = name[idx+1:]
+	}
+	switch strings.ToLower(name) {
+	case "pwsh", "pwsh.exe", "powershell", "powershell.exe":
+		return []string{"-NoProfile", "-Command", command}
+	case "cmd", "cmd.exe":
+		return []string{"/C", command}
+	default:
+		return []string{"-c", command}
+	}
+}
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_PassesThroughExitCode(t *testing.T) {
+	var stdout bytes.Buffer
+	code, err := Run(context.Background(), "echo hello; exit 3", Options{Shell: "sh", Stdout: &stdout})
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "hello\n", stdout.String())
+}
+
+func TestRun_AppendsExtraEnv(t *testing.T) {
+	var stdout bytes.Buffer
+	code, err := Run(context.Background(), "printf %s \"$WF_TEST_VALUE\"", Options{
+		Shell:  "sh",
+		Env:    []string{"WF_TEST_VALUE=from-env"},
+		Stdout: &stdout,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "from-env", stdout.String())
+}
+
+func TestRun_MissingShellIsAnError(t *testing.T) {
+	_, err := Run(context.Background(), "true", Options{Shell: "/nonexistent/wf-shell"})
+	require.Error(t, err)
+}
+
+func TestShellArgs(t *testing.T) {
+	assert.Equal(t, []string{"-c", "ls"}, shellArgs("/bin/zsh", "ls"))
+	assert.Equal(t, []string{"-c", "ls"}, shellArgs("/usr/local/bin/fish", "ls"))
+	assert.Equal(t, []string{"-NoProfile", "-Command", "ls"}, shellArgs("pwsh", "ls"))
+	assert.Equal(t, []string{"/C", "dir"}, shellArgs(`C:\Windows\System32\cmd.exe`, "dir"))
+}
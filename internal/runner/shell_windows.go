@@ -0,0 +1,7 @@
+//go:build windows
+
+package runner
+
+func defaultShell() string {
+	return "powershell.exe"
+}
@@ -0,0 +1,246 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/fredriklanga/wf/internal/template"
+)
+
+// StepStatus reports what happened to a single step during a run.
+type StepStatus int
+
+const (
+	StepPending   StepStatus = iota // Not run (an earlier step stopped the run)
+	StepSucceeded                   // Exited 0
+	StepFailed                      // Exited non-zero or could not start
+	StepSkipped                     // when: condition evaluated to false
+	StepDeclined                    // Confirmation prompt was declined
+)
+
+// String returns a short human-readable label for the status.
+func (s StepStatus) String() string {
+	switch s {
+	case StepSucceeded:
+		return "ok"
+	case StepFailed:
+		return "failed"
+	case StepSkipped:
+		return "skipped"
+	case StepDeclined:
+		return "declined"
+	default:
+		return "not run"
+	}
+}
+
+// PlannedStep is a workflow step with its command rendered and its when:
+// condition already evaluated against the filled param values.
+type PlannedStep struct {
+	Index           int
+	Name            string
+	Command         string
+	Skip            bool
+	ContinueOnError bool
+	Confirm         bool
+}
+
+// StepResult pairs a planned step with the outcome of running it.
+type StepResult struct {
+	Step     PlannedStep
+	Status   StepStatus
+	ExitCode int
+	Err      error
+}
+
+// StepHooks lets callers drive confirmation prompts and progress output.
+// All hooks are optional; a nil Confirm declines every confirmation step.
+type StepHooks struct {
+	Confirm func(PlannedStep) bool
+	OnStart func(PlannedStep)
+	OnDone  func(StepResult)
+}
+
+// Plan renders every step of a multi-step workflow and evaluates its when:
+// condition. Single-step workflows produce one unnamed step.
+func Plan(wf store.Workflow, values map[string]string) ([]PlannedStep, error) {
+	return plan(wf, values, QuoteOptions(wf))
+}
+
+// PlanMasked is Plan for display, with secret values already replaced by a
+// mask. The mask is inserted unquoted.
+func PlanMasked(wf store.Workflow, masked map[string]string) ([]PlannedStep, error) {
+	return plan(wf, masked, maskedQuoteOptions(wf))
+}
+
+func plan(wf store.Workflow, values map[string]string, quote template.QuoteOptions) ([]PlannedStep, error) {
+	if !wf.IsMultiStep() {
+		return []PlannedStep{{
+			Name:    wf.Name,
+			Command: template.RenderQuoted(wf.Command, values, quote),
+		}}, nil
+	}
+
+	planned := make([]PlannedStep, len(wf.Steps))
+	for i, step := range wf.Steps {
+		ok, err := template.EvalCondition(step.When, values)
+		if err != nil {
+			return nil, fmt.Errorf("step %d when: %w", i+1, err)
+		}
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+		planned[i] = PlannedStep{
+			Index:           i,
+			Name:            name,
+			Command:         template.RenderQuoted(step.Command, values, quote),
+			Skip:            !ok,
+			ContinueOnError: step.ContinueOnError,
+			Confirm:         step.Confirm,
+		}
+	}
+	return planned, nil
+}
+
+// QuoteOptions returns how param values are quoted when wf is rendered:
+// for the dialect of the shell that runs workflow commands, leaving args
+// marked raw unchanged. A multi-select list is not quoted as a whole, which
+// would turn its values into one word; JoinListValues has already quoted
+// each value, unless list_quote is "none".
+func QuoteOptions(wf store.Workflow) template.QuoteOptions {
+	opts := template.QuoteOptions{Dialect: Dialect()}
+	for _, arg := range wf.Args {
+		if arg.Raw || arg.ListMulti {
+			if opts.Raw == nil {
+				opts.Raw = make(map[string]bool)
+			}
+			opts.Raw[arg.Name] = true
+		}
+	}
+	return opts
+}
+
+// Dialect returns the quoting dialect of the shell that runs workflow
+// commands.
+func Dialect() template.Dialect {
+	return template.DialectFromShell(Shell())
+}
+
+// maskedQuoteOptions is QuoteOptions with secret args left unquoted.
+func maskedQuoteOptions(wf store.Workflow) template.QuoteOptions {
+	opts := QuoteOptions(wf)
+	for _, arg := range wf.Args {
+		if arg.Type == "secret" {
+			if opts.Raw == nil {
+				opts.Raw = make(map[string]bool)
+			}
+			opts.Raw[arg.Name] = true
+		}
+	}
+	return opts
+}
+
+// Render produces a single pasteable command for a workflow. Multi-step
+// workflows are joined with && in order, skipping steps whose condition is
+// false; continue_on_error steps are grouped as "{ cmd || true; }". Confirmation
+// prompts cannot be expressed in a pasted command and are dropped. An invalid
+// when: condition is an error, as it is for Plan.
+func Render(wf store.Workflow, values map[string]string) (string, error) {
+	return render(wf, values, QuoteOptions(wf))
+}
+
+// RenderMasked is Render for display, with secret values already replaced
+// by a mask. The mask is inserted unquoted.
+func RenderMasked(wf store.Workflow, masked map[string]string) (string, error) {
+	return render(wf, masked, maskedQuoteOptions(wf))
+}
+
+func render(wf store.Workflow, values map[string]string, quote template.QuoteOptions) (string, error) {
+	if !wf.IsMultiStep() {
+		return template.RenderQuoted(wf.Command, values, quote), nil
+	}
+
+	var parts []string
+	for i, step := range wf.Steps {
+		ok, err := template.EvalCondition(step.When, values)
+		if err != nil {
+			return "", fmt.Errorf("step %d when: %w", i+1, err)
+		}
+		if !ok {
+			continue
+		}
+		cmd := template.RenderQuoted(step.Command, values, quote)
+		if step.ContinueOnError {
+			cmd = ignoreFailure(cmd, quote.Dialect)
+		}
+		parts = append(parts, cmd)
+	}
+	return strings.Join(parts, " && "), nil
+}
+
+// ignoreFailure groups cmd with "|| true" so the fallback only covers cmd.
+// Left ungrouped, "a && b || true && c" would also swallow a failure of a.
+func ignoreFailure(cmd string, d template.Dialect) string {
+	if d == template.DialectFish {
+		return "begin; " + cmd + " || true; end"
+	}
+	return "{ " + cmd + " || true; }"
+}
+
+// RunSteps executes planned steps in order. It returns a result for every
+// step plus the overall exit code: 0 when every required step succeeded,
+// otherwise the exit code of the step that stopped the run (1 when a
+// confirmation was declined).
+func RunSteps(ctx context.Context, steps []PlannedStep, opts Options, hooks StepHooks) ([]StepResult, int) {
+	results := make([]StepResult, len(steps))
+	for i := range steps {
+		results[i] = StepResult{Step: steps[i], Status: StepPending}
+	}
+
+	for i, step := range steps {
+		if step.Skip {
+			results[i].Status = StepSkipped
+			notify(hooks, results[i])
+			continue
+		}
+
+		if step.Confirm && (hooks.Confirm == nil || !hooks.Confirm(step)) {
+			results[i].Status = StepDeclined
+			notify(hooks, results[i])
+			return results, 1
+		}
+
+		if hooks.OnStart != nil {
+			hooks.OnStart(step)
+		}
+		code, err := Run(ctx, step.Command, opts)
+		results[i].ExitCode = code
+		results[i].Err = err
+		if err == nil && code == 0 {
+			results[i].Status = StepSucceeded
+			notify(hooks, results[i])
+			continue
+		}
+
+		results[i].Status = StepFailed
+		notify(hooks, results[i])
+		if step.ContinueOnError {
+			continue
+		}
+		if code <= 0 {
+			code = 1
+		}
+		return results, code
+	}
+
+	return results, 0
+}
+
+func notify(hooks StepHooks, result StepResult) {
+	if hooks.OnDone != nil {
+		hooks.OnDone(result)
+	}
+}
@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/fredriklanga/wf/internal/params"
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func releaseWorkflow() store.Workflow {
+	return store.Workflow{
+		Name: "release",
+		Steps: []store.Step{
+			{Name: "build", Command: "echo build {{version}}"},
+			{Name: "notify", Command: "echo notify", When: "env == prod", ContinueOnError: true},
+			{Name: "tag", Command: "echo tag v{{version}}", Confirm: true},
+		},
+	}
+}
+
+func TestPlan_RendersStepsAndEvaluatesConditions(t *testing.T) {
+	plan, err := Plan(releaseWorkflow(), map[string]string{"version": "1.2", "env": "dev"})
+	require.NoError(t, err)
+	require.Len(t, plan, 3)
+	assert.Equal(t, "echo build 1.2", plan[0].Command)
+	assert.True(t, plan[1].Skip)
+	assert.True(t, plan[2].Confirm)
+}
+
+func mustRender(t *testing.T, wf store.Workflow, values map[string]string) string {
+	t.Helper()
+	got, err := Render(wf, values)
+	require.NoError(t, err)
+	return got
+}
+
+func TestRender_JoinsActiveSteps(t *testing.T) {
+	wf := releaseWorkflow()
+	assert.Equal(t, "echo build 1.2 && echo tag v1.2", mustRender(t, wf, map[string]string{"version": "1.2", "env": "dev"}))
+	assert.Equal(t, "echo build 1.2 && { echo notify || true; } && echo tag v1.2", mustRender(t, wf, map[string]string{"version": "1.2", "env": "prod"}))
+}
+
+func TestRender_ContinueOnErrorDoesNotHideEarlierFailure(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	wf := store.Workflow{
+		Name: "release",
+		Steps: []store.Step{
+			{Name: "build", Command: "false"},
+			{Name: "notify", Command: "echo notify", ContinueOnError: true},
+			{Name: "tag", Command: "echo tag"},
+		},
+	}
+	var out bytes.Buffer
+	code, err := Run(context.Background(), mustRender(t, wf, nil), Options{Shell: "sh", Stdout: &out})
+	require.NoError(t, err)
+	assert.Equal(t, 1, code, "the failed build stops the command")
+	assert.Empty(t, out.String(), "later steps do not run")
+
+	wf.Steps[0].Command = "echo build"
+	wf.Steps[1].Command = "false"
+	out.Reset()
+	code, err = Run(context.Background(), mustRender(t, wf, nil), Options{Shell: "sh", Stdout: &out})
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "build\ntag\n", out.String(), "a continue_on_error failure is ignored")
+}
+
+func TestRender_InvalidConditionIsAnError(t *testing.T) {
+	wf := releaseWorkflow()
+	wf.Steps[1].When = "env == prod && "
+
+	_, planErr := Plan(wf, map[string]string{"env": "prod"})
+	require.Error(t, planErr)
+	_, err := Render(wf, map[string]string{"env": "prod"})
+	assert.EqualError(t, err, planErr.Error())
+	_, err = RenderMasked(wf, map[string]string{"env": "prod"})
+	assert.Error(t, err)
+}
+
+func TestRender_QuotesValuesUnlessRaw(t *testing.T) {
+	t.Setenv("SHELL", "/bin/bash")
+	wf := store.Workflow{
+		Name:    "commit",
+		Command: `git commit {{flags}} -m {{msg}} && echo "{{msg}}"`,
+		Args:    []store.Arg{{Name: "flags", Raw: true}},
+	}
+	got := mustRender(t, wf, map[string]string{"flags": "-a --no-verify", "msg": "don't $break"})
+	assert.Equal(t, `git commit -a --no-verify -m 'don'\''t $break' && echo "don't \$break"`, got)
+}
+
+func TestRender_QuotesMultiSelectItemsByDefault(t *testing.T) {
+	t.Setenv("SHELL", "/bin/bash")
+	wf := store.Workflow{
+		Name:    "rm",
+		Command: "rm {{files}}",
+		Args:    []store.Arg{{Name: "files", Type: "list", ListMulti: true}},
+	}
+	joined := params.JoinListValues([]string{"a.txt", "my file;rm -rf x"}, params.ForWorkflow(wf)[0].ListJoin, Dialect().QuoteListItem)
+	assert.Equal(t, `rm a.txt 'my file;rm -rf x'`, mustRender(t, wf, map[string]string{"files": joined}))
+}
+
+func TestRunSteps_ReportsStatusPerStep(t *testing.T) {
+	steps := []PlannedStep{
+		{Index: 0, Name: "ok", Command: "echo one"},
+		{Index: 1, Name: "soft-fail", Command: "exit 2", ContinueOnError: true},
+		{Index: 2, Name: "skipped", Command: "echo never", Skip: true},
+		{Index: 3, Name: "hard-fail", Command: "exit 5"},
+		{Index: 4, Name: "after", Command: "echo after"},
+	}
+	var out bytes.Buffer
+	results, code := RunSteps(context.Background(), steps, Options{Shell: "sh", Stdout: &out}, StepHooks{})
+
+	assert.Equal(t, 5, code)
+	assert.Equal(t, "one\n", out.String())
+	statuses := make([]StepStatus, len(results))
+	for i, r := range results {
+		statuses[i] = r.Status
+	}
+	assert.Equal(t, []StepStatus{StepSucceeded, StepFailed, StepSkipped, StepFailed, StepPending}, statuses)
+}
+
+func TestRunSteps_DeclinedConfirmationStopsRun(t *testing.T) {
+	steps := []PlannedStep{
+		{Index: 0, Name: "gate", Command: "echo gated", Confirm: true},
+		{Index: 1, Name: "after", Command: "echo after"},
+	}
+	var out bytes.Buffer
+	results, code := RunSteps(context.Background(), steps, Options{Shell: "sh", Stdout: &out}, StepHooks{
+		Confirm: func(PlannedStep) bool { return false },
+	})
+	assert.Equal(t, 1, code)
+	assert.Empty(t, out.String())
+	assert.Equal(t, StepDeclined, results[0].Status)
+	assert.Equal(t, StepPending, results[1].Status)
+}
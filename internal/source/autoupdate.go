@@ -0,0 +1,119 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ErrUpdateInProgress is returned when another process holds the update
+// lock: immediately by a background update, and by other changes once
+// they have waited lockWaitTimeout for it.
+var ErrUpdateInProgress = errors.New("another wf process is updating sources")
+
+const (
+	// lockFileName guards sources.yaml and the clones against concurrent
+	// changes by several wf processes.
+	lockFileName = ".update.lock"
+	// staleLockAge is how old a lock must be before it is assumed to have
+	// been left behind by a process that died, and is taken over.
+	staleLockAge = 10 * time.Minute
+	// lockWaitTimeout bounds how long a change waits for another process,
+	// such as a background update, to release the lock.
+	lockWaitTimeout = 2 * time.Minute
+	// autoUpdateStampName records when a background update last started,
+	// so an unreachable remote is retried once per interval rather than on
+	// every invocation.
+	autoUpdateStampName = ".auto-update"
+)
+
+// lock waits for the update lock and then reloads sources.yaml, so that
+// changes saved by another process while m was loaded are kept. Every
+// method that changes the config or runs git in a clone holds it. The
+// returned function releases the lock.
+func (m *Manager) lock(ctx context.Context) (unlock func(), err error) {
+	ctx, cancel := context.WithTimeout(ctx, lockWaitTimeout)
+	defer cancel()
+	for {
+		unlock, err := m.tryLock()
+		if !errors.Is(err, ErrUpdateInProgress) {
+			return unlock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// tryLock is lock without waiting: it returns ErrUpdateInProgress at once
+// if another process holds the lock.
+func (m *Manager) tryLock() (unlock func(), err error) {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return nil, fmt.Errorf("create sources directory: %w", err)
+	}
+	path := filepath.Join(m.dir, lockFileName)
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			fmt.Fprintln(f, strconv.Itoa(os.Getpid()))
+			f.Close()
+			m.load()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("create update lock: %w", err)
+		}
+		info, statErr := os.Stat(path)
+		if statErr != nil || time.Since(info.ModTime()) < staleLockAge {
+			break
+		}
+		os.Remove(path)
+	}
+	return nil, ErrUpdateInProgress
+}
+
+// Stale returns the git sources last updated longer than maxAge ago. Local
+// path sources are never stale.
+func (m *Manager) Stale(maxAge time.Duration) []Source {
+	var stale []Source
+	for _, s := range m.cfg.Sources {
+		if s.Type() == TypeGit && time.Since(s.UpdatedAt) > maxAge {
+			stale = append(stale, s)
+		}
+	}
+	return stale
+}
+
+// AutoUpdateDue reports whether a background update should be started: a
+// source is older than interval and no background update has started
+// within the interval.
+func (m *Manager) AutoUpdateDue(interval time.Duration) bool {
+	if interval <= 0 || len(m.Stale(interval)) == 0 {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(m.dir, autoUpdateStampName))
+	return err != nil || time.Since(info.ModTime()) > interval
+}
+
+// AutoUpdate updates the sources older than interval while holding the
+// update lock. It does not wait for the lock: it returns
+// ErrUpdateInProgress without updating anything if another process holds
+// it.
+func (m *Manager) AutoUpdate(ctx context.Context, interval time.Duration) ([]SourceUpdate, error) {
+	unlock, err := m.tryLock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := os.WriteFile(filepath.Join(m.dir, autoUpdateStampName), nil, 0644); err != nil {
+		return nil, fmt.Errorf("record auto-update: %w", err)
+	}
+	return m.updateSources(ctx, m.Stale(interval), DefaultUpdateWorkers, nil), nil
+}
@@ -0,0 +1,139 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_TryLock(t *testing.T) {
+	mgr := NewManager(t.TempDir())
+
+	unlock, err := mgr.tryLock()
+	require.NoError(t, err)
+	_, err = NewManager(mgr.dir).tryLock()
+	assert.ErrorIs(t, err, ErrUpdateInProgress)
+
+	unlock()
+	unlock, err = mgr.tryLock()
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestManager_TryLockTakesOverStaleLock(t *testing.T) {
+	mgr := NewManager(t.TempDir())
+	lock := filepath.Join(mgr.dir, lockFileName)
+	require.NoError(t, os.WriteFile(lock, []byte("12345\n"), 0644))
+	old := time.Now().Add(-2 * staleLockAge)
+	require.NoError(t, os.Chtimes(lock, old, old))
+
+	unlock, err := mgr.tryLock()
+	require.NoError(t, err)
+	unlock()
+	assert.NoFileExists(t, lock)
+}
+
+func TestManager_AutoUpdateDue(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(filepath.Join(dir, "sources"))
+	require.NoError(t, mgr.AddPath(dir, "local"))
+	mgr.cfg.Sources = append(mgr.cfg.Sources,
+		Source{Alias: "fresh", URL: "file:///fresh", UpdatedAt: time.Now()},
+		Source{Alias: "old", URL: "file:///old", UpdatedAt: time.Now().Add(-2 * time.Hour)},
+	)
+
+	stale := mgr.Stale(time.Hour)
+	require.Len(t, stale, 1, "path sources and fresh clones are not stale")
+	assert.Equal(t, "old", stale[0].Alias)
+
+	assert.True(t, mgr.AutoUpdateDue(time.Hour))
+	assert.False(t, mgr.AutoUpdateDue(3*time.Hour))
+	assert.False(t, mgr.AutoUpdateDue(0), "zero turns automatic updates off")
+
+	// A recent background attempt holds off the next one, even if it failed.
+	require.NoError(t, os.WriteFile(filepath.Join(mgr.dir, autoUpdateStampName), nil, 0644))
+	assert.False(t, mgr.AutoUpdateDue(time.Hour))
+}
+
+func TestManager_AutoUpdate(t *testing.T) {
+	url := newOriginRepo(t)
+	mgr := NewManager(t.TempDir())
+	ctx := context.Background()
+	require.NoError(t, mgr.Add(ctx, url, "stale", "v1"))
+	require.NoError(t, mgr.Add(ctx, url, "fresh", "v1"))
+
+	// Age one source and move the pin on disk so updating it shows.
+	mgr.cfg.Sources[0].Ref = "main"
+	mgr.cfg.Sources[0].UpdatedAt = time.Now().Add(-2 * time.Hour)
+	require.NoError(t, mgr.save())
+	freshUpdated := mgr.List()[1].UpdatedAt
+
+	// Nothing happens while another process holds the lock.
+	unlock, err := NewManager(mgr.dir).tryLock()
+	require.NoError(t, err)
+	_, err = mgr.AutoUpdate(ctx, time.Hour)
+	assert.ErrorIs(t, err, ErrUpdateInProgress)
+	unlock()
+
+	updates, err := mgr.AutoUpdate(ctx, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "stale", updates[0].Alias)
+	require.NoError(t, updates[0].Err)
+	assert.Equal(t, []string{"backup"}, updates[0].Result.Added)
+
+	reloaded := NewManager(mgr.dir)
+	assert.WithinDuration(t, time.Now(), reloaded.List()[0].UpdatedAt, time.Minute)
+	assert.Equal(t, freshUpdated.Unix(), reloaded.List()[1].UpdatedAt.Unix())
+	assert.False(t, reloaded.AutoUpdateDue(time.Hour))
+	assert.NoFileExists(t, filepath.Join(mgr.dir, lockFileName))
+}
+
+func TestManager_ChangesWaitForLock(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(filepath.Join(dir, "sources"))
+
+	unlock, err := NewManager(mgr.dir).tryLock()
+	require.NoError(t, err)
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		close(released)
+		unlock()
+	}()
+
+	require.NoError(t, mgr.AddPath(dir, "ops"))
+	select {
+	case <-released:
+	default:
+		t.Fatal("AddPath did not wait for the lock")
+	}
+}
+
+func TestManager_ChangesKeepOtherProcessesEdits(t *testing.T) {
+	url := newOriginRepo(t)
+	dir := t.TempDir()
+	first := NewManager(filepath.Join(dir, "sources"))
+	ctx := context.Background()
+	require.NoError(t, first.Add(ctx, url, "team", ""))
+
+	// second was loaded before first's later changes, like a background
+	// update that started earlier.
+	second := NewManager(first.dir)
+	require.NoError(t, first.AddPath(dir, "ops"))
+	require.NoError(t, first.SetWritable("team", true, ""))
+
+	updates := second.UpdateAll(ctx, 1, nil)
+	require.Len(t, updates, 2)
+	require.NoError(t, updates[0].Err)
+
+	sources := NewManager(first.dir).List()
+	require.Len(t, sources, 2)
+	assert.Equal(t, "ops", sources[1].Alias)
+	assert.True(t, sources[0].Writable)
+}
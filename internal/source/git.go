@@ -31,11 +31,23 @@ func gitClone(ctx context.Context, url, dest string) error {
 }
 
 // gitPull runs git pull --ff-only in repoDir with a 30-second timeout.
-// Returns the combined stdout+stderr output for display.
+// A clone that gitPublish left on a detached commit is first returned to
+// the branch it follows. Returns the combined stdout+stderr output for
+// display.
 func gitPull(ctx context.Context, repoDir string) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
+	if err := exec.CommandContext(ctx, "git", "-C", repoDir, "symbolic-ref", "--quiet", "HEAD").Run(); err != nil {
+		branch, err := gitDefaultBranch(ctx, repoDir)
+		if err != nil {
+			return "", err
+		}
+		if out, err := exec.CommandContext(ctx, "git", "-C", repoDir, "checkout", "--quiet", branch).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git checkout %s in %s: %w\n%s", branch, repoDir, err, out)
+		}
+	}
+
 	cmd := exec.CommandContext(ctx, "git", "pull", "--ff-only")
 	cmd.Dir = repoDir
 	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
@@ -46,6 +58,109 @@ func gitPull(ctx context.Context, repoDir string) (string, error) {
 	return string(out), nil
 }
 
+// gitDefaultBranch returns the branch a clone at repoDir follows: the
+// remote's default branch, as recorded by git clone.
+func gitDefaultBranch(ctx context.Context, repoDir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("finding the default branch in %s: %w", repoDir, err)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), "origin/"), nil
+}
+
+// gitCheckoutRef fetches ref (a branch, tag or commit) from origin into
+// the shallow clone at repoDir and checks it out as a detached HEAD.
+func gitCheckoutRef(ctx context.Context, repoDir, ref string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	for _, args := range [][]string{
+		{"fetch", "--depth", "1", "origin", ref},
+		{"checkout", "--quiet", "--detach", "FETCH_HEAD"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s in %s: %w\n%s", args[0], repoDir, err, out)
+		}
+	}
+	return nil
+}
+
+// gitHead returns the abbreviated commit hash checked out in repoDir.
+func gitHead(ctx context.Context, repoDir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse in %s: %w", repoDir, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitPublish commits paths in repoDir with message and pushes the commit
+// to branch, or to the branch the clone follows when branch is empty.
+//
+// The commit is made on a detached HEAD, so the followed branch never gets
+// ahead of origin: pushing to another branch, or a failed push, would
+// otherwise stop later pulls from fast-forwarding once upstream moves. The
+// branch is moved to the commit only after it was pushed to that branch.
+// Until the next update, a failed push can be retried by saving once more,
+// since earlier commits are pushed along with the new one.
+func gitPublish(ctx context.Context, repoDir, branch, message string, paths []string) error {
+	run := func(timeout time.Duration, args ...string) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = repoDir
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		return cmd.CombinedOutput()
+	}
+
+	followed := ""
+	if branch == "" {
+		b, err := gitDefaultBranch(ctx, repoDir)
+		if err != nil {
+			return fmt.Errorf("%s does not follow a branch; set one with 'wf source writable <alias> --push-branch <branch>'", repoDir)
+		}
+		branch, followed = b, b
+	}
+
+	if out, err := run(10*time.Second, "checkout", "--quiet", "--detach"); err != nil {
+		return fmt.Errorf("git checkout in %s: %w\n%s", repoDir, err, out)
+	}
+	add := append([]string{"add", "-A", "--"}, paths...)
+	if out, err := run(10*time.Second, add...); err != nil {
+		return fmt.Errorf("git add in %s: %w\n%s", repoDir, err, out)
+	}
+	// diff --quiet exits 1 when something is staged.
+	if _, err := run(10*time.Second, "diff", "--cached", "--quiet"); err != nil {
+		if out, err := run(10*time.Second, "commit", "--quiet", "-m", message); err != nil {
+			return fmt.Errorf("git commit in %s: %w\n%s", repoDir, err, out)
+		}
+	}
+
+	refspec := "HEAD:refs/heads/" + branch
+	if out, err := run(30*time.Second, "push", "--quiet", "origin", refspec); err != nil {
+		// Name the commit rather than HEAD, which the next update moves.
+		if head, headErr := gitHead(ctx, repoDir); headErr == nil {
+			refspec = head + ":refs/heads/" + branch
+		}
+		return fmt.Errorf("saved and committed, but git push failed: %w\n%s\nPush it by hand with: git -C %s push origin %s",
+			err, strings.TrimSpace(string(out)), repoDir, refspec)
+	}
+
+	if followed != "" {
+		if out, err := run(10*time.Second, "checkout", "--quiet", "-B", followed); err != nil {
+			return fmt.Errorf("git checkout in %s: %w\n%s", repoDir, err, out)
+		}
+	}
+	return nil
+}
+
 // deriveAlias extracts a short alias from a git URL.
 // "https://github.com/team/workflows.git" -> "workflows"
 // "git@github.com:user/my-commands.git"   -> "my-commands"
@@ -2,20 +2,50 @@ package source
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/fredriklanga/wf/internal/store"
 	"github.com/goccy/go-yaml"
 )
 
+// Source types, as reported by Source.Type.
+const (
+	TypeGit  = "git"  // A git repository cloned under the sources directory
+	TypePath = "path" // A local directory or file read in place
+)
+
 // Source represents a configured remote workflow source.
 type Source struct {
-	Alias     string    `yaml:"alias"`
-	URL       string    `yaml:"url"`
-	UpdatedAt time.Time `yaml:"updated_at,omitempty"`
+	Alias      string    `yaml:"alias"`
+	URL        string    `yaml:"url,omitempty"`
+	Path       string    `yaml:"path,omitempty"`        // Absolute path of a local source; empty for git sources
+	Ref        string    `yaml:"ref,omitempty"`         // Branch, tag or commit the clone is pinned to; empty follows the default branch
+	Writable   bool      `yaml:"writable,omitempty"`    // Saving a workflow commits it in the clone and pushes it
+	PushBranch string    `yaml:"push_branch,omitempty"` // Branch writes are pushed to; empty pushes to the followed branch
+	UpdatedAt  time.Time `yaml:"updated_at,omitempty"`
+}
+
+// Type returns TypePath for local sources and TypeGit otherwise.
+func (s Source) Type() string {
+	if s.Path != "" {
+		return TypePath
+	}
+	return TypeGit
+}
+
+// Location returns the path of a local source or the URL of a git source.
+func (s Source) Location() string {
+	if s.Path != "" {
+		return s.Path
+	}
+	return s.URL
 }
 
 // sourceConfig is the on-disk representation stored in sources.yaml.
@@ -23,7 +53,8 @@ type sourceConfig struct {
 	Sources []Source `yaml:"sources"`
 }
 
-// UpdateResult reports what changed after a source update (git pull).
+// UpdateResult reports the workflows that changed after a source update
+// (git pull), by name.
 type UpdateResult struct {
 	Added   []string
 	Removed []string
@@ -45,42 +76,99 @@ func NewManager(dir string) *Manager {
 }
 
 // Add clones a remote repository and registers it as a source.
-// If alias is empty, it is auto-derived from the URL.
-func (m *Manager) Add(ctx context.Context, url, alias string) error {
+// If alias is empty, it is auto-derived from the URL. If ref is set, the
+// clone is pinned to that branch, tag or commit.
+func (m *Manager) Add(ctx context.Context, url, alias, ref string) error {
 	if !gitAvailable() {
 		return fmt.Errorf("git is required for remote sources. Install git and try again")
 	}
 
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	if alias == "" {
 		alias = deriveAlias(url)
 	}
 
-	if m.hasAlias(alias) {
-		return fmt.Errorf("source %q already exists. Use --name to specify a different alias", alias)
+	if err := m.checkAlias(alias); err != nil {
+		return err
 	}
 
 	dest := filepath.Join(m.dir, alias)
 	if err := gitClone(ctx, url, dest); err != nil {
 		return err
 	}
+	if ref != "" {
+		if err := gitCheckoutRef(ctx, dest, ref); err != nil {
+			os.RemoveAll(dest)
+			return fmt.Errorf("checking out %q: %w", ref, err)
+		}
+	}
 
 	m.cfg.Sources = append(m.cfg.Sources, Source{
 		Alias:     alias,
 		URL:       url,
+		Ref:       ref,
+		UpdatedAt: time.Now(),
+	})
+	return m.save()
+}
+
+// AddPath registers a local directory, or a single workflow file, as a
+// source that is read in place: nothing is cloned and git is not needed.
+// If alias is empty, it is derived from the path's last element.
+func (m *Manager) AddPath(path, alias string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve source path: %w", err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return fmt.Errorf("source path: %w", err)
+	}
+
+	if alias == "" {
+		alias = strings.TrimSuffix(filepath.Base(abs), filepath.Ext(abs))
+	}
+
+	unlock, err := m.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := m.checkAlias(alias); err != nil {
+		return err
+	}
+
+	m.cfg.Sources = append(m.cfg.Sources, Source{
+		Alias:     alias,
+		Path:      abs,
 		UpdatedAt: time.Now(),
 	})
 	return m.save()
 }
 
 // Remove deletes a source's clone directory and removes it from config.
+// Local sources are only unregistered; their files are left alone.
 func (m *Manager) Remove(alias string) error {
+	unlock, err := m.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	idx := m.findIndex(alias)
 	if idx < 0 {
 		return fmt.Errorf("source %q not found", alias)
 	}
 
-	if err := os.RemoveAll(filepath.Join(m.dir, alias)); err != nil {
-		return fmt.Errorf("remove clone directory: %w", err)
+	if m.cfg.Sources[idx].Type() == TypeGit {
+		if err := os.RemoveAll(filepath.Join(m.dir, alias)); err != nil {
+			return fmt.Errorf("remove clone directory: %w", err)
+		}
 	}
 
 	m.cfg.Sources = append(m.cfg.Sources[:idx], m.cfg.Sources[idx+1:]...)
@@ -88,31 +176,240 @@ func (m *Manager) Remove(alias string) error {
 }
 
 // Update pulls the latest changes for a source and returns a diff summary.
+// A pinned source is refreshed to its ref instead: a branch moves to its
+// latest commit, while a tag or commit stays where it is. Local sources are
+// always current, so updating them changes nothing.
 func (m *Manager) Update(ctx context.Context, alias string) (*UpdateResult, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	idx := m.findIndex(alias)
+	if idx < 0 {
+		return nil, fmt.Errorf("source %q not found", alias)
+	}
+	if m.cfg.Sources[idx].Type() == TypePath {
+		return &UpdateResult{}, nil
+	}
+	return m.checkout(ctx, idx, m.cfg.Sources[idx].Ref)
+}
+
+// Pin checks out ref (a branch, tag or commit) in a source's clone and
+// records it, so later updates stay on it. It returns what changed.
+func (m *Manager) Pin(ctx context.Context, alias, ref string) (*UpdateResult, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	idx := m.findIndex(alias)
 	if idx < 0 {
 		return nil, fmt.Errorf("source %q not found", alias)
 	}
+	if ref == "" {
+		return nil, fmt.Errorf("ref cannot be empty")
+	}
+	if m.cfg.Sources[idx].Type() == TypePath {
+		return nil, fmt.Errorf("source %q is a local path and cannot be pinned", alias)
+	}
+	result, err := m.checkout(ctx, idx, ref)
+	if err != nil {
+		return nil, err
+	}
+	m.cfg.Sources[idx].Ref = ref
+	return result, m.save()
+}
 
-	cloneDir := filepath.Join(m.dir, alias)
+// SetWritable turns publishing on or off for a git source. When on, saving
+// one of its workflows commits the change in the clone and pushes it to
+// pushBranch, or to the branch the clone follows when pushBranch is empty.
+func (m *Manager) SetWritable(alias string, writable bool, pushBranch string) error {
+	unlock, err := m.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
+	idx := m.findIndex(alias)
+	if idx < 0 {
+		return fmt.Errorf("source %q not found", alias)
+	}
+	if writable && m.cfg.Sources[idx].Type() == TypePath {
+		return fmt.Errorf("source %q is a local path; only git sources can be made writable", alias)
+	}
+	m.cfg.Sources[idx].Writable = writable
+	m.cfg.Sources[idx].PushBranch = ""
+	if writable {
+		m.cfg.Sources[idx].PushBranch = pushBranch
+	}
+	return m.save()
+}
+
+// Publisher returns the function that commits and pushes changes written
+// to a writable source's clone, or nil for read-only sources.
+func (m *Manager) Publisher(alias string) store.PublishFunc {
+	idx := m.findIndex(alias)
+	if idx < 0 || !m.cfg.Sources[idx].Writable {
+		return nil
+	}
+	dir := filepath.Join(m.dir, alias)
+	src := m.cfg.Sources[idx]
+	return func(message string, paths ...string) error {
+		if src.PushBranch == "" && src.Ref != "" {
+			return fmt.Errorf("source %q is pinned to %s; set a branch to push to with 'wf source writable %s --push-branch <branch>'", alias, src.Ref, alias)
+		}
+		unlock, err := m.lock(context.Background())
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		return gitPublish(context.Background(), dir, src.PushBranch, message, paths)
+	}
+}
+
+// Commit returns the abbreviated commit hash a source's clone is at.
+func (m *Manager) Commit(ctx context.Context, alias string) (string, error) {
+	idx := m.findIndex(alias)
+	if idx < 0 {
+		return "", fmt.Errorf("source %q not found", alias)
+	}
+	if m.cfg.Sources[idx].Type() == TypePath {
+		return "", fmt.Errorf("source %q is a local path, not a git clone", alias)
+	}
+	return gitHead(ctx, filepath.Join(m.dir, alias))
+}
+
+// checkout brings the clone of source idx up to date, pulling when ref is
+// empty and checking out ref otherwise, and records the update time.
+func (m *Manager) checkout(ctx context.Context, idx int, ref string) (*UpdateResult, error) {
+	result, err := refreshClone(ctx, filepath.Join(m.dir, m.cfg.Sources[idx].Alias), ref)
+	if err != nil {
+		return nil, err
+	}
+
+	m.cfg.Sources[idx].UpdatedAt = time.Now()
+	if err := m.save(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// refreshClone pulls cloneDir, or checks out ref when it is set, and
+// reports which workflows changed. It does not touch the config, so
+// several clones can be refreshed at once.
+func refreshClone(ctx context.Context, cloneDir, ref string) (*UpdateResult, error) {
 	// Snapshot before pull
 	before, _ := listYAMLFiles(cloneDir)
 
-	if _, err := gitPull(ctx, cloneDir); err != nil {
+	if ref == "" {
+		if _, err := gitPull(ctx, cloneDir); err != nil {
+			return nil, err
+		}
+	} else if err := gitCheckoutRef(ctx, cloneDir, ref); err != nil {
 		return nil, err
 	}
 
 	// Snapshot after pull
 	after, _ := listYAMLFiles(cloneDir)
 
-	result := diffSnapshots(before, after)
+	return diffSnapshots(before, after), nil
+}
 
-	m.cfg.Sources[idx].UpdatedAt = time.Now()
-	if err := m.save(); err != nil {
-		return result, err
+// SourceUpdate is the outcome of updating one source with UpdateAll.
+type SourceUpdate struct {
+	Alias  string
+	Result *UpdateResult // nil when Err is set
+	Err    error
+}
+
+// DefaultUpdateWorkers is how many sources UpdateAll updates at once when
+// not told otherwise.
+const DefaultUpdateWorkers = 4
+
+// UpdateAll updates every source concurrently, at most workers at a time,
+// and returns the outcomes in configuration order. One failing source does
+// not stop the others. If progress is non-nil it is called, one call at a
+// time, when each source starts (done false) and finishes (done true).
+func (m *Manager) UpdateAll(ctx context.Context, workers int, progress func(u SourceUpdate, done bool)) []SourceUpdate {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		updates := make([]SourceUpdate, len(m.cfg.Sources))
+		for i, s := range m.cfg.Sources {
+			updates[i] = SourceUpdate{Alias: s.Alias, Err: err}
+		}
+		return updates
 	}
-	return result, nil
+	defer unlock()
+	return m.updateSources(ctx, m.List(), workers, progress)
+}
+
+// updateSources updates the given sources as described for UpdateAll. The
+// caller holds the update lock.
+func (m *Manager) updateSources(ctx context.Context, sources []Source, workers int, progress func(u SourceUpdate, done bool)) []SourceUpdate {
+	if workers < 1 {
+		workers = DefaultUpdateWorkers
+	}
+	updates := make([]SourceUpdate, len(sources))
+
+	var mu sync.Mutex
+	report := func(u SourceUpdate, done bool) {
+		if progress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		progress(u, done)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(sources); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				s := sources[i]
+				u := SourceUpdate{Alias: s.Alias}
+				report(u, false)
+				if s.Type() == TypePath {
+					u.Result = &UpdateResult{}
+				} else {
+					u.Result, u.Err = refreshClone(ctx, filepath.Join(m.dir, s.Alias), s.Ref)
+				}
+				updates[i] = u
+				report(u, true)
+			}
+		}()
+	}
+	for i := range sources {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	now := time.Now()
+	changed := false
+	for i, u := range updates {
+		if u.Err == nil && sources[i].Type() == TypeGit {
+			if idx := m.findIndex(u.Alias); idx >= 0 {
+				m.cfg.Sources[idx].UpdatedAt = now
+				changed = true
+			}
+		}
+	}
+	if changed {
+		if err := m.save(); err != nil {
+			for i := range updates {
+				if updates[i].Err == nil {
+					updates[i].Err = err
+				}
+			}
+		}
+	}
+	return updates
 }
 
 // List returns a copy of all configured sources.
@@ -122,10 +419,15 @@ func (m *Manager) List() []Source {
 	return out
 }
 
-// SourceDirs returns a map of alias -> clone directory path.
+// SourceDirs returns a map of alias -> directory to read workflows from:
+// the clone for git sources, and the path itself for local sources.
 func (m *Manager) SourceDirs() map[string]string {
 	dirs := make(map[string]string, len(m.cfg.Sources))
 	for _, s := range m.cfg.Sources {
+		if s.Type() == TypePath {
+			dirs[s.Alias] = s.Path
+			continue
+		}
 		dirs[s.Alias] = filepath.Join(m.dir, s.Alias)
 	}
 	return dirs
@@ -160,6 +462,17 @@ func (m *Manager) save() error {
 	return os.WriteFile(m.configPath(), data, 0644)
 }
 
+// checkAlias returns an error if alias is taken or reserved.
+func (m *Manager) checkAlias(alias string) error {
+	if alias == store.ProjectAlias {
+		return fmt.Errorf("alias %q is reserved for project workflows. Use --name to specify a different alias", alias)
+	}
+	if m.hasAlias(alias) {
+		return fmt.Errorf("source %q already exists. Use --name to specify a different alias", alias)
+	}
+	return nil
+}
+
 // hasAlias returns true if a source with the given alias already exists.
 func (m *Manager) hasAlias(alias string) bool {
 	return m.findIndex(alias) >= 0
@@ -175,13 +488,16 @@ func (m *Manager) findIndex(alias string) int {
 	return -1
 }
 
-// yamlFileInfo holds a filename and its modification time for diff comparison.
+// yamlFileInfo identifies a workflow file in a snapshot of a clone.
 type yamlFileInfo struct {
-	name    string
-	modTime time.Time
+	name string   // workflow name, or the file path when it has none
+	hash [32]byte // SHA-256 of the file content
 }
 
-// listYAMLFiles returns all .yaml files under dir (excluding .git/) with mod times.
+// listYAMLFiles returns all .yaml files under dir (excluding .git/), keyed
+// by path, with the workflow each defines and a hash of its content.
+// Hashes are compared rather than modification times, which git does not
+// preserve.
 func listYAMLFiles(dir string) (map[string]yamlFileInfo, error) {
 	files := make(map[string]yamlFileInfo)
 	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
@@ -195,31 +511,39 @@ func listYAMLFiles(dir string) (map[string]yamlFileInfo, error) {
 			return nil
 		}
 		rel, _ := filepath.Rel(dir, path)
-		info, infoErr := d.Info()
-		if infoErr != nil {
+		data, readErr := os.ReadFile(path)
+		if readErr != nil {
 			return nil
 		}
-		files[rel] = yamlFileInfo{name: rel, modTime: info.ModTime()}
+		info := yamlFileInfo{name: rel, hash: sha256.Sum256(data)}
+		if w, parseErr := store.ParseWorkflow(data); parseErr == nil && w.Name != "" {
+			info.name = w.Name
+		}
+		files[rel] = info
 		return nil
 	})
 	return files, err
 }
 
-// diffSnapshots computes added, removed, and updated files between two snapshots.
+// diffSnapshots computes the workflows added, removed, and updated between
+// two snapshots, sorted by name.
 func diffSnapshots(before, after map[string]yamlFileInfo) *UpdateResult {
 	result := &UpdateResult{}
-	for name := range after {
-		b, existed := before[name]
+	for path, a := range after {
+		b, existed := before[path]
 		if !existed {
-			result.Added = append(result.Added, name)
-		} else if after[name].modTime.After(b.modTime) {
-			result.Updated = append(result.Updated, name)
+			result.Added = append(result.Added, a.name)
+		} else if a.hash != b.hash {
+			result.Updated = append(result.Updated, a.name)
 		}
 	}
-	for name := range before {
-		if _, exists := after[name]; !exists {
-			result.Removed = append(result.Removed, name)
+	for path, b := range before {
+		if _, exists := after[path]; !exists {
+			result.Removed = append(result.Removed, b.name)
 		}
 	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Updated)
 	return result
 }
@@ -0,0 +1,418 @@
+package source
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fredriklanga/wf/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOriginRepo creates a git repository with a v1 tag and a later commit
+// on main, and returns its file:// URL.
+func newOriginRepo(t *testing.T) string {
+	t.Helper()
+	if !gitAvailable() {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	git("init", "-q", "-b", "main")
+	write("deploy.yaml", "name: deploy\ncommand: echo v1\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1")
+	write("deploy.yaml", "name: deploy\ncommand: echo v2\n")
+	write("backup.yaml", "name: backup\ncommand: echo backup\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "v2")
+	return "file://" + dir
+}
+
+func TestManager_AddWithRef(t *testing.T) {
+	url := newOriginRepo(t)
+	mgr := NewManager(t.TempDir())
+	ctx := context.Background()
+
+	require.NoError(t, mgr.Add(ctx, url, "team", "v1"))
+
+	sources := mgr.List()
+	require.Len(t, sources, 1)
+	assert.Equal(t, "v1", sources[0].Ref)
+	assert.NoFileExists(t, filepath.Join(mgr.SourceDirs()["team"], "backup.yaml"))
+
+	// The ref survives a reload of sources.yaml.
+	reloaded := NewManager(mgr.dir)
+	assert.Equal(t, "v1", reloaded.List()[0].Ref)
+}
+
+func TestManager_AddWithUnknownRefCleansUp(t *testing.T) {
+	url := newOriginRepo(t)
+	mgr := NewManager(t.TempDir())
+
+	err := mgr.Add(context.Background(), url, "team", "no-such-ref")
+	require.Error(t, err)
+	assert.Empty(t, mgr.List())
+	assert.NoDirExists(t, filepath.Join(mgr.dir, "team"))
+}
+
+func TestManager_PinAndUpdate(t *testing.T) {
+	url := newOriginRepo(t)
+	mgr := NewManager(t.TempDir())
+	ctx := context.Background()
+	require.NoError(t, mgr.Add(ctx, url, "team", "v1"))
+	pinned, err := mgr.Commit(ctx, "team")
+	require.NoError(t, err)
+
+	// Updating a tag pin does not move it.
+	result, err := mgr.Update(ctx, "team")
+	require.NoError(t, err)
+	assert.Empty(t, result.Added)
+	commit, err := mgr.Commit(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, pinned, commit)
+
+	// Pinning to a branch moves to its head and reports the changes.
+	result, err = mgr.Pin(ctx, "team", "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup"}, result.Added)
+	assert.Equal(t, []string{"deploy"}, result.Updated)
+	assert.Equal(t, "main", mgr.List()[0].Ref)
+	assert.FileExists(t, filepath.Join(mgr.SourceDirs()["team"], "backup.yaml"))
+
+	_, err = mgr.Pin(ctx, "missing", "main")
+	assert.Error(t, err)
+}
+
+func TestManager_AddPath(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "ops", "workflows")
+	require.NoError(t, os.MkdirAll(shared, 0755))
+	mgr := NewManager(filepath.Join(dir, "sources"))
+	ctx := context.Background()
+
+	require.NoError(t, mgr.AddPath(shared, ""))
+
+	sources := mgr.List()
+	require.Len(t, sources, 1)
+	assert.Equal(t, "workflows", sources[0].Alias)
+	assert.Equal(t, TypePath, sources[0].Type())
+	assert.Equal(t, shared, sources[0].Location())
+	assert.Equal(t, shared, mgr.SourceDirs()["workflows"])
+
+	result, err := mgr.Update(ctx, "workflows")
+	require.NoError(t, err)
+	assert.Equal(t, &UpdateResult{}, result)
+	_, err = mgr.Pin(ctx, "workflows", "main")
+	assert.Error(t, err)
+
+	// Removing a path source leaves its files alone.
+	require.NoError(t, mgr.Remove("workflows"))
+	assert.Empty(t, mgr.List())
+	assert.DirExists(t, shared)
+}
+
+func TestManager_AddPathErrors(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(filepath.Join(dir, "sources"))
+
+	assert.Error(t, mgr.AddPath(filepath.Join(dir, "missing"), ""))
+
+	file := filepath.Join(dir, "team.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: a\ncommand: echo a\n"), 0644))
+	require.NoError(t, mgr.AddPath(file, ""))
+	assert.Equal(t, "team", mgr.List()[0].Alias)
+	assert.Error(t, mgr.AddPath(file, "team"))
+}
+
+func TestManager_ProjectAliasReserved(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(filepath.Join(dir, "sources"))
+
+	err := mgr.AddPath(dir, "project")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reserved")
+}
+
+// newBareRepo returns a bare repository cloned from newOriginRepo, which a
+// source can push to.
+func newBareRepo(t *testing.T) string {
+	t.Helper()
+	origin := newOriginRepo(t)
+	bare := filepath.Join(t.TempDir(), "team.git")
+	out, err := exec.Command("git", "clone", "-q", "--bare", origin, bare).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return bare
+}
+
+// gitShow returns the content of path at rev in the repository gitDir.
+func gitShow(t *testing.T, gitDir, rev, path string) (string, error) {
+	t.Helper()
+	out, err := exec.Command("git", "--git-dir", gitDir, "show", rev+":"+path).Output()
+	return string(out), err
+}
+
+func setGitIdentity(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+}
+
+func TestManager_WritableSourcePublishes(t *testing.T) {
+	bare := newBareRepo(t)
+	setGitIdentity(t)
+	mgr := NewManager(t.TempDir())
+	require.NoError(t, mgr.Add(context.Background(), "file://"+bare, "team", ""))
+	assert.Nil(t, mgr.Publisher("team"), "sources are read-only by default")
+
+	require.NoError(t, mgr.SetWritable("team", true, ""))
+	assert.True(t, NewManager(mgr.dir).List()[0].Writable)
+	rs := store.NewWritableRemoteStore(mgr.SourceDirs()["team"], mgr.Publisher("team"))
+
+	// Updating an existing workflow rewrites the file it came from.
+	wf, err := rs.Get("deploy")
+	require.NoError(t, err)
+	wf.Command = "echo v3"
+	require.NoError(t, rs.Save(wf))
+	content, err := gitShow(t, bare, "main", "deploy.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, content, "command: echo v3")
+
+	// New workflows are added and deletions are pushed too.
+	require.NoError(t, rs.Save(&store.Workflow{Name: "ops/restart", Command: "systemctl restart app"}))
+	_, err = gitShow(t, bare, "main", "ops/restart.yaml")
+	require.NoError(t, err)
+
+	require.NoError(t, rs.Delete("backup"))
+	_, err = gitShow(t, bare, "main", "backup.yaml")
+	assert.Error(t, err)
+
+	out, err := exec.Command("git", "--git-dir", bare, "log", "-3", "--format=%s", "main").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Remove workflow backup\nAdd workflow ops/restart\nUpdate workflow deploy\n", string(out))
+}
+
+func TestManager_WritableSourcePushBranch(t *testing.T) {
+	bare := newBareRepo(t)
+	setGitIdentity(t)
+	mgr := NewManager(t.TempDir())
+	require.NoError(t, mgr.Add(context.Background(), "file://"+bare, "team", "v1"))
+	require.NoError(t, mgr.SetWritable("team", true, "wf/updates"))
+	rs := store.NewWritableRemoteStore(mgr.SourceDirs()["team"], mgr.Publisher("team"))
+
+	require.NoError(t, rs.Save(&store.Workflow{Name: "lint", Command: "make lint"}))
+
+	_, err := gitShow(t, bare, "wf/updates", "lint.yaml")
+	require.NoError(t, err)
+	_, err = gitShow(t, bare, "main", "lint.yaml")
+	assert.Error(t, err, "main is untouched")
+}
+
+func TestManager_WritableSourcePushFailure(t *testing.T) {
+	bare := newBareRepo(t)
+	setGitIdentity(t)
+	mgr := NewManager(t.TempDir())
+	require.NoError(t, mgr.Add(context.Background(), "file://"+bare, "team", ""))
+	require.NoError(t, mgr.SetWritable("team", true, ""))
+	rs := store.NewWritableRemoteStore(mgr.SourceDirs()["team"], mgr.Publisher("team"))
+
+	require.NoError(t, os.RemoveAll(bare))
+	err := rs.Save(&store.Workflow{Name: "lint", Command: "make lint"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Push it by hand with: git -C")
+	assert.Regexp(t, `push origin [0-9a-f]+:refs/heads/main`, err.Error())
+
+	// The change is committed locally.
+	out, err := exec.Command("git", "-C", mgr.SourceDirs()["team"], "log", "-1", "--format=%s").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Add workflow lint\n", string(out))
+}
+
+// commitUpstream pushes a commit adding name to main in the bare repository.
+func commitUpstream(t *testing.T, bare, name string) {
+	t.Helper()
+	work := filepath.Join(t.TempDir(), "work")
+	for _, args := range [][]string{
+		{"clone", "-q", bare, work},
+		{"-C", work, "checkout", "-q", "main"},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(work, name+".yaml"), []byte("name: "+name+"\ncommand: echo "+name+"\n"), 0644))
+	for _, args := range [][]string{
+		{"-C", work, "add", "-A"},
+		{"-C", work, "commit", "-q", "-m", "Add " + name},
+		{"-C", work, "push", "-q", "origin", "main"},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func TestManager_UpdateAfterPublishToPushBranch(t *testing.T) {
+	bare := newBareRepo(t)
+	setGitIdentity(t)
+	mgr := NewManager(t.TempDir())
+	ctx := context.Background()
+	require.NoError(t, mgr.Add(ctx, "file://"+bare, "team", ""))
+	require.NoError(t, mgr.SetWritable("team", true, "wf/updates"))
+	rs := store.NewWritableRemoteStore(mgr.SourceDirs()["team"], mgr.Publisher("team"))
+	require.NoError(t, rs.Save(&store.Workflow{Name: "lint", Command: "make lint"}))
+	require.NoError(t, rs.Save(&store.Workflow{Name: "fmt", Command: "make fmt"}))
+	_, err := gitShow(t, bare, "wf/updates", "lint.yaml")
+	require.NoError(t, err, "later publishes build on earlier ones")
+
+	commitUpstream(t, bare, "upstream")
+
+	// The source still follows main: it picks up the upstream commit, and
+	// the workflow pushed to wf/updates is gone until that branch merges.
+	result, err := mgr.Update(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"upstream"}, result.Added)
+	assert.Equal(t, []string{"fmt", "lint"}, result.Removed)
+}
+
+func TestManager_UpdateAfterFailedPush(t *testing.T) {
+	bare := newBareRepo(t)
+	setGitIdentity(t)
+	mgr := NewManager(t.TempDir())
+	ctx := context.Background()
+	require.NoError(t, mgr.Add(ctx, "file://"+bare, "team", ""))
+	require.NoError(t, mgr.SetWritable("team", true, ""))
+	rs := store.NewWritableRemoteStore(mgr.SourceDirs()["team"], mgr.Publisher("team"))
+
+	// Reject pushes to main so the commit stays local.
+	hook := filepath.Join(bare, "hooks", "pre-receive")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755))
+	require.Error(t, rs.Save(&store.Workflow{Name: "lint", Command: "make lint"}))
+	require.NoError(t, os.Remove(hook))
+
+	commitUpstream(t, bare, "upstream")
+
+	result, err := mgr.Update(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"upstream"}, result.Added)
+
+	// Publishing to the followed branch keeps the clone on it.
+	require.NoError(t, rs.Save(&store.Workflow{Name: "fmt", Command: "make fmt"}))
+	_, err = gitShow(t, bare, "main", "fmt.yaml")
+	require.NoError(t, err)
+	commitUpstream(t, bare, "later")
+	result, err = mgr.Update(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"later"}, result.Added)
+}
+
+func TestManager_SetWritableRejectsPathSources(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(filepath.Join(dir, "sources"))
+	require.NoError(t, mgr.AddPath(dir, "ops"))
+
+	assert.Error(t, mgr.SetWritable("ops", true, ""))
+	assert.Error(t, mgr.SetWritable("missing", true, ""))
+}
+
+func TestDiffSnapshots_ComparesContent(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("deploy.yaml", "name: deploy\ncommand: echo v1\n")
+	write("backup.yaml", "name: backup\ncommand: echo backup\n")
+	before, err := listYAMLFiles(dir)
+	require.NoError(t, err)
+
+	// Rewriting a file with the same content is not an update, even
+	// though its modification time changes.
+	write("backup.yaml", "name: backup\ncommand: echo backup\n")
+	write("deploy.yaml", "name: deploy\ncommand: echo v2\n")
+	write("notes.yaml", "not: a workflow\n")
+	after, err := listYAMLFiles(dir)
+	require.NoError(t, err)
+
+	result := diffSnapshots(before, after)
+	assert.Equal(t, []string{"notes.yaml"}, result.Added)
+	assert.Equal(t, []string{"deploy"}, result.Updated)
+	assert.Empty(t, result.Removed)
+
+	result = diffSnapshots(after, before)
+	assert.Equal(t, []string{"notes.yaml"}, result.Removed)
+}
+
+func TestManager_UpdateAll(t *testing.T) {
+	url := newOriginRepo(t)
+	dir := t.TempDir()
+	mgr := NewManager(filepath.Join(dir, "sources"))
+	ctx := context.Background()
+	require.NoError(t, mgr.Add(ctx, url, "pinned", "v1"))
+	require.NoError(t, mgr.Add(ctx, url, "broken", ""))
+	require.NoError(t, mgr.AddPath(dir, "local"))
+	require.NoError(t, mgr.Add(ctx, url, "team", ""))
+
+	// Commit to the origin so the update has something to report, and
+	// break one clone.
+	setGitIdentity(t)
+	origin := strings.TrimPrefix(url, "file://")
+	require.NoError(t, os.WriteFile(filepath.Join(origin, "deploy.yaml"), []byte("name: deploy\ncommand: echo v3\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(origin, "lint.yaml"), []byte("name: lint\ncommand: make lint\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(origin, "backup.yaml")))
+	out, err := exec.Command("git", "-C", origin, "commit", "-q", "-am", "v3").CombinedOutput()
+	require.NoError(t, err, string(out))
+	out, err = exec.Command("git", "-C", origin, "add", "lint.yaml").CombinedOutput()
+	require.NoError(t, err, string(out))
+	out, err = exec.Command("git", "-C", origin, "commit", "-q", "-m", "lint").CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.NoError(t, os.RemoveAll(filepath.Join(mgr.SourceDirs()["broken"], ".git")))
+
+	var started, finished []string
+	updates := mgr.UpdateAll(ctx, 2, func(u SourceUpdate, done bool) {
+		if done {
+			finished = append(finished, u.Alias)
+		} else {
+			started = append(started, u.Alias)
+		}
+	})
+
+	require.Len(t, updates, 4)
+	assert.ElementsMatch(t, []string{"pinned", "broken", "local", "team"}, started)
+	assert.ElementsMatch(t, started, finished)
+
+	assert.Equal(t, "pinned", updates[0].Alias)
+	require.NoError(t, updates[0].Err)
+	assert.Equal(t, &UpdateResult{}, updates[0].Result)
+
+	assert.Equal(t, "broken", updates[1].Alias)
+	assert.Error(t, updates[1].Err, "one failing source does not stop the others")
+
+	assert.Equal(t, "local", updates[2].Alias)
+	require.NoError(t, updates[2].Err)
+
+	assert.Equal(t, "team", updates[3].Alias)
+	require.NoError(t, updates[3].Err)
+	assert.Equal(t, []string{"lint"}, updates[3].Result.Added)
+	assert.Equal(t, []string{"backup"}, updates[3].Result.Removed)
+	assert.Equal(t, []string{"deploy"}, updates[3].Result.Updated)
+	assert.False(t, NewManager(mgr.dir).List()[3].UpdatedAt.IsZero())
+}
@@ -9,7 +9,9 @@ import (
 
 // MultiStore aggregates a local Store with zero or more remote Stores.
 // Local workflows are returned without prefix. Remote workflows are
-// namespaced with their source alias (e.g., "team/deploy-k8s").
+// namespaced with their source alias (e.g., "team/deploy-k8s"). The
+// current project's .wf/ directory is merged in the same way under
+// ProjectAlias.
 type MultiStore struct {
 	local  Store
 	remote map[string]Store // key = source alias
@@ -76,13 +78,19 @@ func (ms *MultiStore) Get(name string) (*Workflow, error) {
 }
 
 // Save persists a workflow. If the name starts with a known remote alias,
-// the operation is rejected (remote sources are read-only). Otherwise
-// it delegates to the local store.
+// the workflow is saved to that store with the alias stripped, or the
+// operation is rejected when the store is read-only. Otherwise it
+// delegates to the local store.
 func (ms *MultiStore) Save(w *Workflow) error {
 	if idx := strings.Index(w.Name, "/"); idx >= 0 {
 		alias := w.Name[:idx]
-		if _, ok := ms.remote[alias]; ok {
-			return fmt.Errorf("cannot save to remote source %q (read-only)", alias)
+		if s, ok := ms.remote[alias]; ok {
+			if readOnly(s) {
+				return fmt.Errorf("cannot save to remote source %q (read-only)", alias)
+			}
+			out := *w
+			out.Name = w.Name[idx+1:]
+			return s.Save(&out)
 		}
 	}
 
@@ -90,18 +98,30 @@ func (ms *MultiStore) Save(w *Workflow) error {
 }
 
 // Delete removes a workflow by name. If the name starts with a known remote
-// alias, the operation is rejected. Otherwise it delegates to the local store.
+// alias, the request is delegated to that store with the alias stripped, or
+// rejected when the store is read-only. Otherwise it delegates to the local
+// store.
 func (ms *MultiStore) Delete(name string) error {
 	if idx := strings.Index(name, "/"); idx >= 0 {
 		alias := name[:idx]
-		if _, ok := ms.remote[alias]; ok {
-			return fmt.Errorf("cannot delete from remote source %q (read-only)", alias)
+		if s, ok := ms.remote[alias]; ok {
+			if readOnly(s) {
+				return fmt.Errorf("cannot delete from remote source %q (read-only)", alias)
+			}
+			return s.Delete(name[idx+1:])
 		}
 	}
 
 	return ms.local.Delete(name)
 }
 
+// readOnly reports whether s rejects writes. Stores that do not implement
+// ReadOnlyStore are assumed to.
+func readOnly(s Store) bool {
+	ro, ok := s.(ReadOnlyStore)
+	return !ok || ro.ReadOnly()
+}
+
 // HasRemote returns true if any remote stores are configured.
 // Useful for UI to decide whether source labels should be shown.
 func (ms *MultiStore) HasRemote() bool {
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMultiStore(t *testing.T) (*MultiStore, *YAMLStore, *YAMLStore) {
+	t.Helper()
+	local := NewYAMLStore(t.TempDir())
+	project := NewYAMLStore(t.TempDir())
+
+	remoteDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(remoteDir, "shared.yaml"), []byte("name: shared\ncommand: echo shared\n"), 0644))
+
+	ms := NewMultiStore(local, map[string]Store{
+		ProjectAlias: project,
+		"team":       NewRemoteStore(remoteDir),
+	})
+	return ms, local, project
+}
+
+func TestMultiStore_SavesToWritablePrefixedStore(t *testing.T) {
+	ms, local, project := newTestMultiStore(t)
+
+	w := &Workflow{Name: "project/deploy", Command: "make deploy"}
+	require.NoError(t, ms.Save(w))
+	assert.Equal(t, "project/deploy", w.Name, "caller's workflow is not renamed")
+
+	got, err := project.Get("deploy")
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", got.Name)
+	_, err = local.Get("project/deploy")
+	assert.Error(t, err)
+
+	got, err = ms.Get("project/deploy")
+	require.NoError(t, err)
+	assert.Equal(t, "make deploy", got.Command)
+
+	all, err := ms.List()
+	require.NoError(t, err)
+	var names []string
+	for _, wf := range all {
+		names = append(names, wf.Name)
+	}
+	assert.Equal(t, []string{"project/deploy", "team/shared"}, names)
+
+	require.NoError(t, ms.Delete("project/deploy"))
+	_, err = project.Get("deploy")
+	assert.Error(t, err)
+}
+
+func TestMultiStore_RejectsWritesToReadOnlyStore(t *testing.T) {
+	ms, _, _ := newTestMultiStore(t)
+
+	err := ms.Save(&Workflow{Name: "team/shared", Command: "echo changed"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read-only")
+
+	err = ms.Delete("team/shared")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read-only")
+}
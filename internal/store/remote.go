@@ -9,25 +9,51 @@ import (
 	"github.com/goccy/go-yaml"
 )
 
-// RemoteStore implements Store as a read-only view over a cloned git repository.
+// RemoteStore implements Store as a view over a cloned git repository
+// or a local source directory.
 // It walks the entire directory tree (skipping .git) and returns all valid
-// workflow YAML files found. Save and Delete operations return errors since
-// remote sources are read-only.
+// workflow YAML files found. By default Save and Delete return errors since
+// remote sources are read-only; a store created with NewWritableRemoteStore
+// writes into the directory and publishes each change.
 type RemoteStore struct {
-	dir string // path to cloned repo root
+	dir     string      // path to cloned repo root
+	publish PublishFunc // nil for read-only sources
 }
 
-// NewRemoteStore creates a new RemoteStore rooted at the given directory.
+// PublishFunc shares a change after it has been written to a source's
+// directory, for example by committing and pushing it. paths are relative
+// to the directory; message describes the change.
+type PublishFunc func(message string, paths ...string) error
+
+// NewRemoteStore creates a new read-only RemoteStore rooted at the given directory.
 func NewRemoteStore(dir string) *RemoteStore {
 	return &RemoteStore{dir: dir}
 }
 
+// NewWritableRemoteStore creates a RemoteStore whose Save and Delete write
+// into dir and then call publish with the changed file.
+func NewWritableRemoteStore(dir string, publish PublishFunc) *RemoteStore {
+	return &RemoteStore{dir: dir, publish: publish}
+}
+
 // List walks the cloned repo directory and returns all valid workflows.
 // It skips .git directories and silently ignores malformed YAML files
-// or files that don't contain valid workflow definitions (missing Name or Command).
+// or files that don't contain valid workflow definitions (missing Name or a command).
 func (rs *RemoteStore) List() ([]Workflow, error) {
 	var workflows []Workflow
+	err := rs.walk(func(path string, w *Workflow) bool {
+		workflows = append(workflows, *w)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workflows, nil
+}
 
+// walk calls fn with each valid workflow and the file it came from, until
+// fn returns false.
+func (rs *RemoteStore) walk(fn func(path string, w *Workflow) bool) error {
 	err := filepath.WalkDir(rs.dir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -55,51 +81,146 @@ func (rs *RemoteStore) List() ([]Workflow, error) {
 			return nil // skip unreadable files
 		}
 
-		var w Workflow
-		if unmarshalErr := yaml.Unmarshal(data, &w); unmarshalErr != nil {
-			return nil // skip malformed YAML
+		w, parseErr := ParseWorkflow(data)
+		if parseErr != nil {
+			return nil // skip malformed YAML and newer schema versions
 		}
 
 		// Skip files that don't have required workflow fields
-		if w.Name == "" || w.Command == "" {
+		if w.Name == "" || w.Template() == "" {
 			return nil
 		}
 
-		workflows = append(workflows, w)
+		if !fn(path, w) {
+			return filepath.SkipAll
+		}
 		return nil
 	})
+	return err
+}
 
-	if err != nil {
-		return nil, err
-	}
-
-	return workflows, nil
+// find returns the file holding workflow name, or "" if there is none.
+func (rs *RemoteStore) find(name string) (string, *Workflow, error) {
+	var (
+		found string
+		wf    *Workflow
+	)
+	err := rs.walk(func(path string, w *Workflow) bool {
+		if w.Name == name {
+			found, wf = path, w
+			return false
+		}
+		return true
+	})
+	return found, wf, err
 }
 
 // Get retrieves a workflow by name from the cloned repo.
 // It iterates over all workflows since remote repos don't follow slug-based
 // path conventions.
 func (rs *RemoteStore) Get(name string) (*Workflow, error) {
-	workflows, err := rs.List()
+	_, w, err := rs.find(name)
 	if err != nil {
 		return nil, err
 	}
+	if w == nil {
+		return nil, fmt.Errorf("workflow %q %w in remote source", name, ErrNotFound)
+	}
+	return w, nil
+}
 
-	for i := range workflows {
-		if workflows[i].Name == name {
-			return &workflows[i], nil
-		}
+// WorkflowPath returns the file holding workflow name, or the path a new
+// workflow of that name would be saved to, laid out as in YAMLStore.
+func (rs *RemoteStore) WorkflowPath(name string) string {
+	if path, _, err := rs.find(name); err == nil && path != "" {
+		return path
 	}
+	return NewYAMLStore(rs.dir).WorkflowPath(name)
+}
 
-	return nil, fmt.Errorf("workflow %q not found in remote source", name)
+// ReadOnly reports whether the store rejects writes, which it does unless
+// it was created with NewWritableRemoteStore.
+func (rs *RemoteStore) ReadOnly() bool {
+	return rs.publish == nil
 }
 
-// Save returns an error because remote sources are read-only.
+// Save writes a workflow into the source, replacing the file it was read
+// from if it already exists, and publishes the change. Read-only stores
+// return an error.
 func (rs *RemoteStore) Save(w *Workflow) error {
-	return fmt.Errorf("cannot save to remote source (read-only)")
+	if rs.ReadOnly() {
+		return fmt.Errorf("cannot save to remote source (read-only)")
+	}
+
+	fpath, existing, err := rs.find(w.Name)
+	if err != nil {
+		return err
+	}
+	message := "Update workflow " + w.Name
+	if existing == nil {
+		fpath = NewYAMLStore(rs.dir).WorkflowPath(w.Name)
+		message = "Add workflow " + w.Name
+	}
+
+	out := *w
+	out.Version = SchemaVersion
+	data, err := yaml.Marshal(&out)
+	if err != nil {
+		return fmt.Errorf("marshalling workflow: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return fmt.Errorf("creating workflow directory: %w", err)
+	}
+	if err := os.WriteFile(fpath, data, 0644); err != nil {
+		return fmt.Errorf("writing workflow file: %w", err)
+	}
+	return rs.publishFile(message, fpath)
+}
+
+// PublishPath shares changes made directly to the workflow file at path,
+// such as an edit in $EDITOR. The file is named by path rather than by
+// workflow, since the edit may have renamed the workflow. Read-only stores
+// return an error.
+func (rs *RemoteStore) PublishPath(path string) error {
+	if rs.ReadOnly() {
+		return fmt.Errorf("cannot publish to remote source (read-only)")
+	}
+	label := filepath.Base(path)
+	if data, err := os.ReadFile(path); err == nil {
+		if w, err := ParseWorkflow(data); err == nil && w.Name != "" {
+			label = w.Name
+		}
+	}
+	return rs.publishFile("Update workflow "+label, path)
 }
 
-// Delete returns an error because remote sources are read-only.
+// Delete removes a workflow's file from the source and publishes the
+// change. Read-only stores return an error.
 func (rs *RemoteStore) Delete(name string) error {
-	return fmt.Errorf("cannot delete from remote source (read-only)")
+	if rs.ReadOnly() {
+		return fmt.Errorf("cannot delete from remote source (read-only)")
+	}
+
+	fpath, existing, err := rs.find(name)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("workflow %q %w in remote source", name, ErrNotFound)
+	}
+	if err := os.Remove(fpath); err != nil {
+		return fmt.Errorf("deleting workflow file: %w", err)
+	}
+	return rs.publishFile("Remove workflow "+name, fpath)
+}
+
+func (rs *RemoteStore) publishFile(message, fpath string) error {
+	rel, err := filepath.Rel(rs.dir, fpath)
+	if err != nil {
+		return fmt.Errorf("resolving workflow path: %w", err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is outside the source directory %s", fpath, rs.dir)
+	}
+	return rs.publish(message, filepath.ToSlash(rel))
 }
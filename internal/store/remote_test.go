@@ -0,0 +1,82 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteStore_ReadOnlyByDefault(t *testing.T) {
+	rs := NewRemoteStore(t.TempDir())
+
+	assert.True(t, rs.ReadOnly())
+	assert.Error(t, rs.Save(&Workflow{Name: "a", Command: "echo a"}))
+	assert.Error(t, rs.Delete("a"))
+	_, err := rs.Get("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRemoteStore_WritableSavesInPlaceAndPublishes(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "ops", "Restart App.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(existing), 0755))
+	require.NoError(t, os.WriteFile(existing, []byte("name: restart\ncommand: echo old\n"), 0644))
+
+	type publish struct {
+		message string
+		paths   []string
+	}
+	var published []publish
+	rs := NewWritableRemoteStore(dir, func(message string, paths ...string) error {
+		published = append(published, publish{message, paths})
+		return nil
+	})
+	assert.False(t, rs.ReadOnly())
+	assert.Equal(t, existing, rs.WorkflowPath("restart"))
+
+	require.NoError(t, rs.Save(&Workflow{Name: "restart", Command: "echo new"}))
+	got, err := rs.Get("restart")
+	require.NoError(t, err)
+	assert.Equal(t, "echo new", got.Command)
+	assert.Equal(t, SchemaVersion, got.Version)
+
+	require.NoError(t, rs.Save(&Workflow{Name: "infra/deploy", Command: "make deploy"}))
+	assert.FileExists(t, filepath.Join(dir, "infra", "deploy.yaml"))
+
+	require.NoError(t, rs.Delete("restart"))
+	assert.NoFileExists(t, existing)
+
+	assert.Equal(t, []publish{
+		{"Update workflow restart", []string{"ops/Restart App.yml"}},
+		{"Add workflow infra/deploy", []string{"infra/deploy.yaml"}},
+		{"Remove workflow restart", []string{"ops/Restart App.yml"}},
+	}, published)
+	assert.Error(t, rs.Delete("restart"))
+}
+
+func TestRemoteStore_PublishPathAfterRename(t *testing.T) {
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, "ops", "restart.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(fpath), 0755))
+	// The file was edited in place and its workflow renamed, so looking it
+	// up by either name would not find this file.
+	require.NoError(t, os.WriteFile(fpath, []byte("name: bounce\ncommand: echo new\n"), 0644))
+
+	var message string
+	var paths []string
+	rs := NewWritableRemoteStore(dir, func(m string, p ...string) error {
+		message, paths = m, p
+		return nil
+	})
+	var _ Publisher = rs
+
+	require.NoError(t, rs.PublishPath(fpath))
+	assert.Equal(t, "Update workflow bounce", message)
+	assert.Equal(t, []string{"ops/restart.yaml"}, paths)
+
+	assert.Error(t, rs.PublishPath(filepath.Join(t.TempDir(), "other.yaml")))
+	assert.Error(t, NewRemoteStore(dir).PublishPath(fpath))
+}
@@ -0,0 +1,26 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SchemaVersion is the workflow file schema this build reads and writes.
+// Save stamps it into every file; files without a version are treated as
+// version 1. Bump it when a change to the file format would be misread by
+// older builds.
+const SchemaVersion = 1
+
+// ParseWorkflow decodes a workflow file. Files written for a newer schema
+// than this build understands are rejected rather than half-read.
+func ParseWorkflow(data []byte) (*Workflow, error) {
+	var w Workflow
+	if err := yaml.Unmarshal(data, &w); err != nil {
+		return nil, err
+	}
+	if w.Version > SchemaVersion {
+		return nil, fmt.Errorf("schema version %d is newer than this wf supports (%d); upgrade wf", w.Version, SchemaVersion)
+	}
+	return &w, nil
+}
@@ -1,5 +1,11 @@
 package store
 
+import "errors"
+
+// ErrNotFound is wrapped by Get and Delete errors when no workflow has the
+// requested name, as opposed to one that exists but cannot be read.
+var ErrNotFound = errors.New("not found")
+
 // Store defines the interface for workflow CRUD operations.
 // Implementations handle persistence (YAML files, in-memory, etc.).
 type Store interface {
@@ -18,3 +24,21 @@ type Store interface {
 	// Returns an error if the workflow does not exist.
 	Delete(name string) error
 }
+
+// ProjectAlias is the MultiStore prefix for workflows in the current
+// project's .wf/ directory, e.g. "project/deploy".
+const ProjectAlias = "project"
+
+// ReadOnlyStore is implemented by stores that can report whether they
+// accept writes. MultiStore treats prefixed stores that do not implement
+// it as read-only.
+type ReadOnlyStore interface {
+	ReadOnly() bool
+}
+
+// Publisher is implemented by stores that share changes, for example by
+// committing and pushing them, and so must be told about files edited
+// outside Save.
+type Publisher interface {
+	PublishPath(path string) error
+}
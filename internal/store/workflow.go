@@ -7,25 +7,88 @@ import (
 
 // Workflow represents a saved command template with metadata.
 type Workflow struct {
+	Version     int      `yaml:"version,omitempty"` // Schema version the file was written for; 0 means 1
 	Name        string   `yaml:"name"`
 	Command     string   `yaml:"command"`
 	Description string   `yaml:"description"`
 	Tags        []string `yaml:"tags,omitempty"`
 	Args        []Arg    `yaml:"args,omitempty"`
+	Steps       []Step   `yaml:"steps,omitempty"`
+}
+
+// Step is one command in a multi-step workflow. Steps run in order; a failed
+// step stops the run unless ContinueOnError is set.
+type Step struct {
+	Name            string `yaml:"name,omitempty"`
+	Command         string `yaml:"command"`
+	When            string `yaml:"when,omitempty"`              // Condition on param values, e.g. "env == prod"
+	ContinueOnError bool   `yaml:"continue_on_error,omitempty"` // Keep going when this step fails
+	Confirm         bool   `yaml:"confirm,omitempty"`           // Ask before running this step
 }
 
 // Arg defines a named parameter for a workflow command.
 type Arg struct {
-	Name           string   `yaml:"name"`
-	Default        string   `yaml:"default,omitempty"`
-	Description    string   `yaml:"description,omitempty"`
-	Type           string   `yaml:"type,omitempty"`             // "text" (default/omitted), "enum", "dynamic", "list"
-	Options        []string `yaml:"options,omitempty"`          // For enum type
-	DynamicCmd     string   `yaml:"dynamic_cmd,omitempty"`      // For dynamic type
-	ListCmd        string   `yaml:"list_cmd,omitempty"`         // For list type: shell command producing rows
-	ListDelimiter  string   `yaml:"list_delimiter,omitempty"`   // For list type: literal field delimiter
-	ListFieldIndex int      `yaml:"list_field_index,omitempty"` // For list type: 1-based extracted field, 0 = whole row
-	ListSkipHeader int      `yaml:"list_skip_header,omitempty"` // For list type: number of leading rows to skip
+	Name           string            `yaml:"name"`
+	Default        string            `yaml:"default,omitempty"`
+	Description    string            `yaml:"description,omitempty"`
+	Type           string            `yaml:"type,omitempty"`             // "text" (default/omitted), "enum", "dynamic", "list", "secret"
+	Options        []string          `yaml:"options,omitempty"`          // For enum type
+	DynamicCmd     string            `yaml:"dynamic_cmd,omitempty"`      // For dynamic type
+	ListCmd        string            `yaml:"list_cmd,omitempty"`         // For list type: shell command producing rows
+	ListDelimiter  string            `yaml:"list_delimiter,omitempty"`   // For list type: literal field delimiter
+	ListFieldIndex int               `yaml:"list_field_index,omitempty"` // For list type: 1-based extracted field, 0 = whole row
+	ListSkipHeader int               `yaml:"list_skip_header,omitempty"` // For list type: number of leading rows to skip
+	ListMulti      bool              `yaml:"list_multi,omitempty"`       // For list type: several rows can be chosen and are joined
+	ListSeparator  string            `yaml:"list_separator,omitempty"`   // For multi lists: text between chosen values, default " "
+	ListQuote      string            `yaml:"list_quote,omitempty"`       // For multi lists: "single", "double" or "none"; by default values are quoted when needed
+	ListPrefix     string            `yaml:"list_prefix,omitempty"`      // For multi lists: flag repeated before each value, e.g. "-f"
+	SecretEnv      string            `yaml:"secret_env,omitempty"`       // For secret type: environment variable holding the value
+	SecretCmd      string            `yaml:"secret_cmd,omitempty"`       // For secret type: command printing the value, e.g. "pass show x"
+	Remember       *bool             `yaml:"remember,omitempty"`         // nil = true; false stops wf remembering used values
+	Raw            bool              `yaml:"raw,omitempty"`              // Insert the value unquoted, e.g. for a list of extra flags
+	Validate       *Validation       `yaml:"validate,omitempty"`         // Optional rules a value must satisfy before use
+	CacheTTL       string            `yaml:"cache_ttl,omitempty"`        // For dynamic/list types: how long command output is reused, e.g. "10m"
+	Shell          string            `yaml:"shell,omitempty"`            // For dynamic/list types: interpreter, e.g. "bash" (default from config, then sh)
+	Timeout        string            `yaml:"timeout,omitempty"`          // For dynamic/list types: maximum run time, e.g. "20s"
+	Workdir        string            `yaml:"workdir,omitempty"`          // For dynamic/list types: directory the command runs in
+	Env            map[string]string `yaml:"env,omitempty"`              // For dynamic/list types: extra environment variables
+}
+
+// Validation constrains the values accepted for an arg. All rules are
+// optional; an empty value only fails when Required is set.
+type Validation struct {
+	Required bool     `yaml:"required,omitempty"`
+	Kind     string   `yaml:"kind,omitempty"`    // "int", "float", "port", "url", "file", "dir", or "path"
+	Pattern  string   `yaml:"pattern,omitempty"` // Regular expression the whole value must match
+	Min      *float64 `yaml:"min,omitempty"`     // Inclusive lower bound for int/float/port
+	Max      *float64 `yaml:"max,omitempty"`     // Inclusive upper bound for int/float/port
+}
+
+// Remembers reports whether values used for this arg may be remembered and
+// offered again as defaults.
+func (a Arg) Remembers() bool {
+	if a.Type == "secret" {
+		return false
+	}
+	return a.Remember == nil || *a.Remember
+}
+
+// IsMultiStep reports whether the workflow defines ordered steps.
+func (w *Workflow) IsMultiStep() bool {
+	return len(w.Steps) > 0
+}
+
+// Template returns the text parameters are extracted from: the command for
+// single-step workflows, or every step command joined by newlines.
+func (w *Workflow) Template() string {
+	if !w.IsMultiStep() {
+		return w.Command
+	}
+	cmds := make([]string, len(w.Steps))
+	for i, step := range w.Steps {
+		cmds[i] = step.Command
+	}
+	return strings.Join(cmds, "\n")
 }
 
 var slugRe = regexp.MustCompile(`[^a-z0-9-]+`)
@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,9 @@ import (
 	"github.com/goccy/go-yaml"
 )
 
+// warnings receives a line for each workflow file List skips.
+var warnings io.Writer = os.Stderr
+
 // YAMLStore implements Store using YAML files on disk.
 // Each workflow is stored as a separate .yaml file under basePath.
 // Supports nested directories up to 2 levels for folder organization.
@@ -29,7 +33,9 @@ func (s *YAMLStore) Save(w *Workflow) error {
 		return fmt.Errorf("creating store directory: %w", err)
 	}
 
-	data, err := yaml.Marshal(w)
+	out := *w
+	out.Version = SchemaVersion
+	data, err := yaml.Marshal(&out)
 	if err != nil {
 		return fmt.Errorf("marshalling workflow: %w", err)
 	}
@@ -57,20 +63,21 @@ func (s *YAMLStore) Get(name string) (*Workflow, error) {
 	data, err := os.ReadFile(fpath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("workflow %q not found", name)
+			return nil, fmt.Errorf("workflow %q %w", name, ErrNotFound)
 		}
 		return nil, fmt.Errorf("reading workflow file: %w", err)
 	}
 
-	var w Workflow
-	if err := yaml.Unmarshal(data, &w); err != nil {
+	w, err := ParseWorkflow(data)
+	if err != nil {
 		return nil, fmt.Errorf("parsing workflow file: %w", err)
 	}
 
-	return &w, nil
+	return w, nil
 }
 
 // List returns all workflows found under basePath, scanning up to 2 levels deep.
+// Files that cannot be parsed are skipped with a warning on stderr.
 func (s *YAMLStore) List() ([]Workflow, error) {
 	var workflows []Workflow
 
@@ -100,12 +107,15 @@ func (s *YAMLStore) List() ([]Workflow, error) {
 			return fmt.Errorf("reading %s: %w", path, err)
 		}
 
-		var w Workflow
-		if err := yaml.Unmarshal(data, &w); err != nil {
-			return fmt.Errorf("parsing %s: %w", path, err)
+		// Skip malformed YAML and newer schema versions, as RemoteStore
+		// does, so one bad file does not hide every other workflow.
+		w, err := ParseWorkflow(data)
+		if err != nil {
+			fmt.Fprintf(warnings, "warning: skipping %s: %v (run wf lint for details)\n", path, err)
+			return nil
 		}
 
-		workflows = append(workflows, w)
+		workflows = append(workflows, *w)
 		return nil
 	})
 
@@ -121,7 +131,7 @@ func (s *YAMLStore) Delete(name string) error {
 	fpath := s.WorkflowPath(name)
 
 	if _, err := os.Stat(fpath); os.IsNotExist(err) {
-		return fmt.Errorf("workflow %q not found", name)
+		return fmt.Errorf("workflow %q %w", name, ErrNotFound)
 	}
 
 	if err := os.Remove(fpath); err != nil {
@@ -131,6 +141,11 @@ func (s *YAMLStore) Delete(name string) error {
 	return nil
 }
 
+// ReadOnly reports false: a YAMLStore always accepts writes.
+func (s *YAMLStore) ReadOnly() bool {
+	return false
+}
+
 // WorkflowPath resolves the filesystem path for a workflow name.
 // Names can include path separators for folder organization (e.g., "infra/deploy").
 func (s *YAMLStore) WorkflowPath(name string) string {
@@ -1,8 +1,10 @@
 package store
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -286,3 +288,125 @@ func TestListNonExistentDirectory(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, workflows)
 }
+
+func TestStepsRoundTripAndTemplate(t *testing.T) {
+	dir := t.TempDir()
+	s := NewYAMLStore(dir)
+
+	w := &Workflow{
+		Name: "release",
+		Steps: []Step{
+			{Name: "build", Command: "make build VERSION={{version}}"},
+			{Name: "publish", Command: "make publish", When: "env == prod", ContinueOnError: true, Confirm: true},
+		},
+	}
+	require.NoError(t, s.Save(w))
+
+	got, err := s.Get("release")
+	require.NoError(t, err)
+	require.True(t, got.IsMultiStep())
+	assert.Equal(t, w.Steps, got.Steps)
+	assert.Equal(t, "make build VERSION={{version}}\nmake publish", got.Template())
+}
+
+func TestArgRememberRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := NewYAMLStore(dir)
+
+	off := false
+	w := &Workflow{
+		Name:    "login",
+		Command: "login {{user}} {{token}}",
+		Args: []Arg{
+			{Name: "user"},
+			{Name: "token", Remember: &off},
+		},
+	}
+	require.NoError(t, s.Save(w))
+
+	data, err := os.ReadFile(filepath.Join(dir, "login.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "remember: false")
+	assert.Equal(t, 1, strings.Count(string(data), "remember:"), "unset remember is omitted")
+
+	got, err := s.Get("login")
+	require.NoError(t, err)
+	assert.True(t, got.Args[0].Remembers())
+	assert.False(t, got.Args[1].Remembers())
+}
+
+func TestArgValidateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := NewYAMLStore(dir)
+
+	lo := 1024.0
+	w := &Workflow{
+		Name:    "serve",
+		Command: "serve --port {{port}}",
+		Args: []Arg{
+			{Name: "port", Validate: &Validation{Required: true, Kind: "port", Min: &lo}},
+		},
+	}
+	require.NoError(t, s.Save(w))
+
+	data, err := os.ReadFile(filepath.Join(dir, "serve.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "kind: port")
+	assert.NotContains(t, string(data), "max:", "unset rules are omitted")
+
+	got, err := s.Get("serve")
+	require.NoError(t, err)
+	require.NotNil(t, got.Args[0].Validate)
+	assert.True(t, got.Args[0].Validate.Required)
+	assert.Equal(t, "port", got.Args[0].Validate.Kind)
+	assert.Equal(t, 1024.0, *got.Args[0].Validate.Min)
+	assert.Nil(t, got.Args[0].Validate.Max)
+}
+
+func TestYAMLStore_SaveStampsSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	s := NewYAMLStore(dir)
+	wf := &Workflow{Name: "ls", Command: "ls"}
+	require.NoError(t, s.Save(wf))
+	assert.Zero(t, wf.Version, "caller's workflow is not modified")
+
+	data, err := os.ReadFile(filepath.Join(dir, "ls.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "version: 1")
+
+	got, err := s.Get("ls")
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, got.Version)
+}
+
+func TestYAMLStore_RejectsNewerSchemaVersion(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "future.yaml"), []byte("version: 2\nname: future\ncommand: ls\n"), 0644))
+
+	_, err := NewYAMLStore(dir).Get("future")
+	assert.ErrorContains(t, err, "schema version 2 is newer")
+}
+
+func TestYAMLStore_ListSkipsBadFilesLikeRemoteStore(t *testing.T) {
+	dir := t.TempDir()
+	s := NewYAMLStore(dir)
+	require.NoError(t, s.Save(&Workflow{Name: "ok", Command: "ls"}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: [unclosed\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "future.yaml"), []byte("version: 2\nname: future\ncommand: ls\n"), 0644))
+
+	var warned bytes.Buffer
+	warnings = &warned
+	t.Cleanup(func() { warnings = os.Stderr })
+
+	local, err := s.List()
+	require.NoError(t, err)
+	remote, err := NewRemoteStore(dir).List()
+	require.NoError(t, err)
+
+	require.Len(t, local, 1)
+	assert.Equal(t, "ok", local[0].Name)
+	assert.Equal(t, local, remote)
+	assert.Contains(t, warned.String(), "skipping "+filepath.Join(dir, "broken.yaml"))
+	assert.Contains(t, warned.String(), "skipping "+filepath.Join(dir, "future.yaml"))
+	assert.Contains(t, warned.String(), "wf lint")
+}
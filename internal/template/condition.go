@@ -0,0 +1,112 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EvalCondition evaluates a step's when: expression against param values.
+//
+// Supported forms:
+//
+//	env == prod        equality (value may be quoted)
+//	env != dev         inequality
+//	dry_run            truthy: non-empty and not false/0/no/off
+//	!dry_run           negated truthiness
+//	a == x && b != y   conjunction; || separates alternatives
+//
+// An empty condition is always true.
+func EvalCondition(cond string, values map[string]string) (bool, error) {
+	cond = strings.TrimSpace(cond)
+	if cond == "" {
+		return true, nil
+	}
+
+	for _, alt := range strings.Split(cond, "||") {
+		all := true
+		for _, term := range strings.Split(alt, "&&") {
+			ok, err := evalTerm(strings.TrimSpace(term), values)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ConditionNames returns the param names referenced by a condition, in order
+// of first appearance.
+func ConditionNames(cond string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, alt := range strings.Split(cond, "||") {
+		for _, term := range strings.Split(alt, "&&") {
+			name := conditionTermName(strings.TrimSpace(term))
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func conditionTermName(term string) string {
+	for _, op := range []string{"==", "!="} {
+		if idx := strings.Index(term, op); idx >= 0 {
+			return strings.TrimSpace(term[:idx])
+		}
+	}
+	return strings.TrimSpace(strings.TrimPrefix(term, "!"))
+}
+
+func evalTerm(term string, values map[string]string) (bool, error) {
+	if term == "" {
+		return false, fmt.Errorf("empty term in condition")
+	}
+
+	for _, op := range []string{"==", "!="} {
+		if idx := strings.Index(term, op); idx >= 0 {
+			name := strings.TrimSpace(term[:idx])
+			if name == "" {
+				return false, fmt.Errorf("condition %q has no parameter name", term)
+			}
+			want := unquote(strings.TrimSpace(term[idx+len(op):]))
+			equal := values[name] == want
+			if op == "==" {
+				return equal, nil
+			}
+			return !equal, nil
+		}
+	}
+
+	if strings.HasPrefix(term, "!") {
+		return !truthy(values[strings.TrimSpace(term[1:])]), nil
+	}
+	return truthy(values[term]), nil
+}
+
+func truthy(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "false", "0", "no", "off":
+		return false
+	default:
+		return true
+	}
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 {
+		if (s[0] == '"' && s[len(s)-1] == '"') || (s[0] == '\'' && s[len(s)-1] == '\'') {
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubContext(t *testing.T, vars map[string]string) {
+	t.Helper()
+	resolveContext = func(name, arg string) (string, bool) {
+		if name == "@date" {
+			if arg == "" {
+				arg = "default-layout"
+			}
+			return "date(" + arg + ")", true
+		}
+		v, ok := vars[name]
+		return v, ok
+	}
+	t.Cleanup(func() { resolveContext = defaultResolveContext })
+}
+
+func TestRender_ContextVariables(t *testing.T) {
+	stubContext(t, map[string]string{"@git.branch": "Feature/X", "@cwd": "/src/app"})
+
+	got := Render("cd {{@cwd}} && git push origin {{@git.branch}} {{@git.branch |> slug}} {{@date:2006-01-02}}", nil)
+	assert.Equal(t, "cd /src/app && git push origin Feature/X feature-x date(2006-01-02)", got)
+
+	assert.Equal(t, "main", Render("{{@git.sha:main}}", nil), "unresolved variable falls back to its default")
+	assert.Equal(t, "{{@git.sha}}", Render("{{@git.sha}}", nil))
+}
+
+func TestRenderQuoted_QuotesContextValues(t *testing.T) {
+	stubContext(t, map[string]string{"@clipboard": "it's pasted"})
+	assert.Equal(t, `echo 'it'\''s pasted'`, RenderQuoted("echo {{@clipboard}}", nil, QuoteOptions{}))
+}
+
+func TestExtractParams_SkipsContextVariables(t *testing.T) {
+	params := ExtractParams("deploy {{@git.branch}} to {{env}} on {{@date:2006-01-02}}")
+	require.Len(t, params, 1)
+	assert.Equal(t, "env", params[0].Name)
+}
+
+func TestResolveContext_LeavesParams(t *testing.T) {
+	stubContext(t, map[string]string{"@user": "ada"})
+	assert.Equal(t, "ssh {{host:web}} -l ada", ResolveContext("ssh {{host:web}} -l {{@user}}"))
+}
+
+func TestCheckContextVars(t *testing.T) {
+	assert.NoError(t, CheckContextVars("echo {{@git.branch}} {{@env.HOME}} {{name}}"))
+	assert.ErrorContains(t, CheckContextVars("echo {{@gti.branch}}"), `unknown context variable "@gti.branch"`)
+}
@@ -0,0 +1,108 @@
+package template
+
+import "strings"
+
+// escapedOpen is written in a template where a literal {{ is wanted, such as
+// docker inspect --format '\{{.State.Status}}'. It renders as {{ and never
+// starts a placeholder.
+const escapedOpen = `\{{`
+
+// EscapeBraces escapes every {{ in s so it is kept literally, for commands
+// taken from places that do not use wf placeholders.
+func EscapeBraces(s string) string {
+	return strings.ReplaceAll(s, "{{", escapedOpen)
+}
+
+// unescapeBraces turns escaped braces in literal template text back into {{.
+func unescapeBraces(s string) string {
+	return strings.ReplaceAll(s, escapedOpen, "{{")
+}
+
+// placeholderIndexes returns the start and end of every {{...}} placeholder
+// in command, skipping escaped ones.
+func placeholderIndexes(command string) [][]int {
+	var out [][]int
+	for _, m := range paramRegex.FindAllStringIndex(command, -1) {
+		if m[0] > 0 && command[m[0]-1] == '\\' {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// placeholderInners returns the text inside every unescaped placeholder.
+func placeholderInners(command string) []string {
+	idx := placeholderIndexes(command)
+	out := make([]string, len(idx))
+	for i, m := range idx {
+		out[i] = command[m[0]+2 : m[1]-2]
+	}
+	return out
+}
+
+// replacePlaceholders rebuilds command with each unescaped placeholder
+// replaced by fn(match, inner). Escaped braces in the text between
+// placeholders are turned into {{ when unescape is set.
+func replacePlaceholders(command string, unescape bool, fn func(match, inner string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range placeholderIndexes(command) {
+		literal := command[last:m[0]]
+		if unescape {
+			literal = unescapeBraces(literal)
+		}
+		b.WriteString(literal)
+		match := command[m[0]:m[1]]
+		b.WriteString(fn(match, match[2:len(match)-2]))
+		last = m[1]
+	}
+	rest := command[last:]
+	if unescape {
+		rest = unescapeBraces(rest)
+	}
+	b.WriteString(rest)
+	return b.String()
+}
+
+// Placeholder is one placeholder found by Rewrite.
+type Placeholder struct {
+	Param      Param
+	Transforms []Transform
+	Text       string // As written, e.g. {{env|dev|prod}}
+	Quote      byte   // 0 in a bare word, or the ' or " it sits inside (POSIX rules)
+}
+
+// Rewrite returns command with each placeholder replaced by fn's result and
+// escaped braces turned into literal {{. Exporters use it to translate
+// placeholders into another tool's syntax.
+func Rewrite(command string, fn func(ph Placeholder) string) string {
+	var b strings.Builder
+	ctx := contextBare
+	escaped := false
+	last := 0
+	for _, m := range placeholderIndexes(command) {
+		literal := command[last:m[0]]
+		ctx, escaped = scanQuotes(literal, ctx, escaped, DialectPOSIX)
+		b.WriteString(unescapeBraces(literal))
+
+		text := command[m[0]:m[1]]
+		p, ts := parsePlaceholder(text[2 : len(text)-2])
+		ph := Placeholder{Param: p, Transforms: ts, Text: text}
+		switch ctx {
+		case contextSingle:
+			ph.Quote = '\''
+		case contextDouble:
+			ph.Quote = '"'
+		}
+		b.WriteString(fn(ph))
+		last = m[1]
+	}
+	b.WriteString(unescapeBraces(command[last:]))
+	return b.String()
+}
+
+// HasEscapedBraces reports whether command contains an escaped \{{.
+func HasEscapedBraces(command string) bool {
+	return strings.Contains(command, escapedOpen)
+}
@@ -0,0 +1,59 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractParams_SkipsEscapedBraces(t *testing.T) {
+	params := ExtractParams(`docker ps --filter name={{name}} --format '\{{.Names}} \{{.State}}'`)
+	require.Len(t, params, 1)
+	assert.Equal(t, "name", params[0].Name)
+}
+
+func TestRender_UnescapesBraces(t *testing.T) {
+	got := Render(`docker inspect --format '\{{.State.Status}}' {{id}}`, map[string]string{"id": "web"})
+	assert.Equal(t, `docker inspect --format '{{.State.Status}}' web`, got)
+}
+
+func TestRenderQuoted_UnescapesBraces(t *testing.T) {
+	got := RenderQuoted(`kubectl get pods -o go-template='\{{range .items}}\{{.metadata.name}}\{{end}}' -n {{ns}}`,
+		map[string]string{"ns": "my ns"}, QuoteOptions{})
+	assert.Equal(t, `kubectl get pods -o go-template='{{range .items}}{{.metadata.name}}{{end}}' -n 'my ns'`, got)
+
+	assert.Equal(t, "echo {{x}}", RenderQuoted(`echo \{{x}}`, nil, QuoteOptions{}))
+}
+
+func TestResolveContext_KeepsEscapes(t *testing.T) {
+	assert.Equal(t, `echo \{{@cwd}} {{name}}`, ResolveContext(`echo \{{@cwd}} {{name}}`))
+}
+
+func TestCheck_IgnoresEscapedPlaceholders(t *testing.T) {
+	assert.NoError(t, CheckTransforms(`echo \{{x |> nope}}`))
+	assert.NoError(t, CheckContextVars(`echo \{{@nope}}`))
+}
+
+func TestEscapeBraces(t *testing.T) {
+	s := EscapeBraces(`docker ps --format '{{.Names}}'`)
+	assert.Equal(t, `docker ps --format '\{{.Names}}'`, s)
+	assert.Empty(t, ExtractParams(s))
+	assert.Equal(t, `docker ps --format '{{.Names}}'`, Render(s, nil))
+}
+
+func TestRewrite(t *testing.T) {
+	var seen []Placeholder
+	got := Rewrite(`echo {{a}} '{{b:x}}' "{{c |> upper}}" \{{d}}`, func(ph Placeholder) string {
+		seen = append(seen, ph)
+		return "<" + ph.Param.Name + ">"
+	})
+	assert.Equal(t, `echo <a> '<b>' "<c>" {{d}}`, got)
+	require.Len(t, seen, 3)
+	assert.Equal(t, byte(0), seen[0].Quote)
+	assert.Equal(t, byte('\''), seen[1].Quote)
+	assert.Equal(t, "x", seen[1].Param.Default)
+	assert.Equal(t, byte('"'), seen[2].Quote)
+	assert.Equal(t, "upper", seen[2].Transforms[0].Name)
+	assert.Equal(t, "{{c |> upper}}", seen[2].Text)
+}
@@ -3,6 +3,9 @@ package template
 import (
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/fredriklanga/wf/internal/contextvar"
 )
 
 // ParamType discriminates parameter behavior.
@@ -13,6 +16,7 @@ const (
 	ParamEnum                     // Selection from predefined options
 	ParamDynamic                  // Options populated by shell command
 	ParamList                     // Selection from shell-command rows with deferred extraction
+	ParamSecret                   // Masked free text that is never displayed, remembered, or logged
 )
 
 // String returns the author-facing name for a parameter type.
@@ -24,6 +28,8 @@ func (t ParamType) String() string {
 		return "dynamic"
 	case ParamList:
 		return "list"
+	case ParamSecret:
+		return "secret"
 	default:
 		return "text"
 	}
@@ -38,6 +44,8 @@ func ParamTypeFromString(s string) ParamType {
 		return ParamDynamic
 	case "list":
 		return ParamList
+	case "secret":
+		return ParamSecret
 	default:
 		return ParamText
 	}
@@ -54,6 +62,41 @@ type Param struct {
 	ListDelimiter  string   // For ParamList: literal field delimiter
 	ListFieldIndex int      // For ParamList: 1-based extracted field, 0 = whole row
 	ListSkipHeader int      // For ParamList: leading rows removed before selection
+	ListMulti      bool     // For ParamList: several rows can be chosen, see ListJoin
+	ListJoin       ListJoin // For ParamList with ListMulti: how chosen values are combined
+	SecretEnv      string   // For ParamSecret: environment variable holding the value
+	SecretCmd      string   // For ParamSecret: shell command printing the value
+	NoRemember     bool     // Used values are not remembered (remember: false, or secret)
+	Validation     *Validation
+	CacheTTL       time.Duration  // For ParamDynamic/ParamList: how long command output is reused, 0 = never cached
+	Exec           CommandOptions // For ParamDynamic/ParamList: how the command is run
+}
+
+// ListJoin controls how the values chosen in a multi-select list param are
+// combined into one string.
+type ListJoin struct {
+	Separator string // Between values, default " "
+	Quote     string // "single" or "double" wraps each value in quotes, "none" leaves it bare, "" quotes it when needed
+	Prefix    string // Placed before each value, e.g. "-f" gives "-f x -f y"; a prefix ending in "=" is not followed by a space
+}
+
+// CommandOptions controls how a dynamic or list param's command is run.
+// Zero values fall back to the global defaults.
+type CommandOptions struct {
+	Shell   string            // Interpreter and flags; the command is passed after -c
+	Timeout time.Duration     // Maximum run time
+	Dir     string            // Working directory
+	Env     map[string]string // Extra environment variables
+}
+
+// Validation holds the rules a param value must satisfy. See
+// params.Validate for how each rule is applied.
+type Validation struct {
+	Required bool
+	Kind     string // "int", "float", "port", "url", "file", "dir", or "path"
+	Pattern  string // regular expression the whole value must match
+	Min      *float64
+	Max      *float64
 }
 
 // paramRegex matches {{content}} patterns where content is one or more non-} characters.
@@ -61,10 +104,13 @@ var paramRegex = regexp.MustCompile(`\{\{([^}]+)\}\}`)
 
 // ExtractParams extracts unique parameters from a command string.
 // Parameters use {{name}}, {{name:default}}, {{name|opt1|opt2|*default}},
-// or {{name!command}} syntax.
+// or {{name!command}} syntax, optionally followed by transforms such as
+// {{name |> trimprefix "v" |> upper}}.
+// Context variables such as {{@cwd}} are not parameters and are skipped, as
+// are escaped placeholders written \{{...}}.
 // Duplicates are deduplicated by name (last default wins), preserving order of first appearance.
 func ExtractParams(command string) []Param {
-	matches := paramRegex.FindAllStringSubmatch(command, -1)
+	matches := placeholderInners(command)
 	if len(matches) == 0 {
 		return nil
 	}
@@ -73,8 +119,11 @@ func ExtractParams(command string) []Param {
 	var params []Param
 
 	for _, match := range matches {
-		inner := match[1]
-		p := parseInner(inner)
+		p, _ := parsePlaceholder(match)
+		if contextvar.IsContext(p.Name) {
+			// Resolved at render time, never prompted for.
+			continue
+		}
 
 		if idx, exists := seen[p.Name]; exists {
 			// Last default wins for duplicates
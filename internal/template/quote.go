@@ -0,0 +1,223 @@
+package template
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Dialect identifies the quoting rules of the shell a rendered command is
+// meant for.
+type Dialect int
+
+const (
+	DialectPOSIX      Dialect = iota // sh, bash, zsh and compatible shells
+	DialectFish                      // fish
+	DialectPowerShell                // pwsh and Windows PowerShell
+)
+
+// DialectFromShell picks the dialect for a shell path or name such as
+// "/bin/zsh", "pwsh.exe" or "bash -O extglob". Unknown shells get POSIX
+// rules.
+func DialectFromShell(shell string) Dialect {
+	if d, ok := dialectByName(shell); ok {
+		return d
+	}
+	if fields := strings.Fields(shell); len(fields) > 0 {
+		if d, ok := dialectByName(fields[0]); ok {
+			return d
+		}
+	}
+	return DialectPOSIX
+}
+
+func dialectByName(shell string) (Dialect, bool) {
+	base := strings.ToLower(filepath.Base(strings.ReplaceAll(shell, `\`, "/")))
+	switch strings.TrimSuffix(base, ".exe") {
+	case "fish":
+		return DialectFish, true
+	case "pwsh", "powershell":
+		return DialectPowerShell, true
+	case "sh", "bash", "zsh", "dash", "ksh":
+		return DialectPOSIX, true
+	}
+	return DialectPOSIX, false
+}
+
+// QuoteOptions controls RenderQuoted.
+type QuoteOptions struct {
+	Dialect Dialect
+	Raw     map[string]bool // Param names whose values are inserted unchanged
+}
+
+// quoteContext is where a placeholder sits in the surrounding command.
+type quoteContext int
+
+const (
+	contextBare quoteContext = iota
+	contextSingle
+	contextDouble
+)
+
+// RenderQuoted is like Render, but escapes each supplied value so the shell
+// sees it as literal text. A placeholder inside single quotes, inside double
+// quotes, or in a bare word is escaped for that position, so
+// `git commit -m "{{msg}}"` and `git commit -m {{msg}}` both survive a
+// message like `it's $5`. Values of params listed in opts.Raw and defaults
+// written in the template itself are inserted unchanged.
+func RenderQuoted(command string, values map[string]string, opts QuoteOptions) string {
+	matches := placeholderIndexes(command)
+	if len(matches) == 0 {
+		return unescapeBraces(command)
+	}
+
+	var b strings.Builder
+	ctx := contextBare
+	escaped := false
+	last := 0
+	for _, m := range matches {
+		literal := command[last:m[0]]
+		ctx, escaped = scanQuotes(literal, ctx, escaped, opts.Dialect)
+		b.WriteString(unescapeBraces(literal))
+
+		match := command[m[0]:m[1]]
+		p, ts := parsePlaceholder(match[2 : len(match)-2])
+		v, supplied, ok := placeholderValue(p, ts, values)
+		switch {
+		case !ok:
+			b.WriteString(match)
+		case supplied && !opts.Raw[p.Name]:
+			b.WriteString(quoteValue(v, ctx, opts.Dialect))
+		default:
+			b.WriteString(v)
+		}
+		last = m[1]
+	}
+	b.WriteString(unescapeBraces(command[last:]))
+	return b.String()
+}
+
+// scanQuotes advances the quote context over literal command text. escaped
+// reports that the last character was an escape that applies to the next
+// one.
+func scanQuotes(s string, ctx quoteContext, escaped bool, d Dialect) (quoteContext, bool) {
+	escape := byte('\\')
+	if d == DialectPowerShell {
+		escape = '`'
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if escaped {
+			escaped = false
+			continue
+		}
+		switch ctx {
+		case contextBare:
+			switch c {
+			case escape:
+				escaped = true
+			case '\'':
+				ctx = contextSingle
+			case '"':
+				ctx = contextDouble
+			}
+		case contextSingle:
+			switch {
+			case c == '\'':
+				ctx = contextBare
+			case c == '\\' && d == DialectFish:
+				escaped = true
+			}
+		case contextDouble:
+			switch c {
+			case escape:
+				escaped = true
+			case '"':
+				ctx = contextBare
+			}
+		}
+	}
+	return ctx, escaped
+}
+
+// QuoteListItem quotes one value chosen in a multi-select list param for
+// the dialect. style "single" or "double" wraps the value in that kind of
+// quotes, "none" leaves it unchanged, and "" quotes it as a bare word would
+// be, only when needed.
+func (d Dialect) QuoteListItem(v, style string) string {
+	switch style {
+	case "single":
+		return "'" + quoteValue(v, contextSingle, d) + "'"
+	case "double":
+		return `"` + quoteValue(v, contextDouble, d) + `"`
+	case "none":
+		return v
+	}
+	return quoteValue(v, contextBare, d)
+}
+
+// quoteValue escapes v for insertion at a position with the given context.
+func quoteValue(v string, ctx quoteContext, d Dialect) string {
+	switch d {
+	case DialectFish:
+		switch ctx {
+		case contextSingle:
+			return fishSingleEscaper.Replace(v)
+		case contextDouble:
+			return fishDoubleEscaper.Replace(v)
+		}
+		if isBareSafe(v) {
+			return v
+		}
+		return "'" + fishSingleEscaper.Replace(v) + "'"
+	case DialectPowerShell:
+		switch ctx {
+		case contextSingle:
+			return powerShellSingleEscaper.Replace(v)
+		case contextDouble:
+			return powerShellDoubleEscaper.Replace(v)
+		}
+		if isBareSafe(v) {
+			return v
+		}
+		return "'" + powerShellSingleEscaper.Replace(v) + "'"
+	default:
+		switch ctx {
+		case contextSingle:
+			// Close the quotes, add an escaped quote, and reopen them.
+			return strings.ReplaceAll(v, "'", `'\''`)
+		case contextDouble:
+			return posixDoubleEscaper.Replace(v)
+		}
+		if isBareSafe(v) {
+			return v
+		}
+		return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+	}
+}
+
+var (
+	posixDoubleEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+	fishSingleEscaper       = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	fishDoubleEscaper       = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	powerShellSingleEscaper = strings.NewReplacer(`'`, `''`)
+	powerShellDoubleEscaper = strings.NewReplacer("`", "``", `"`, "`\"", `$`, "`$")
+)
+
+// isBareSafe reports whether v can appear as an unquoted word in every
+// supported shell without changing meaning.
+func isBareSafe(v string) bool {
+	if v == "" {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("-_./:+", c) >= 0:
+		case c == '=' && i > 0: // zsh expands a leading = to a command path
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderQuoted_POSIXContexts(t *testing.T) {
+	values := map[string]string{"msg": `it's $HOME "now"`, "file": "notes.txt"}
+	opts := QuoteOptions{Dialect: DialectPOSIX}
+
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"bare", `git commit -m {{msg}}`, `git commit -m 'it'\''s $HOME "now"'`},
+		{"single quoted", `git commit -m '{{msg}}'`, `git commit -m 'it'\''s $HOME "now"'`},
+		{"double quoted", `git commit -m "{{msg}}"`, `git commit -m "it's \$HOME \"now\""`},
+		{"safe bare value", `cat {{file}}`, `cat notes.txt`},
+		{"escaped quote stays bare", `echo \' {{file}}`, `echo \' notes.txt`},
+		{"after closed quotes", `echo 'a' "b" {{msg}}`, `echo 'a' "b" 'it'\''s $HOME "now"'`},
+		{"template default untouched", `echo {{other:a b}}`, `echo a b`},
+		{"missing value untouched", `echo {{other}}`, `echo {{other}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RenderQuoted(tt.command, values, opts))
+		})
+	}
+}
+
+func TestRenderQuoted_EmptyValueBecomesEmptyWord(t *testing.T) {
+	got := RenderQuoted("cmd {{a}} {{b}}", map[string]string{"a": "", "b": "x"}, QuoteOptions{})
+	assert.Equal(t, "cmd '' x", got)
+}
+
+func TestRenderQuoted_RawOptOut(t *testing.T) {
+	got := RenderQuoted("ls {{flags}} {{dir}}", map[string]string{"flags": "-la --color", "dir": "my dir"}, QuoteOptions{
+		Raw: map[string]bool{"flags": true},
+	})
+	assert.Equal(t, "ls -la --color 'my dir'", got)
+}
+
+func TestRenderQuoted_Fish(t *testing.T) {
+	values := map[string]string{"msg": `it's \ $x`}
+	opts := QuoteOptions{Dialect: DialectFish}
+	assert.Equal(t, `echo 'it\'s \\ $x'`, RenderQuoted(`echo {{msg}}`, values, opts))
+	assert.Equal(t, `echo 'it\'s \\ $x'`, RenderQuoted(`echo '{{msg}}'`, values, opts))
+	assert.Equal(t, `echo "it's \\ \$x"`, RenderQuoted(`echo "{{msg}}"`, values, opts))
+}
+
+func TestRenderQuoted_PowerShell(t *testing.T) {
+	values := map[string]string{"msg": "it's `$x` \"y\""}
+	opts := QuoteOptions{Dialect: DialectPowerShell}
+	assert.Equal(t, "echo 'it''s `$x` \"y\"'", RenderQuoted(`echo {{msg}}`, values, opts))
+	assert.Equal(t, "echo 'it''s `$x` \"y\"'", RenderQuoted(`echo '{{msg}}'`, values, opts))
+	assert.Equal(t, "echo \"it's ```$x`` `\"y`\"\"", RenderQuoted(`echo "{{msg}}"`, values, opts))
+}
+
+func TestDialectFromShell(t *testing.T) {
+	assert.Equal(t, DialectPOSIX, DialectFromShell("/bin/zsh"))
+	assert.Equal(t, DialectPOSIX, DialectFromShell("bash -O extglob"))
+	assert.Equal(t, DialectPOSIX, DialectFromShell(""))
+	assert.Equal(t, DialectFish, DialectFromShell("/usr/local/bin/fish"))
+	assert.Equal(t, DialectPowerShell, DialectFromShell(`C:\Program Files\PowerShell\7\pwsh.exe`))
+	assert.Equal(t, DialectPowerShell, DialectFromShell("powershell -NoProfile"))
+}
@@ -1,25 +1,81 @@
 package template
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fredriklanga/wf/internal/contextvar"
+)
+
 // Render substitutes all {{name}}, {{name:default}}, {{name|opt1|*default}},
-// and {{name!command}} occurrences in command with values from the provided map.
+// and {{name!command}} occurrences in command with values from the provided map,
+// applying any transforms such as {{name |> upper}}. Context variables such
+// as {{@git.branch}} are resolved automatically.
 // If a parameter has no value and no default, the placeholder is left as-is.
+// An escaped \{{ renders as a literal {{.
 func Render(command string, values map[string]string) string {
-	return paramRegex.ReplaceAllStringFunc(command, func(match string) string {
-		// Strip {{ and }}
-		inner := match[2 : len(match)-2]
-		p := parseInner(inner)
-
-		if values != nil {
-			if v, ok := values[p.Name]; ok {
-				return v
-			}
+	return replacePlaceholders(command, true, func(match, inner string) string {
+		p, ts := parsePlaceholder(inner)
+		if v, _, ok := placeholderValue(p, ts, values); ok {
+			return v
 		}
 
-		if p.Default != "" {
-			return p.Default
+		// No value, no default — leave placeholder
+		return match
+	})
+}
+
+// resolveContext looks up {{@...}} variables; replaced in tests.
+var resolveContext = defaultResolveContext
+
+var defaultResolveContext = contextvar.Resolve
+
+// placeholderValue returns the transformed value for a placeholder.
+// supplied reports that it came from values or the environment rather than
+// from a default written in the template; ok is false when there is
+// neither.
+func placeholderValue(p Param, ts []Transform, values map[string]string) (v string, supplied, ok bool) {
+	if v, ok := values[p.Name]; ok {
+		return ApplyTransforms(v, ts), true, true
+	}
+	if contextvar.IsContext(p.Name) {
+		if contextvar.TakesArg(p.Name) {
+			v, ok := resolveContext(p.Name, p.Default)
+			return ApplyTransforms(v, ts), true, ok
 		}
+		if v, ok := resolveContext(p.Name, ""); ok {
+			return ApplyTransforms(v, ts), true, true
+		}
+	}
+	if p.Default != "" || hasTransform(ts, "default") {
+		return ApplyTransforms(p.Default, ts), false, true
+	}
+	return "", false, false
+}
 
-		// No value, no default — leave placeholder
+// ResolveContext substitutes only the context variables in command, leaving
+// param placeholders as written. It is used for previews of a workflow that
+// has not been filled in yet. Escaped braces are kept as written.
+func ResolveContext(command string) string {
+	return replacePlaceholders(command, false, func(match, inner string) string {
+		p, ts := parsePlaceholder(inner)
+		if !contextvar.IsContext(p.Name) {
+			return match
+		}
+		if v, _, ok := placeholderValue(p, ts, nil); ok {
+			return v
+		}
 		return match
 	})
 }
+
+// CheckContextVars reports the first unknown {{@...}} variable in command.
+func CheckContextVars(command string) error {
+	for _, inner := range placeholderInners(command) {
+		p, _ := parsePlaceholder(inner)
+		if contextvar.IsContext(p.Name) && !contextvar.Known(p.Name) {
+			return fmt.Errorf("{{%s}}: unknown context variable %q (want one of @%s)", inner, p.Name, strings.Join(contextvar.Names(), ", @"))
+		}
+	}
+	return nil
+}
@@ -279,3 +279,45 @@ func TestRender_EnumNoDefaultPlaceholderStays(t *testing.T) {
 		t.Errorf("got %q, want %q", result, "deploy {{env|dev|staging|prod}}")
 	}
 }
+
+// --- Step conditions ---
+
+func TestEvalCondition(t *testing.T) {
+	values := map[string]string{"env": "prod", "dry_run": "false", "app": "api"}
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{"", true},
+		{"env == prod", true},
+		{"env == 'prod'", true},
+		{"env != prod", false},
+		{"dry_run", false},
+		{"!dry_run", true},
+		{"app", true},
+		{"missing", false},
+		{"env == dev || app == api", true},
+		{"env == prod && dry_run", false},
+	}
+	for _, tt := range tests {
+		got, err := EvalCondition(tt.cond, values)
+		if err != nil {
+			t.Fatalf("EvalCondition(%q): %v", tt.cond, err)
+		}
+		if got != tt.want {
+			t.Errorf("EvalCondition(%q) = %v, want %v", tt.cond, got, tt.want)
+		}
+	}
+
+	if _, err := EvalCondition("== prod", values); err == nil {
+		t.Error("expected error for condition without a name")
+	}
+}
+
+func TestConditionNames(t *testing.T) {
+	got := ConditionNames("env == prod && !dry_run || env != dev")
+	want := []string{"env", "dry_run"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,207 @@
+package template
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// transformDelim separates a placeholder from its transforms, e.g.
+// {{branch |> slug}}. It is distinct from the | used by enum options, so
+// {{env|dev|prod |> upper}} is an enum whose chosen value is upper-cased.
+const transformDelim = "|>"
+
+// Transform is one step of a placeholder's transform chain, such as
+// trimprefix "v".
+type Transform struct {
+	Name string
+	Args []string
+}
+
+// transformSpec describes a supported transform: how many arguments it
+// takes and how it changes a value.
+type transformSpec struct {
+	args  int
+	apply func(v string, args []string) string
+}
+
+var transforms = map[string]transformSpec{
+	"upper":      {0, func(v string, _ []string) string { return strings.ToUpper(v) }},
+	"lower":      {0, func(v string, _ []string) string { return strings.ToLower(v) }},
+	"slug":       {0, func(v string, _ []string) string { return slug(v) }},
+	"basename":   {0, func(v string, _ []string) string { return basename(v) }},
+	"base64":     {0, func(v string, _ []string) string { return base64.StdEncoding.EncodeToString([]byte(v)) }},
+	"trim":       {0, func(v string, _ []string) string { return strings.TrimSpace(v) }},
+	"default":    {1, defaultValue},
+	"trimprefix": {1, func(v string, args []string) string { return strings.TrimPrefix(v, args[0]) }},
+	"trimsuffix": {1, func(v string, args []string) string { return strings.TrimSuffix(v, args[0]) }},
+}
+
+// TransformNames returns the supported transform names, for help and lint
+// messages.
+func TransformNames() []string {
+	return []string{"upper", "lower", "slug", "basename", "base64", "trim", "default", "trimprefix", "trimsuffix"}
+}
+
+// ParseTransforms parses a transform chain such as `trimprefix "v" |> upper`.
+// Arguments are bare words or double-quoted strings with \" and \\ escapes.
+func ParseTransforms(chain string) ([]Transform, error) {
+	var out []Transform
+	for _, segment := range splitChain(chain) {
+		words, err := splitWords(segment)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("empty transform in %q", strings.TrimSpace(chain))
+		}
+		t := Transform{Name: words[0], Args: words[1:]}
+		spec, ok := transforms[t.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %q (want one of %s)", t.Name, strings.Join(TransformNames(), ", "))
+		}
+		if len(t.Args) != spec.args {
+			return nil, fmt.Errorf("transform %q takes %d argument(s), got %d", t.Name, spec.args, len(t.Args))
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// ApplyTransforms runs v through ts in order.
+func ApplyTransforms(v string, ts []Transform) string {
+	for _, t := range ts {
+		if spec, ok := transforms[t.Name]; ok && len(t.Args) == spec.args {
+			v = spec.apply(v, t.Args)
+		}
+	}
+	return v
+}
+
+// CheckTransforms reports the first invalid transform chain in command.
+func CheckTransforms(command string) error {
+	for _, inner := range placeholderInners(command) {
+		if _, chain, ok := strings.Cut(inner, transformDelim); ok {
+			if _, err := ParseTransforms(chain); err != nil {
+				return fmt.Errorf("{{%s}}: %w", inner, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parsePlaceholder splits the inner content of {{...}} into the param and
+// its transforms. An invalid chain yields no transforms; CheckTransforms
+// reports it.
+func parsePlaceholder(inner string) (Param, []Transform) {
+	base, chain, ok := strings.Cut(inner, transformDelim)
+	if !ok {
+		return parseInner(inner), nil
+	}
+	ts, _ := ParseTransforms(chain)
+	return parseInner(strings.TrimSpace(base)), ts
+}
+
+// hasTransform reports whether ts contains a transform called name.
+func hasTransform(ts []Transform, name string) bool {
+	for _, t := range ts {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultValue(v string, args []string) string {
+	if v == "" {
+		return args[0]
+	}
+	return v
+}
+
+// slug lower-cases v and collapses every run of other characters than
+// letters and digits into a single hyphen, e.g. "Feature/ABC-12 fix" gives
+// "feature-abc-12-fix".
+func slug(v string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(v) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pending && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pending = false
+			b.WriteRune(r)
+			continue
+		}
+		pending = true
+	}
+	return b.String()
+}
+
+func basename(v string) string {
+	if v == "" {
+		return ""
+	}
+	return filepath.Base(v)
+}
+
+// splitChain splits a transform chain on |> outside double quotes.
+func splitChain(chain string) []string {
+	var parts []string
+	inQuotes, escaped := false, false
+	start := 0
+	for i := 0; i < len(chain); i++ {
+		c := chain[i]
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inQuotes:
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && strings.HasPrefix(chain[i:], transformDelim):
+			parts = append(parts, chain[start:i])
+			start = i + len(transformDelim)
+			i++
+		}
+	}
+	return append(parts, chain[start:])
+}
+
+// splitWords splits a transform call into its name and arguments.
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord, inQuotes, escaped := false, false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case inQuotes && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+			inWord = true
+		case !inQuotes && unicode.IsSpace(r):
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in %q", strings.TrimSpace(s))
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,81 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender_Transforms(t *testing.T) {
+	values := map[string]string{
+		"name":   "Ada",
+		"branch": "Feature/ABC-12 Fix login!",
+		"path":   "/var/log/app.log",
+		"value":  "user:pass",
+		"tag":    "v1.2.3",
+		"empty":  "",
+	}
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"{{name |> upper}}", "ADA"},
+		{"{{name|>lower}}", "ada"},
+		{"{{branch |> slug}}", "feature-abc-12-fix-login"},
+		{"{{path |> basename}}", "app.log"},
+		{"{{value |> base64}}", "dXNlcjpwYXNz"},
+		{`{{empty |> default "a"}}`, "a"},
+		{`{{name |> default "a"}}`, "Ada"},
+		{`{{missing |> default "fallback value"}}`, "fallback value"},
+		{`{{tag |> trimprefix "v"}}`, "1.2.3"},
+		{`{{tag |> trimprefix "v" |> trimsuffix ".3" |> upper}}`, "1.2"},
+		{"{{who:world |> upper}}", "WORLD"},
+		{"{{env|dev|*prod |> upper}}", "PROD"},
+		{"{{missing |> upper}}", "{{missing |> upper}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.command, values))
+		})
+	}
+}
+
+func TestRenderQuoted_QuotesTransformedValue(t *testing.T) {
+	got := RenderQuoted(`echo {{msg |> upper}}`, map[string]string{"msg": "it's"}, QuoteOptions{})
+	assert.Equal(t, `echo 'IT'\''S'`, got)
+}
+
+func TestExtractParams_IgnoresTransforms(t *testing.T) {
+	params := ExtractParams(`deploy {{tag |> trimprefix "v"}} {{env|dev|*prod |> upper}} {{tag}}`)
+	require.Len(t, params, 2)
+	assert.Equal(t, "tag", params[0].Name)
+	assert.Equal(t, ParamText, params[0].Type)
+	assert.Equal(t, "env", params[1].Name)
+	assert.Equal(t, ParamEnum, params[1].Type)
+	assert.Equal(t, []string{"dev", "prod"}, params[1].Options)
+	assert.Equal(t, "prod", params[1].Default)
+}
+
+func TestParseTransforms(t *testing.T) {
+	ts, err := ParseTransforms(` trimprefix "a \"|>\" b" |> upper `)
+	require.NoError(t, err)
+	assert.Equal(t, []Transform{
+		{Name: "trimprefix", Args: []string{`a "|>" b`}},
+		{Name: "upper", Args: []string{}},
+	}, ts)
+
+	_, err = ParseTransforms("shout")
+	assert.ErrorContains(t, err, `unknown transform "shout"`)
+	_, err = ParseTransforms("trimprefix")
+	assert.ErrorContains(t, err, "takes 1 argument(s), got 0")
+	_, err = ParseTransforms(`default "open`)
+	assert.ErrorContains(t, err, "unterminated quote")
+	_, err = ParseTransforms("upper |>")
+	assert.ErrorContains(t, err, "empty transform")
+}
+
+func TestCheckTransforms(t *testing.T) {
+	assert.NoError(t, CheckTransforms("echo {{a}} {{b |> upper}}"))
+	assert.ErrorContains(t, CheckTransforms("echo {{a |> nope}}"), "{{a |> nope}}")
+}
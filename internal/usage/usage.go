@@ -0,0 +1,177 @@
+// Package usage keeps a small local state file describing how often and how
+// recently each workflow is used, so frequently used workflows can be ranked
+// ahead of the rest, and which parameter values were used last.
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HalfLife is how long it takes a workflow's frecency score to halve when it
+// is not used. One use today outranks one use two weeks ago, but a workflow
+// used daily for a month outranks something tried once this morning.
+const HalfLife = 7 * 24 * time.Hour
+
+// pruneBelow drops workflows whose score has decayed to noise (about ten
+// half-lives after a single use) so the state file stays small.
+const pruneBelow = 0.001
+
+// MaxRecentValues is how many distinct values are remembered per workflow
+// param.
+const MaxRecentValues = 5
+
+// Frecency is a decaying use counter: each use adds 1 to the score, and the
+// score halves every HalfLife.
+type Frecency struct {
+	Score   float64   `json:"score"`
+	Updated time.Time `json:"updated"`
+}
+
+// At returns the score decayed to now.
+func (f Frecency) At(now time.Time) float64 {
+	if f.Score == 0 {
+		return 0
+	}
+	elapsed := now.Sub(f.Updated)
+	if elapsed <= 0 {
+		return f.Score
+	}
+	return f.Score * math.Exp2(-float64(elapsed)/float64(HalfLife))
+}
+
+// State is the persisted usage data. A nil *State scores every workflow 0
+// and ignores updates.
+type State struct {
+	Workflows map[string]Frecency `json:"workflows,omitempty"`
+	// Values maps workflow -> param -> recently used values, newest first.
+	Values map[string]map[string][]string `json:"values,omitempty"`
+
+	path string
+}
+
+// New returns an empty State that saves to path.
+func New(path string) *State {
+	return &State{path: path}
+}
+
+// Load reads the state file at path. A missing file yields an empty State.
+func Load(path string) (*State, error) {
+	s := New(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading usage state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing usage state %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Record registers one use of the named workflow at now.
+func (s *State) Record(name string, now time.Time) {
+	if s == nil || name == "" {
+		return
+	}
+	if s.Workflows == nil {
+		s.Workflows = make(map[string]Frecency)
+	}
+	f := s.Workflows[name]
+	s.Workflows[name] = Frecency{Score: f.At(now) + 1, Updated: now}
+}
+
+// Score returns the named workflow's frecency at now.
+func (s *State) Score(name string, now time.Time) float64 {
+	if s == nil {
+		return 0
+	}
+	return s.Workflows[name].At(now)
+}
+
+// RememberValues records the values used for a workflow's params. Each
+// param keeps up to MaxRecentValues distinct values, newest first. Empty
+// values are ignored; callers filter out params that must not be stored.
+func (s *State) RememberValues(workflow string, values map[string]string) {
+	if s == nil || workflow == "" {
+		return
+	}
+	for param, value := range values {
+		if value == "" {
+			continue
+		}
+		if s.Values == nil {
+			s.Values = make(map[string]map[string][]string)
+		}
+		if s.Values[workflow] == nil {
+			s.Values[workflow] = make(map[string][]string)
+		}
+		recent := []string{value}
+		for _, v := range s.Values[workflow][param] {
+			if v != value && len(recent) < MaxRecentValues {
+				recent = append(recent, v)
+			}
+		}
+		s.Values[workflow][param] = recent
+	}
+}
+
+// RecentValues returns the remembered values for a workflow param, newest
+// first.
+func (s *State) RecentValues(workflow, param string) []string {
+	if s == nil {
+		return nil
+	}
+	return s.Values[workflow][param]
+}
+
+// Save writes the state back to its file, dropping workflows whose score
+// has decayed to nothing along with their remembered values. The file is
+// replaced atomically so a concurrent reader never sees a partial write.
+func (s *State) Save() error {
+	if s == nil {
+		return nil
+	}
+	now := time.Now()
+	for name, f := range s.Workflows {
+		if f.At(now) < pruneBelow {
+			delete(s.Workflows, name)
+		}
+	}
+	for name := range s.Values {
+		if _, ok := s.Workflows[name]; !ok {
+			delete(s.Values, name)
+		}
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding usage state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating usage state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".usage-*.json")
+	if err != nil {
+		return fmt.Errorf("writing usage state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing usage state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing usage state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("writing usage state: %w", err)
+	}
+	return nil
+}
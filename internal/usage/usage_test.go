@@ -0,0 +1,110 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrecencyDecay(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	f := Frecency{Score: 4, Updated: now}
+
+	assert.InDelta(t, 4, f.At(now), 1e-9)
+	assert.InDelta(t, 2, f.At(now.Add(HalfLife)), 1e-9)
+	assert.InDelta(t, 1, f.At(now.Add(2*HalfLife)), 1e-9)
+	assert.InDelta(t, 4, f.At(now.Add(-time.Hour)), 1e-9, "clock skew does not inflate")
+}
+
+func TestRecordCombinesFrequencyAndRecency(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := New(filepath.Join(t.TempDir(), "usage.json"))
+
+	// "daily" was used every day for the past two weeks, "once" only today.
+	for d := 14; d >= 1; d-- {
+		s.Record("daily", now.Add(-time.Duration(d)*24*time.Hour))
+	}
+	s.Record("once", now)
+	// "old" was used heavily long ago.
+	for i := 0; i < 5; i++ {
+		s.Record("old", now.Add(-90*24*time.Hour))
+	}
+
+	daily := s.Score("daily", now)
+	once := s.Score("once", now)
+	old := s.Score("old", now)
+
+	assert.Greater(t, daily, once, "frequent recent use beats a single use")
+	assert.Greater(t, once, old, "recent use beats stale heavy use")
+	assert.Zero(t, s.Score("never", now))
+}
+
+func TestSaveLoadRoundTripAndPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "usage.json")
+	s := New(path)
+	now := time.Now()
+	s.Record("recent", now)
+	s.Record("ancient", now.Add(-365*24*time.Hour))
+	require.NoError(t, s.Save())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.InDelta(t, s.Score("recent", now), loaded.Score("recent", now), 1e-9)
+	_, ok := loaded.Workflows["ancient"]
+	assert.False(t, ok, "fully decayed workflows are pruned on save")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temp files left behind")
+}
+
+func TestLoadMissingAndMalformed(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Load(filepath.Join(dir, "absent.json"))
+	require.NoError(t, err)
+	assert.Empty(t, s.Workflows)
+
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte("{"), 0600))
+	_, err = Load(bad)
+	assert.Error(t, err)
+}
+
+func TestNilState(t *testing.T) {
+	var s *State
+	s.Record("x", time.Now())
+	assert.Zero(t, s.Score("x", time.Now()))
+	assert.NoError(t, s.Save())
+}
+
+func TestRememberValues(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "usage.json"))
+
+	for _, v := range []string{"a", "b", "c", "b", "d", "e", "f"} {
+		s.RememberValues("deploy", map[string]string{"env": v, "empty": ""})
+	}
+
+	assert.Equal(t, []string{"f", "e", "d", "b", "c"}, s.RecentValues("deploy", "env"),
+		"newest first, deduplicated, capped at MaxRecentValues")
+	assert.Empty(t, s.RecentValues("deploy", "empty"))
+	assert.Empty(t, s.RecentValues("other", "env"))
+}
+
+func TestValuesPrunedWithWorkflow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	s := New(path)
+	s.Record("kept", time.Now())
+	s.RememberValues("kept", map[string]string{"x": "1"})
+	s.RememberValues("orphan", map[string]string{"x": "2"})
+	require.NoError(t, s.Save())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, loaded.RecentValues("kept", "x"))
+	assert.Empty(t, loaded.RecentValues("orphan", "x"))
+}